@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"devsecops-be/config"
+	"devsecops-be/internal/domain/audit"
+	"devsecops-be/pkg/logger"
+	"fmt"
+	"os"
+)
+
+func runAudit(appLogger logger.Logger, cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "verify" {
+		exitUsage(os.Stderr, "audit needs the verify command")
+	}
+
+	db := connectDatabase(cfg, appLogger)
+	defer db.Close()
+
+	result, err := audit.NewAuditModule(db, appLogger).Service.Verify(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for _, chainBreak := range result.Breaks {
+		fmt.Printf("seq %d: %s\n", chainBreak.Seq, chainBreak.Reason)
+	}
+	if len(result.Breaks) > 0 {
+		return fmt.Errorf("audit chain is broken in %d place(s) across %d row(s)", len(result.Breaks), result.Rows)
+	}
+
+	fmt.Printf("Audit chain intact, %d row(s) verified\n", result.Rows)
+	return nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"devsecops-be/config"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+func runConfig(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		exitUsage(os.Stderr, "config needs the print command")
+	}
+
+	flags := flag.NewFlagSet("config print", flag.ExitOnError)
+	redacted := flags.Bool("redacted", false, "mask passwords, keys and other secrets")
+	flags.Parse(args[1:])
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, setting := range cfg.Settings() {
+		value := setting.Value
+		if *redacted && setting.Secret && value != "" {
+			value = "xxxxx"
+		}
+		if strings.ContainsAny(value, " \t\"'#\\\n") {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(w, "%s=%s\t# %s\n", setting.Key, value, setting.Source)
+	}
+	return w.Flush()
+}
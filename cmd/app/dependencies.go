@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/config"
+	"devsecops-be/internal/domain/admin"
+	"devsecops-be/internal/domain/alert"
+	"devsecops-be/internal/domain/audit"
+	"devsecops-be/internal/domain/auth"
+	"devsecops-be/internal/domain/category"
+	"devsecops-be/internal/domain/transaction"
+	"devsecops-be/pkg/database"
+	"devsecops-be/pkg/jwt"
+	"devsecops-be/pkg/lockout"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/mailer"
+	"devsecops-be/pkg/oidc"
+	"devsecops-be/pkg/password"
+	"devsecops-be/pkg/passwordpolicy"
+	"devsecops-be/pkg/revocation"
+	"devsecops-be/pkg/secretbox"
+	"devsecops-be/pkg/worker"
+)
+
+// dependencies are the building blocks shared by the server and the
+// commands that go through the service layer.
+type dependencies struct {
+	db              *sql.DB
+	jwtUtil         jwt.JWTUtil
+	revocationStore revocation.Store
+	attemptStore    lockout.Store
+	mailer          mailer.Mailer
+	jobs            *worker.Pool
+	mfaBox          secretbox.Box
+	passUtil        password.PasswordUtil
+	passPolicy      passwordpolicy.Checker
+	oidcProviders   map[string]oidc.Provider
+}
+
+// connectDatabase opens the database or exits.
+func connectDatabase(cfg *config.Config, appLogger logger.Logger) *sql.DB {
+	db, err := database.NewPostgresConnection(cfg.Database, appLogger)
+	if err != nil {
+		appLogger.Fatal(context.Background(), "Failed to connect to database", err)
+	}
+	return db
+}
+
+// loadDependencies builds the dependencies from the configuration or exits
+// when a key or corpus file cannot be loaded.
+func loadDependencies(cfg *config.Config, appLogger logger.Logger, db *sql.DB) *dependencies {
+	deps := &dependencies{db: db}
+
+	// JWT Utility
+	jwtUtil, err := jwt.NewJWTUtil(cfg.JWT)
+	if err != nil {
+		appLogger.Fatal(context.Background(), "Failed to load JWT signing keys", err)
+	}
+	deps.jwtUtil = jwtUtil
+
+	// Token revocation store
+	if cfg.TokenRevocation.Store == config.StoreMemory {
+		deps.revocationStore = revocation.NewMemoryStore()
+	} else {
+		deps.revocationStore = revocation.NewPostgresStore(db)
+	}
+
+	// Failed login counters
+	if cfg.LoginAttempts.Store == config.StoreMemory {
+		deps.attemptStore = lockout.NewMemoryStore()
+	} else {
+		deps.attemptStore = lockout.NewPostgresStore(db)
+	}
+
+	// Mailer
+	if deps.mailer, err = mailer.NewMailer(cfg.Mailer, appLogger); err != nil {
+		appLogger.Fatal(context.Background(), "Failed to configure mailer", err)
+	}
+
+	// Background jobs, such as sending email after the response
+	deps.jobs = worker.NewPool(cfg.Jobs)
+
+	// Encryption for TOTP secrets at rest
+	if deps.mfaBox, err = secretbox.NewFromBase64(cfg.MFAEncryptionKey); err != nil {
+		appLogger.Fatal(context.Background(), "Invalid MFA_ENCRYPTION_KEY", err)
+	}
+
+	// Password hashing, existing hashes are upgraded on login
+	if deps.passUtil, err = password.NewPasswordUtil(cfg.Password); err != nil {
+		appLogger.Fatal(context.Background(), "Invalid password hashing configuration", err)
+	}
+
+	// Password policy, with an optional local copy of the breached password corpus
+	var breachChecker passwordpolicy.BreachChecker
+	if cfg.BreachCorpusPath != "" {
+		breachChecker, err = passwordpolicy.NewFileBreachChecker(cfg.BreachCorpusPath)
+		if err != nil {
+			appLogger.Fatal(context.Background(), "Invalid PASSWORD_BREACH_CORPUS_PATH", err)
+		}
+	}
+	deps.passPolicy = passwordpolicy.NewChecker(cfg.PasswordPolicy, breachChecker)
+
+	// Social login providers
+	if deps.oidcProviders, err = oidc.NewProviders(cfg.OIDCProviders, oidc.NewHTTPClient()); err != nil {
+		appLogger.Fatal(context.Background(), "Invalid OIDC provider configuration", err)
+	}
+
+	return deps
+}
+
+// modules are the domain modules used by the commands, wired the same way
+// as in the server.
+type modules struct {
+	auth        *auth.AuthModule
+	admin       *admin.AdminModule
+	category    *category.CategoryModule
+	transaction *transaction.TransactionModule
+}
+
+func newModules(cfg *config.Config, deps *dependencies, appLogger logger.Logger) *modules {
+	auditModule := audit.NewAuditModule(deps.db, appLogger)
+	authModule := auth.NewAuthModule(
+		deps.db, deps.jwtUtil, deps.revocationStore, deps.mailer, deps.jobs, deps.mfaBox, deps.passUtil, deps.passPolicy,
+		deps.oidcProviders, deps.attemptStore, cfg.Auth, auditModule.Service, appLogger,
+	)
+	alertModule := alert.NewAlertModule(deps.db, cfg.AlertThresholds, appLogger)
+	transactionModule := transaction.NewTransactionModule(deps.db, alertModule.Service, auditModule.Service, appLogger)
+
+	return &modules{
+		auth:        authModule,
+		admin:       admin.NewAdminModule(deps.db, authModule.Service, auditModule.Service, transactionModule.Service, appLogger),
+		category:    category.NewCategoryModule(deps.db, appLogger),
+		transaction: transactionModule,
+	}
+}
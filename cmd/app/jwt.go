@@ -0,0 +1,44 @@
+package main
+
+import (
+	"devsecops-be/config"
+	"devsecops-be/pkg/jwt"
+	"devsecops-be/pkg/logger"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func runJWT(appLogger logger.Logger, cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "rotate-keys" {
+		exitUsage(os.Stderr, "jwt needs the rotate-keys command")
+	}
+
+	flags := flag.NewFlagSet("jwt rotate-keys", flag.ExitOnError)
+	algorithm := flags.String("alg", jwt.KeyAlgorithmEd25519, "algorithm of the new key, ed25519 or rsa")
+	keyFile := flags.String("key-file", cfg.JWT.SigningKeyFile, "private key to replace, defaults to JWT_SIGNING_KEY_FILE")
+	flags.Parse(args[1:])
+
+	if *keyFile == "" {
+		exitUsage(os.Stderr, "JWT_SIGNING_KEY_FILE is not set and no -key-file was given")
+	}
+
+	rotation, err := jwt.RotateSigningKey(*keyFile, *algorithm)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("New signing key %s written to %s\n", rotation.NewKeyID, *keyFile)
+	if rotation.OldPublicKeyFile == "" {
+		return nil
+	}
+
+	verificationFiles := append([]string{rotation.OldPublicKeyFile}, cfg.JWT.VerificationKeyFiles...)
+
+	fmt.Printf("Previous key %s saved to %s\n", rotation.OldKeyID, rotation.OldPublicKeyFile)
+	fmt.Println("Restart every instance with:")
+	fmt.Printf("  JWT_VERIFICATION_KEY_FILES=%s\n", strings.Join(verificationFiles, ","))
+	fmt.Println("and drop the previous key once access tokens signed with it have expired.")
+	return nil
+}
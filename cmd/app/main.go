@@ -2,37 +2,84 @@ package main
 
 import (
 	"context"
-	"devsecops-be/config/fiber"
-	"devsecops-be/internal/infra/routes"
-	"devsecops-be/internal/infra/server"
-
-	"devsecops-be/pkg/database"
-	"devsecops-be/pkg/jwt"
+	"devsecops-be/config"
 	"devsecops-be/pkg/logger"
+	"fmt"
+	"io"
+	"os"
 )
 
+const usage = `Usage: app [command]
+
+Commands:
+  serve                        Start the HTTP server (default)
+  migrate up                   Apply every pending migration
+  migrate down [-steps N]      Revert the last N migrations (default 1)
+  migrate to VERSION           Migrate up or down to VERSION, 0 reverts everything
+  migrate status               List migrations and whether they are applied
+  migrate baseline VERSION     Mark migrations up to VERSION as applied without running them
+  seed                         Create demo users with categories and transactions
+  user create-admin            Create an administrator, or promote an existing user
+  user disable EMAIL|ID        Disable an account and sign it out everywhere
+  jwt rotate-keys              Replace the JWT signing key, keeping the old public key
+  audit verify                 Check the audit log hash chain for edited or removed rows
+  config print [-redacted]     Show the effective settings and where they come from
+  help                         Show this help
+
+Run "app COMMAND -h" for the flags of a command.
+`
+
 func main() {
-	// Logger
-	appLogger := logger.NewLogger()
-	appLogger.Info(context.Background(), "Starting Fiber Auth Application")
+	command, args := "serve", []string{}
+	if len(os.Args) > 1 {
+		command, args = os.Args[1], os.Args[2:]
+	}
 
-	// Database
-	db, err := database.NewPostgresConnection(appLogger)
+	switch command {
+	case "help", "-h", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	case "serve", "migrate", "seed", "user", "jwt", "audit", "config":
+	default:
+		exitUsage(os.Stderr, fmt.Sprintf("unknown command %q", command))
+	}
+
+	// Configuration, every command refuses to run with an invalid one
+	cfg, err := config.Load()
 	if err != nil {
-		appLogger.Fatal(context.Background(), "Failed to connect to database", err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	defer db.Close()
 
-	// JWT Utility
-	jwtUtil := jwt.NewJWTUtil()
+	// Logger
+	appLogger := logger.NewLogger(cfg.Env == config.EnvDevelopment)
 
-	// Fiber App
-	fiberApp := fiber.NewFiberApp(appLogger, db, jwtUtil)
+	switch command {
+	case "serve":
+		serve(appLogger, cfg)
+	case "migrate":
+		err = runMigrate(appLogger, cfg, args)
+	case "seed":
+		err = runSeed(appLogger, cfg, args)
+	case "user":
+		err = runUser(appLogger, cfg, args)
+	case "jwt":
+		err = runJWT(appLogger, cfg, args)
+	case "audit":
+		err = runAudit(appLogger, cfg, args)
+	case "config":
+		err = runConfig(cfg, args)
+	}
 
-	// Register routes
-	routes.RegisterRoutes(fiberApp, jwtUtil, appLogger)
+	if err != nil {
+		appLogger.Fatal(context.Background(), "Command failed", err, logger.Fields{
+			"command": command,
+		})
+	}
+}
 
-	// Server
-	server := server.NewServer(fiberApp, appLogger)
-	server.Start()
+// exitUsage reports a command line mistake and exits with status 2.
+func exitUsage(w io.Writer, message string) {
+	fmt.Fprintf(w, "%s\n\n%s", message, usage)
+	os.Exit(2)
 }
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"devsecops-be/config"
+	"devsecops-be/database/migrations"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/migrate"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+func runMigrate(appLogger logger.Logger, cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		exitUsage(os.Stderr, "migrate needs one of up, down, to, status or baseline")
+	}
+
+	db := connectDatabase(cfg, appLogger)
+	defer db.Close()
+
+	migrator, err := migrate.NewMigrator(db, migrations.FS, appLogger)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migration(s)\n", applied)
+	case "down":
+		flags := flag.NewFlagSet("migrate down", flag.ExitOnError)
+		steps := flags.Int("steps", 1, "number of migrations to revert")
+		flags.Parse(args[1:])
+
+		reverted, err := migrator.Down(ctx, *steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Reverted %d migration(s)\n", reverted)
+	case "to":
+		version := parseVersion(args[1:])
+		changed, err := migrator.To(ctx, version)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Ran %d migration(s), database is at version %d\n", changed, version)
+	case "baseline":
+		version := parseVersion(args[1:])
+		if err := migrator.Baseline(ctx, version); err != nil {
+			return err
+		}
+		fmt.Printf("Recorded migrations up to version %d as applied\n", version)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printMigrationStatus(statuses)
+	default:
+		exitUsage(os.Stderr, fmt.Sprintf("unknown migrate command %q", args[0]))
+	}
+
+	return nil
+}
+
+func parseVersion(args []string) int64 {
+	if len(args) != 1 {
+		exitUsage(os.Stderr, "expected exactly one VERSION")
+	}
+	version, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || version < 0 {
+		exitUsage(os.Stderr, fmt.Sprintf("invalid version %q", args[0]))
+	}
+	return version
+}
+
+func printMigrationStatus(statuses []migrate.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", ""
+		if status.Applied {
+			state, appliedAt = "applied", status.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		switch {
+		case status.Missing:
+			state = "applied, file missing"
+		case status.Modified:
+			state = "applied, file modified"
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	w.Flush()
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"devsecops-be/config"
+	authDto "devsecops-be/internal/domain/auth/dto"
+	categoryDto "devsecops-be/internal/domain/category/dto"
+	transactionDto "devsecops-be/internal/domain/transaction/dto"
+	"devsecops-be/pkg/errors"
+	"devsecops-be/pkg/logger"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type demoUser struct {
+	name  string
+	email string
+}
+
+var (
+	demoUsers = []demoUser{
+		{name: "Alice Example", email: "alice@example.com"},
+		{name: "Bob Example", email: "bob@example.com"},
+	}
+	demoCategories = []string{"Groceries", "Transport", "Utilities", "Dining Out", "Salary"}
+)
+
+// runSeed creates demo users with categories and a couple of months of
+// transactions. Users that already exist are left untouched, so it can run
+// more than once.
+func runSeed(appLogger logger.Logger, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	password := flags.String("password", "Ledger-Harbor-Plum-42", "password of the demo users")
+	days := flags.Int("days", 60, "days of transaction history to create")
+	force := flags.Bool("force", false, "seed even when ENV is production")
+	flags.Parse(args)
+
+	if cfg.Env == config.EnvProduction && !*force {
+		exitUsage(os.Stderr, "refusing to seed a production environment without -force")
+	}
+
+	db := connectDatabase(cfg, appLogger)
+	defer db.Close()
+	mods := newModules(cfg, loadDependencies(cfg, appLogger, db), appLogger)
+	ctx := context.Background()
+
+	for i, demo := range demoUsers {
+		user, err := mods.auth.Service.CreateUser(ctx, authDto.RegisterRequest{
+			Name:     demo.name,
+			Email:    demo.email,
+			Password: *password,
+		})
+		if err == errors.ErrUserAlreadyExists {
+			fmt.Printf("Skipping %s, it already exists\n", demo.email)
+			continue
+		}
+		if err != nil {
+			return describeError(err)
+		}
+
+		count, err := seedTransactions(ctx, mods, user.ID, *days, rand.New(rand.NewSource(int64(i+1))))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created %s with %d categories and %d transactions\n", demo.email, len(demoCategories), count)
+	}
+
+	fmt.Printf("Demo users sign in with password %q\n", *password)
+	return nil
+}
+
+func seedTransactions(ctx context.Context, mods *modules, userID uuid.UUID, days int, random *rand.Rand) (int, error) {
+	categories := map[string]uuid.UUID{}
+	for _, name := range demoCategories {
+		category, err := mods.category.Service.Create(ctx, userID, categoryDto.CreateCategoryRequest{Name: name})
+		if err != nil {
+			return 0, err
+		}
+		categories[name] = category.ID
+	}
+
+	expenses := []struct {
+		category   string
+		note       string
+		minAmount  int
+		maxAmount  int
+		everyNDays int
+	}{
+		{"Groceries", "Weekly groceries", 150000, 600000, 7},
+		{"Transport", "Commute", 20000, 80000, 2},
+		{"Dining Out", "Lunch", 30000, 150000, 3},
+		{"Utilities", "Electricity and internet", 400000, 900000, 30},
+	}
+
+	count := 0
+	create := func(date time.Time, txType, category, note string, amount int) error {
+		categoryID := categories[category]
+		_, err := mods.transaction.Service.Create(ctx, userID, transactionDto.CreateTransactionRequest{
+			Type:       txType,
+			Amount:     float64(amount),
+			CategoryID: &categoryID,
+			Note:       &note,
+			Date:       date.Format(transactionDto.DateLayout),
+		})
+		if err == nil {
+			count++
+		}
+		return err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	for day := days - 1; day >= 0; day-- {
+		date := today.AddDate(0, 0, -day)
+		if date.Day() == 1 {
+			if err := create(date, "income", "Salary", "Monthly salary", 15000000); err != nil {
+				return count, err
+			}
+		}
+		for _, expense := range expenses {
+			if day%expense.everyNDays != 0 {
+				continue
+			}
+			// Round to 500 like real prices
+			amount := (expense.minAmount + random.Intn(expense.maxAmount-expense.minAmount)) / 500 * 500
+			if err := create(date, "expense", expense.category, expense.note, amount); err != nil {
+				return count, err
+			}
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"devsecops-be/config"
+	"devsecops-be/config/fiber"
+	"devsecops-be/database/migrations"
+	"devsecops-be/internal/domain/auth"
+	"devsecops-be/internal/infra/routes"
+	"devsecops-be/internal/infra/server"
+	"devsecops-be/pkg/health"
+	"devsecops-be/pkg/lockout"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/migrate"
+	"devsecops-be/pkg/ratelimit"
+	"devsecops-be/pkg/rbac"
+	"devsecops-be/pkg/revocation"
+	"devsecops-be/pkg/version"
+	"time"
+)
+
+func serve(appLogger logger.Logger, cfg *config.Config) {
+	build := version.Get()
+	appLogger.Info(context.Background(), "Starting Fiber Auth Application", logger.Fields{
+		"version": build.Version,
+		"commit":  build.Commit,
+	})
+
+	// Database
+	db := connectDatabase(cfg, appLogger)
+	defer db.Close()
+
+	migrator, err := migrate.NewMigrator(db, migrations.FS, appLogger)
+	if err != nil {
+		appLogger.Fatal(context.Background(), "Failed to load migrations", err)
+	}
+
+	// Schema migrations, replicas starting together wait on an advisory lock
+	if cfg.MigrateOnStart {
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			appLogger.Fatal(context.Background(), "Failed to migrate database", err)
+		}
+		appLogger.Info(context.Background(), "Database schema is up to date", logger.Fields{
+			"applied": applied,
+		})
+	}
+
+	deps := loadDependencies(cfg, appLogger, db)
+
+	cleanupCtx, stopCleanup := context.WithCancel(context.Background())
+	defer stopCleanup()
+	revocation.StartCleanup(cleanupCtx, deps.revocationStore, cfg.TokenRevocation.CleanupInterval, appLogger)
+	lockout.StartCleanup(cleanupCtx, deps.attemptStore, cfg.LoginAttempts.CleanupInterval, appLogger)
+	auth.StartSessionCleanup(cleanupCtx, db, cfg.SessionCleanupInterval, appLogger)
+
+	// Rate limit buckets, per instance by default
+	var rateLimitStore ratelimit.Store
+	if cfg.RateLimit.Store == config.StorePostgres {
+		rateLimitStore = ratelimit.NewPostgresStore(db)
+	} else {
+		rateLimitStore = ratelimit.NewMemoryStore()
+	}
+	ratelimit.StartCleanup(cleanupCtx, rateLimitStore, time.Minute, appLogger)
+
+	// Role permissions, cached per instance
+	authorizer := rbac.NewPostgresAuthorizer(db, cfg.RBACCacheTTL)
+
+	// Fiber App
+	fiberApp := fiber.NewFiberApp(appLogger, cfg, db, deps.jwtUtil, deps.revocationStore, deps.mailer, deps.jobs, deps.mfaBox, deps.passUtil, deps.passPolicy, deps.oidcProviders, deps.attemptStore, rateLimitStore, authorizer)
+
+	// Dependency checks behind the readiness probe
+	healthChecks := health.NewRegistry(cfg.Server.HealthCheckTimeout)
+	healthChecks.Register("database", health.Database(db))
+	healthChecks.Register("migrations", health.Migrations(migrator))
+
+	// Register routes
+	routes.RegisterRoutes(fiberApp, deps.jwtUtil, deps.revocationStore, authorizer, healthChecks, appLogger)
+
+	// Server
+	server := server.NewServer(fiberApp, cfg.Server.Port, cfg.Env, appLogger)
+	server.Start()
+
+	// Emails queued by the last requests are still sent before exiting
+	if err := deps.jobs.Shutdown(); err != nil {
+		appLogger.Error(context.Background(), "Failed to finish background jobs", err)
+	}
+}
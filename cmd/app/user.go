@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"devsecops-be/config"
+	adminDto "devsecops-be/internal/domain/admin/dto"
+	adminService "devsecops-be/internal/domain/admin/service"
+	authDto "devsecops-be/internal/domain/auth/dto"
+	"devsecops-be/pkg/errors"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/rbac"
+	"devsecops-be/pkg/token"
+	"devsecops-be/pkg/validator"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+func runUser(appLogger logger.Logger, cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		exitUsage(os.Stderr, "user needs one of create-admin or disable")
+	}
+
+	switch args[0] {
+	case "create-admin":
+		return createAdmin(appLogger, cfg, args[1:])
+	case "disable":
+		return disableUser(appLogger, cfg, args[1:])
+	default:
+		exitUsage(os.Stderr, fmt.Sprintf("unknown user command %q", args[0]))
+	}
+	return nil
+}
+
+// createAdmin creates a verified administrator, or promotes the user when the
+// email is already registered.
+func createAdmin(appLogger logger.Logger, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("user create-admin", flag.ExitOnError)
+	email := flags.String("email", "", "email of the administrator (required)")
+	name := flags.String("name", "Administrator", "display name for a new account")
+	password := flags.String("password", os.Getenv("ADMIN_PASSWORD"), "password for a new account, defaults to ADMIN_PASSWORD or a generated one")
+	flags.Parse(args)
+
+	if *email == "" {
+		exitUsage(os.Stderr, "user create-admin needs -email")
+	}
+
+	db := connectDatabase(cfg, appLogger)
+	defer db.Close()
+	mods := newModules(cfg, loadDependencies(cfg, appLogger, db), appLogger)
+	ctx := context.Background()
+
+	var userID uuid.UUID
+	existing, err := mods.admin.Service.GetUserByEmail(ctx, *email)
+	switch {
+	case err == nil:
+		userID = existing.ID
+		fmt.Printf("User %s already exists, promoting it\n", existing.Email)
+	case err == errors.ErrUserNotFound:
+		generated := ""
+		if *password == "" {
+			if generated, err = token.Generate(18); err != nil {
+				return err
+			}
+			*password = generated
+		}
+
+		req := authDto.RegisterRequest{Name: *name, Email: *email, Password: *password}
+		if err := validator.NewValidator().Validate(req); err != nil {
+			return err
+		}
+
+		created, err := mods.auth.Service.CreateUser(ctx, req)
+		if err != nil {
+			return describeError(err)
+		}
+		userID = created.ID
+		fmt.Printf("Created user %s\n", created.Email)
+		if generated != "" {
+			fmt.Printf("Generated password: %s\n", generated)
+		}
+	default:
+		return err
+	}
+
+	user, err := mods.admin.Service.SetRole(ctx, adminService.SystemActor, userID, rbac.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("User %s (%s) is now an %s\n", user.Email, user.ID, user.Role)
+	return nil
+}
+
+func disableUser(appLogger logger.Logger, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("user disable", flag.ExitOnError)
+	reason := flags.String("reason", "", "reason recorded in the audit log")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		exitUsage(os.Stderr, "user disable needs exactly one EMAIL or ID")
+	}
+
+	db := connectDatabase(cfg, appLogger)
+	defer db.Close()
+	mods := newModules(cfg, loadDependencies(cfg, appLogger, db), appLogger)
+	ctx := context.Background()
+
+	userID, err := uuid.Parse(flags.Arg(0))
+	if err != nil {
+		user, err := mods.admin.Service.GetUserByEmail(ctx, flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		userID = user.ID
+	}
+
+	user, err := mods.admin.Service.DisableUser(ctx, adminService.SystemActor, userID, adminDto.DisableUserRequest{Reason: *reason})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("User %s (%s) is disabled and signed out everywhere\n", user.Email, user.ID)
+	return nil
+}
+
+// describeError spells out field violations, such as password policy
+// failures, that the HTTP API returns as structured details.
+func describeError(err error) error {
+	appErr, ok := err.(*errors.AppError)
+	if !ok {
+		return err
+	}
+	fieldErrors, ok := appErr.Details.([]errors.FieldError)
+	if !ok || len(fieldErrors) == 0 {
+		return err
+	}
+
+	messages := make([]string, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		messages = append(messages, fieldError.Message)
+	}
+	return fmt.Errorf("%s: %s", appErr.Message, strings.Join(messages, "; "))
+}
@@ -0,0 +1,467 @@
+// Package config loads the application settings once at startup.
+//
+// Every setting is named after its environment variable. The same names, or
+// their nested form, can also be set in a YAML or TOML file given with
+// CONFIG_FILE; the environment wins over the file. NAME_FILE reads the value
+// of NAME from a file, which is how Docker secrets are mounted.
+package config
+
+import (
+	alertService "devsecops-be/internal/domain/alert/service"
+	"devsecops-be/internal/domain/auth"
+	authService "devsecops-be/internal/domain/auth/service"
+	"devsecops-be/pkg/database"
+	"devsecops-be/pkg/jwt"
+	"devsecops-be/pkg/lockout"
+	"devsecops-be/pkg/mailer"
+	"devsecops-be/pkg/oidc"
+	"devsecops-be/pkg/password"
+	"devsecops-be/pkg/passwordpolicy"
+	"devsecops-be/pkg/ratelimit"
+	"devsecops-be/pkg/secretbox"
+	"devsecops-be/pkg/worker"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Environments accepted in ENV.
+const (
+	EnvDevelopment = "development"
+	EnvTest        = "test"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// Store backends for the shared counters.
+const (
+	StorePostgres = "postgres"
+	StoreMemory   = "memory"
+)
+
+type Config struct {
+	Env    string
+	Server ServerConfig
+	CORS   CORSConfig
+
+	RateLimit RateLimitConfig
+	// RBACCacheTTL is how long role permissions are cached per instance.
+	RBACCacheTTL time.Duration
+
+	Database database.Config
+	// MigrateOnStart applies pending migrations before the server starts.
+	MigrateOnStart bool
+
+	JWT             jwt.Config
+	TokenRevocation StoreConfig
+	LoginAttempts   StoreConfig
+	// SessionCleanupInterval is how often sessions whose refresh tokens have
+	// all expired are deleted.
+	SessionCleanupInterval time.Duration
+	Auth                   auth.Config
+	OIDCProviders          []oidc.Config
+	// MFAEncryptionKey is the base64 AES-256 key TOTP secrets are encrypted with.
+	MFAEncryptionKey string
+
+	Password       password.Config
+	PasswordPolicy passwordpolicy.Policy
+	// BreachCorpusPath is an optional local copy of the breached password corpus.
+	BreachCorpusPath string
+
+	Mailer          mailer.Config
+	AlertThresholds []int
+	// Jobs runs work that outlives a request, such as sending email.
+	Jobs worker.Config
+
+	settings []Setting
+}
+
+type ServerConfig struct {
+	Port int
+	// HealthCheckTimeout bounds each dependency check of the readiness probe.
+	HealthCheckTimeout time.Duration
+	// TrustedProxies are the IPs or CIDR ranges of the load balancers in
+	// front of the server. Only requests from them may set the client IP
+	// through ProxyHeader.
+	TrustedProxies []string
+	// ProxyHeader carries the client IP. Fiber takes its first entry, so it
+	// must be a header the proxy overwrites rather than appends to.
+	ProxyHeader string
+}
+
+type CORSConfig struct {
+	// Origins allowed to call the API. "*" allows any origin, in which case
+	// credentials are not allowed.
+	Origins []string
+}
+
+// AllowsAnyOrigin reports whether the origins are the "*" wildcard.
+func (c CORSConfig) AllowsAnyOrigin() bool {
+	return len(c.Origins) == 1 && c.Origins[0] == "*"
+}
+
+type RateLimitConfig struct {
+	// Store is "memory", per instance, or "postgres", shared between instances.
+	Store string
+	API   ratelimit.Limit
+	// Auth is the stricter limit of the unauthenticated auth endpoints.
+	Auth ratelimit.Limit
+	// User and APIKey limit authenticated requests per account and per key,
+	// on top of the per-IP limit.
+	User   ratelimit.Limit
+	APIKey ratelimit.Limit
+}
+
+type StoreConfig struct {
+	// Store is "postgres", shared between instances, or "memory".
+	Store           string
+	CleanupInterval time.Duration
+}
+
+// ValidationError lists every invalid setting found while loading.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Load reads and validates every setting. The error is a *ValidationError
+// when settings are malformed, so they can all be fixed in one go.
+func Load() (*Config, error) {
+	l, err := newLoader(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{Env: l.string("ENV", EnvDevelopment)}
+	config.loadServer(l)
+	config.loadDatabase(l)
+	config.loadAuth(l)
+	config.loadPasswords(l)
+
+	config.Mailer = mailer.Config{
+		Driver:   l.string("MAILER_DRIVER", "log"),
+		From:     l.string("MAILER_FROM", "no-reply@localhost"),
+		Host:     l.string("SMTP_HOST", ""),
+		Port:     l.int("SMTP_PORT", 587),
+		Username: l.string("SMTP_USERNAME", ""),
+		Password: l.secret("SMTP_PASSWORD", ""),
+		Dir:      l.string("MAILER_DIR", "tmp/mail"),
+	}
+	config.Jobs = worker.Config{
+		Workers:         l.int("BACKGROUND_WORKERS", 4),
+		QueueSize:       l.int("BACKGROUND_QUEUE_SIZE", 100),
+		ShutdownTimeout: l.duration("BACKGROUND_SHUTDOWN_TIMEOUT_SECONDS", 30, time.Second),
+	}
+
+	thresholds, err := alertService.ParseThresholds(l.string("ALERT_THRESHOLDS", "80,100"))
+	if err != nil {
+		l.problemf("ALERT_THRESHOLDS: %v", err)
+	}
+	config.AlertThresholds = thresholds
+
+	l.checkUnused()
+	config.settings = l.settings
+
+	problems := append(l.problems, config.validate()...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return config, nil
+}
+
+func (c *Config) loadServer(l *loader) {
+	c.Server = ServerConfig{
+		Port:               l.int("PORT", 8000),
+		HealthCheckTimeout: l.duration("HEALTH_CHECK_TIMEOUT_SECONDS", 2, time.Second),
+		TrustedProxies:     l.list("TRUSTED_PROXIES", ""),
+		ProxyHeader:        l.string("PROXY_HEADER", "X-Real-IP"),
+	}
+	c.CORS = CORSConfig{Origins: l.list("CORS_ORIGINS", "*")}
+
+	c.RateLimit = RateLimitConfig{
+		Store: l.string("RATE_LIMIT_STORE", StoreMemory),
+		API: ratelimit.Limit{
+			Burst:  l.int("RATE_LIMIT_REQUESTS", 300),
+			Period: l.duration("RATE_LIMIT_WINDOW_SECONDS", 60, time.Second),
+		},
+		Auth: ratelimit.Limit{
+			Burst:  l.int("RATE_LIMIT_AUTH_REQUESTS", 20),
+			Period: l.duration("RATE_LIMIT_AUTH_WINDOW_SECONDS", 60, time.Second),
+		},
+		User: ratelimit.Limit{
+			Burst:  l.int("RATE_LIMIT_USER_REQUESTS", 120),
+			Period: l.duration("RATE_LIMIT_USER_WINDOW_SECONDS", 60, time.Second),
+		},
+		APIKey: ratelimit.Limit{
+			Burst:  l.int("RATE_LIMIT_API_KEY_REQUESTS", 60),
+			Period: l.duration("RATE_LIMIT_API_KEY_WINDOW_SECONDS", 60, time.Second),
+		},
+	}
+	c.RBACCacheTTL = l.duration("RBAC_CACHE_SECONDS", 60, time.Second)
+}
+
+func (c *Config) loadDatabase(l *loader) {
+	c.Database = database.Config{
+		Host:            l.string("DB_HOST", "localhost"),
+		Port:            l.int("DB_PORT", 5432),
+		User:            l.string("DB_USER", "postgres"),
+		Password:        l.secret("DB_PASSWORD", "password"),
+		DBName:          l.string("DB_NAME", "devsecops_be"),
+		SSLMode:         l.string("DB_SSLMODE", "disable"),
+		SSLRootCert:     l.string("DB_SSLROOTCERT", ""),
+		SSLCert:         l.string("DB_SSLCERT", ""),
+		SSLKey:          l.string("DB_SSLKEY", ""),
+		ConnectTimeout:  l.int("DB_CONNECT_TIMEOUT_SECONDS", 10),
+		ApplicationName: l.string("DB_APPLICATION_NAME", "devsecops-be"),
+		SearchPath:      l.string("DB_SEARCH_PATH", ""),
+		MaxOpenConns:    l.int("DB_MAX_OPEN_CONNS", 25),
+		MaxIdleConns:    l.int("DB_MAX_IDLE_CONNS", 25),
+		MaxLifetime:     l.duration("DB_MAX_LIFETIME_MINUTES", 5, time.Minute),
+	}
+
+	// DATABASE_URL, as a postgres:// URL or a keyword/value string, takes
+	// precedence for every setting it contains
+	if databaseURL := l.secret("DATABASE_URL", ""); databaseURL != "" {
+		if err := c.Database.ApplyDSN(databaseURL); err != nil {
+			l.problemf("DATABASE_URL: %v", err)
+		}
+	}
+
+	c.MigrateOnStart = l.bool("MIGRATE_ON_START", true)
+}
+
+func (c *Config) loadAuth(l *loader) {
+	c.JWT = jwt.Config{
+		SigningKeyFile:       l.string("JWT_SIGNING_KEY_FILE", ""),
+		VerificationKeyFiles: l.list("JWT_VERIFICATION_KEY_FILES", ""),
+		RefreshTokenExp:      l.duration("JWT_REFRESH_EXP_HOURS", 30*24, time.Hour),
+	}
+	// JWT_ACCESS_EXP_HOURS predates the minutes setting and is still read
+	if l.has("JWT_ACCESS_EXP_HOURS") && !l.has("JWT_ACCESS_EXP_MINUTES") {
+		c.JWT.AccessTokenExp = l.duration("JWT_ACCESS_EXP_HOURS", 0, time.Hour)
+	} else {
+		c.JWT.AccessTokenExp = l.duration("JWT_ACCESS_EXP_MINUTES", 15, time.Minute)
+	}
+	if c.JWT.SigningKeyFile == "" && l.has("JWT_SECRET_KEY") {
+		l.problemf("JWT_SECRET_KEY (HS256) is no longer supported, set JWT_SIGNING_KEY_FILE to an RSA or Ed25519 private key")
+	}
+
+	c.TokenRevocation = StoreConfig{
+		Store:           l.string("TOKEN_REVOCATION_STORE", StorePostgres),
+		CleanupInterval: l.duration("TOKEN_REVOCATION_CLEANUP_MINUTES", 10, time.Minute),
+	}
+	c.LoginAttempts = StoreConfig{
+		Store:           l.string("LOGIN_ATTEMPT_STORE", StorePostgres),
+		CleanupInterval: l.duration("LOGIN_ATTEMPT_CLEANUP_MINUTES", 10, time.Minute),
+	}
+	c.SessionCleanupInterval = l.duration("SESSION_CLEANUP_MINUTES", 60, time.Minute)
+
+	c.Auth.Service = authService.Config{
+		PasswordReset: authService.PasswordResetConfig{
+			URL:            l.string("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+			TokenExp:       l.duration("PASSWORD_RESET_EXP_MINUTES", 30, time.Minute),
+			ResendCooldown: l.duration("PASSWORD_RESET_RESEND_COOLDOWN_SECONDS", 60, time.Second),
+		},
+		EmailVerification: authService.EmailVerificationConfig{
+			URL:            l.string("EMAIL_VERIFICATION_URL", "http://localhost:3000/verify-email"),
+			TokenExp:       l.duration("EMAIL_VERIFICATION_EXP_HOURS", 24, time.Hour),
+			ResendCooldown: l.duration("EMAIL_VERIFICATION_RESEND_COOLDOWN_SECONDS", 60, time.Second),
+		},
+		MFA: authService.MFAConfig{
+			Issuer:          l.string("MFA_ISSUER", "devsecops-be"),
+			PendingTokenExp: l.duration("MFA_PENDING_EXP_MINUTES", 5, time.Minute),
+		},
+		OIDC: authService.OIDCConfig{
+			StateExp: l.duration("OIDC_STATE_EXP_MINUTES", 10, time.Minute),
+		},
+	}
+
+	lockoutBase := l.duration("LOGIN_LOCKOUT_BASE_SECONDS", 60, time.Second)
+	lockoutMax := l.duration("LOGIN_LOCKOUT_MAX_MINUTES", 60, time.Minute)
+	failureWindow := l.duration("LOGIN_FAILURE_WINDOW_MINUTES", 15, time.Minute)
+	c.Auth.AccountLockout = lockout.Policy{
+		MaxFailures: l.int("LOGIN_MAX_FAILURES_PER_ACCOUNT", 5),
+		Window:      failureWindow,
+		BaseLockout: lockoutBase,
+		MaxLockout:  lockoutMax,
+	}
+	c.Auth.IPLockout = lockout.Policy{
+		MaxFailures: l.int("LOGIN_MAX_FAILURES_PER_IP", 20),
+		Window:      failureWindow,
+		BaseLockout: lockoutBase,
+		MaxLockout:  lockoutMax,
+	}
+
+	c.MFAEncryptionKey = l.secret("MFA_ENCRYPTION_KEY", "")
+
+	// Each provider NAME listed in OIDC_PROVIDERS is configured with
+	// OIDC_<NAME>_CLIENT_ID, _CLIENT_SECRET, _ISSUER, _TYPE, _SCOPES and
+	// _REDIRECT_URL, which defaults to OIDC_REDIRECT_URL
+	redirectURL := l.string("OIDC_REDIRECT_URL", "http://localhost:3000/oauth/callback")
+	for _, name := range l.list("OIDC_PROVIDERS", "") {
+		name = strings.ToLower(name)
+		key := func(setting string) string {
+			return "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_" + setting
+		}
+
+		defaults := oidc.DefaultConfig(name)
+		c.OIDCProviders = append(c.OIDCProviders, oidc.Config{
+			Name:         name,
+			Type:         l.string(key("TYPE"), defaults.Type),
+			Issuer:       l.string(key("ISSUER"), defaults.Issuer),
+			ClientID:     l.string(key("CLIENT_ID"), ""),
+			ClientSecret: l.secret(key("CLIENT_SECRET"), ""),
+			RedirectURL:  l.string(key("REDIRECT_URL"), redirectURL),
+			Scopes:       l.fields(key("SCOPES"), strings.Join(defaults.Scopes, " ")),
+		})
+	}
+}
+
+func (c *Config) loadPasswords(l *loader) {
+	c.Password = password.Config{
+		Algorithm:  strings.ToLower(l.string("PASSWORD_HASH_ALGORITHM", password.AlgorithmArgon2id)),
+		BcryptCost: l.int("PASSWORD_BCRYPT_COST", bcrypt.DefaultCost),
+		Argon2: password.Argon2Params{
+			Memory:      uint32(l.intBetween("PASSWORD_ARGON2_MEMORY_KIB", 64*1024, 1, math.MaxInt32)),
+			Iterations:  uint32(l.intBetween("PASSWORD_ARGON2_ITERATIONS", 3, 1, math.MaxInt32)),
+			Parallelism: uint8(l.intBetween("PASSWORD_ARGON2_PARALLELISM", 2, 1, math.MaxUint8)),
+		},
+	}
+
+	c.PasswordPolicy = passwordpolicy.Policy{
+		MinLength:          l.int("PASSWORD_MIN_LENGTH", 8),
+		MaxLength:          l.int("PASSWORD_MAX_LENGTH", 128),
+		RequireUppercase:   l.bool("PASSWORD_REQUIRE_UPPERCASE", false),
+		RequireLowercase:   l.bool("PASSWORD_REQUIRE_LOWERCASE", false),
+		RequireDigit:       l.bool("PASSWORD_REQUIRE_DIGIT", false),
+		RequireSymbol:      l.bool("PASSWORD_REQUIRE_SYMBOL", false),
+		ForbidPersonalInfo: l.bool("PASSWORD_FORBID_PERSONAL_INFO", true),
+		MinScore:           l.int("PASSWORD_MIN_SCORE", 2),
+	}
+	if c.Password.Algorithm == password.AlgorithmBcrypt {
+		c.PasswordPolicy.MaxBytes = password.BcryptMaxBytes
+	}
+	c.BreachCorpusPath = l.string("PASSWORD_BREACH_CORPUS_PATH", "")
+}
+
+// validate checks the values that parsed but do not make sense.
+func (c *Config) validate() []string {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+	checkErr := func(err error) {
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	switch c.Env {
+	case EnvDevelopment, EnvTest, EnvStaging, EnvProduction:
+	default:
+		check(false, "ENV: unknown environment %q, expected development, test, staging or production", c.Env)
+	}
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "PORT: %d is not a valid port", c.Server.Port)
+	check(c.Server.HealthCheckTimeout > 0, "HEALTH_CHECK_TIMEOUT_SECONDS must be positive")
+	for _, proxy := range c.Server.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		check(net.ParseIP(proxy) != nil || cidrErr == nil,
+			"TRUSTED_PROXIES: %q is not an IP address or CIDR range", proxy)
+	}
+
+	for _, origin := range c.CORS.Origins {
+		if origin == "*" {
+			check(len(c.CORS.Origins) == 1, "CORS_ORIGINS: * cannot be combined with other origins")
+			continue
+		}
+		u, err := url.Parse(origin)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && (u.Path == "" || u.Path == "/"),
+			"CORS_ORIGINS: %q is not an origin such as https://app.example.com", origin)
+	}
+	// Credentialed requests are allowed for listed origins, a wildcard would
+	// let any site make them
+	check(c.Env != EnvProduction || !c.CORS.AllowsAnyOrigin(),
+		"CORS_ORIGINS: * is not allowed in production because credentials are allowed, list the frontend origins")
+
+	check(c.RateLimit.Store == StoreMemory || c.RateLimit.Store == StorePostgres,
+		"RATE_LIMIT_STORE: unknown store %q, expected memory or postgres", c.RateLimit.Store)
+	check(c.RateLimit.API.Burst > 0 && c.RateLimit.API.Period > 0,
+		"RATE_LIMIT_REQUESTS and RATE_LIMIT_WINDOW_SECONDS must be positive")
+	check(c.RateLimit.Auth.Burst > 0 && c.RateLimit.Auth.Period > 0,
+		"RATE_LIMIT_AUTH_REQUESTS and RATE_LIMIT_AUTH_WINDOW_SECONDS must be positive")
+	check(c.RateLimit.User.Burst > 0 && c.RateLimit.User.Period > 0,
+		"RATE_LIMIT_USER_REQUESTS and RATE_LIMIT_USER_WINDOW_SECONDS must be positive")
+	check(c.RateLimit.APIKey.Burst > 0 && c.RateLimit.APIKey.Period > 0,
+		"RATE_LIMIT_API_KEY_REQUESTS and RATE_LIMIT_API_KEY_WINDOW_SECONDS must be positive")
+	check(c.RBACCacheTTL >= 0, "RBAC_CACHE_SECONDS must not be negative")
+
+	checkErr(c.Database.Validate())
+	checkErr(c.JWT.Validate())
+
+	checkStore := func(prefix string, store StoreConfig) {
+		check(store.Store == StorePostgres || store.Store == StoreMemory,
+			"%s_STORE: unknown store %q, expected postgres or memory", prefix, store.Store)
+		check(store.CleanupInterval > 0, "%s_CLEANUP_MINUTES must be positive", prefix)
+	}
+	checkStore("TOKEN_REVOCATION", c.TokenRevocation)
+	checkStore("LOGIN_ATTEMPT", c.LoginAttempts)
+	check(c.SessionCleanupInterval > 0, "SESSION_CLEANUP_MINUTES must be positive")
+
+	checkURL := func(key, link string) {
+		u, err := url.Parse(link)
+		check(err == nil && u.Scheme != "" && u.Host != "", "%s: %q is not an absolute URL", key, link)
+	}
+	service := c.Auth.Service
+	checkURL("PASSWORD_RESET_URL", service.PasswordReset.URL)
+	checkURL("EMAIL_VERIFICATION_URL", service.EmailVerification.URL)
+	check(service.PasswordReset.TokenExp > 0, "PASSWORD_RESET_EXP_MINUTES must be positive")
+	check(service.EmailVerification.TokenExp > 0, "EMAIL_VERIFICATION_EXP_HOURS must be positive")
+	check(service.PasswordReset.ResendCooldown >= 0, "PASSWORD_RESET_RESEND_COOLDOWN_SECONDS must not be negative")
+	check(service.EmailVerification.ResendCooldown >= 0, "EMAIL_VERIFICATION_RESEND_COOLDOWN_SECONDS must not be negative")
+	check(service.MFA.PendingTokenExp > 0, "MFA_PENDING_EXP_MINUTES must be positive")
+	check(service.OIDC.StateExp > 0, "OIDC_STATE_EXP_MINUTES must be positive")
+
+	lockoutPolicy := c.Auth.AccountLockout
+	check(lockoutPolicy.MaxFailures > 0 && c.Auth.IPLockout.MaxFailures > 0,
+		"LOGIN_MAX_FAILURES_PER_ACCOUNT and LOGIN_MAX_FAILURES_PER_IP must be positive")
+	check(lockoutPolicy.Window > 0, "LOGIN_FAILURE_WINDOW_MINUTES must be positive")
+	check(lockoutPolicy.BaseLockout > 0 && lockoutPolicy.MaxLockout >= lockoutPolicy.BaseLockout,
+		"LOGIN_LOCKOUT_BASE_SECONDS must be positive and at most LOGIN_LOCKOUT_MAX_MINUTES")
+
+	if _, err := secretbox.NewFromBase64(c.MFAEncryptionKey); err != nil {
+		check(false, "MFA_ENCRYPTION_KEY: %v", err)
+	}
+	for _, provider := range c.OIDCProviders {
+		checkErr(provider.Validate())
+	}
+
+	checkErr(c.Password.Validate())
+	checkErr(c.PasswordPolicy.Validate())
+	// bcrypt rejects longer passwords, a larger maximum would accept passwords
+	// that can never be hashed
+	check(c.Password.Algorithm != password.AlgorithmBcrypt ||
+		(c.PasswordPolicy.MaxLength > 0 && c.PasswordPolicy.MaxLength <= password.BcryptMaxBytes),
+		"PASSWORD_MAX_LENGTH must be between 1 and %d with the bcrypt hash algorithm", password.BcryptMaxBytes)
+	checkErr(c.Mailer.Validate())
+	checkErr(c.Jobs.Validate())
+	check(c.Env != EnvProduction || c.Mailer.Driver != "log",
+		"MAILER_DRIVER: the log mailer does not deliver mail and is not allowed in production, use smtp")
+
+	return problems
+}
+
+// Settings returns every setting with its effective value and source.
+func (c *Config) Settings() []Setting {
+	return append([]Setting(nil), c.settings...)
+}
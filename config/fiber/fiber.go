@@ -1,12 +1,30 @@
 package fiber
 
 import (
+	"devsecops-be/config"
+	"devsecops-be/internal/domain/admin"
+	"devsecops-be/internal/domain/alert"
+	"devsecops-be/internal/domain/apikey"
+	"devsecops-be/internal/domain/audit"
 	"devsecops-be/internal/domain/auth"
+	"devsecops-be/internal/domain/budget"
+	"devsecops-be/internal/domain/category"
+	"devsecops-be/internal/domain/transaction"
 	"devsecops-be/internal/middleware"
 	"devsecops-be/pkg/jwt"
+	"devsecops-be/pkg/lockout"
 	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/mailer"
+	"devsecops-be/pkg/oidc"
+	"devsecops-be/pkg/password"
+	"devsecops-be/pkg/passwordpolicy"
+	"devsecops-be/pkg/ratelimit"
+	"devsecops-be/pkg/rbac"
+	"devsecops-be/pkg/revocation"
+	"devsecops-be/pkg/secretbox"
+	"devsecops-be/pkg/worker"
 	"database/sql"
-	"os"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -14,7 +32,7 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
-func NewFiberApp(appLogger logger.Logger, db *sql.DB, jwtUtil jwt.JWTUtil) *fiber.App {
+func NewFiberApp(appLogger logger.Logger, cfg *config.Config, db *sql.DB, jwtUtil jwt.JWTUtil, revocationStore revocation.Store, mailer mailer.Mailer, jobs *worker.Pool, mfaBox secretbox.Box, passUtil password.PasswordUtil, passPolicy passwordpolicy.Checker, oidcProviders map[string]oidc.Provider, attemptStore lockout.Store, rateLimitStore ratelimit.Store, authorizer rbac.Authorizer) *fiber.App {
 	app := fiber.New(fiber.Config{
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			appLogger.Error(c.Context(), "Unhandled error in Fiber", err, logger.Fields{
@@ -41,30 +59,95 @@ func NewFiberApp(appLogger logger.Logger, db *sql.DB, jwtUtil jwt.JWTUtil) *fibe
 			})
 		},
 		DisableStartupMessage: true,
+		// Behind a load balancer the client IP, used for rate limits and
+		// lockouts, comes from the proxy header but only from trusted proxies
+		ProxyHeader:             cfg.Server.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.Server.TrustedProxies,
+		EnableIPValidation:      true,
 	})
 
 	// Middleware global
 	app.Use(helmet.New())
-	app.Use(recover.New(recover.Config{EnableStackTrace: os.Getenv("ENV") == "development"}))
+	app.Use(recover.New(recover.Config{EnableStackTrace: cfg.Env == config.EnvDevelopment}))
 	app.Use(cors.New(cors.Config{
-		AllowOrigins:     getEnv("CORS_ORIGINS", "*"),
-		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",
+		AllowOrigins:     strings.Join(cfg.CORS.Origins, ","),
+		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
 		AllowHeaders:     "Origin,Content-Type,Accept,Authorization",
-		AllowCredentials: true,
+		AllowCredentials: !cfg.CORS.AllowsAnyOrigin(),
+		ExposeHeaders:    "RateLimit-Policy,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,Retry-After",
 	}))
+	app.Use(middleware.RequestID())
+	app.Use(middleware.RequestInfo())
 	app.Use(middleware.FiberLogger(appLogger))
 
-	// Auth module
-	authModule := auth.NewAuthModule(db, jwtUtil, appLogger)
-	authModule.RegisterRoutes(app)
+	// Rate limiting, stricter for the unauthenticated auth endpoints
+	app.Use("/api/v1", middleware.RateLimit(middleware.RateLimitConfig{
+		Name: "api",
+		Limit: cfg.RateLimit.API,
+		Key:   middleware.RateLimitByIP,
+		Store: rateLimitStore,
+	}, appLogger))
+	app.Use("/api/v1/auth", middleware.RateLimit(middleware.RateLimitConfig{
+		Name: "auth",
+		Limit: cfg.RateLimit.Auth,
+		Key:   middleware.RateLimitByIP,
+		Store: rateLimitStore,
+	}, appLogger))
 
+	// Authenticated requests are also counted per account and per API key, so
+	// a single caller cannot use up the budget of everyone behind its IP
+	limitAuthenticated := middleware.RateLimitAuthenticated(
+		middleware.RateLimitConfig{Name: "user", Limit: cfg.RateLimit.User, Store: rateLimitStore},
+		middleware.RateLimitConfig{Name: "apikey", Limit: cfg.RateLimit.APIKey, Store: rateLimitStore},
+	)
 
-	return app
-}
+	authMiddleware := middleware.AuthMiddleware(jwtUtil, revocationStore, appLogger, limitAuthenticated)
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+	requirePermission := func(permission string) fiber.Handler {
+		return middleware.RequirePermission(authorizer, permission, appLogger)
 	}
-	return defaultValue
+
+	// Audit module
+	auditModule := audit.NewAuditModule(db, appLogger)
+	auditModule.RegisterRoutes(app, authMiddleware)
+
+	// Auth module
+	authModule := auth.NewAuthModule(db, jwtUtil, revocationStore, mailer, jobs, mfaBox, passUtil, passPolicy, oidcProviders, attemptStore, cfg.Auth, auditModule.Service, appLogger)
+	authModule.RegisterRoutes(app, authMiddleware)
+
+	// API key module
+	apiKeyModule := apikey.NewAPIKeyModule(db, auditModule.Service, appLogger)
+	apiKeyModule.RegisterRoutes(app, authMiddleware)
+
+	// Data routes also accept API keys so scripts do not need the user's password
+	dataAuthMiddleware := middleware.AuthMiddleware(
+		jwtUtil, revocationStore, appLogger, middleware.AllowAPIKeys(apiKeyModule.Service), limitAuthenticated,
+	)
+	verifiedAuthMiddleware := middleware.AuthMiddleware(
+		jwtUtil, revocationStore, appLogger,
+		middleware.RequireVerifiedEmail(authModule.Service), middleware.AllowAPIKeys(apiKeyModule.Service), limitAuthenticated,
+	)
+
+	// Category module
+	categoryModule := category.NewCategoryModule(db, appLogger)
+	categoryModule.RegisterRoutes(app, dataAuthMiddleware)
+
+	// Budget module
+	budgetModule := budget.NewBudgetModule(db, appLogger)
+	budgetModule.RegisterRoutes(app, dataAuthMiddleware)
+
+	// Alert module
+	alertModule := alert.NewAlertModule(db, cfg.AlertThresholds, appLogger)
+	alertModule.RegisterRoutes(app, dataAuthMiddleware)
+
+	// Transaction module
+	transactionModule := transaction.NewTransactionModule(db, alertModule.Service, auditModule.Service, appLogger)
+	transactionModule.RegisterRoutes(app, dataAuthMiddleware, verifiedAuthMiddleware)
+
+	// Admin module
+	adminModule := admin.NewAdminModule(db, authModule.Service, auditModule.Service, transactionModule.Service, appLogger)
+	adminModule.RegisterRoutes(app, authMiddleware, requirePermission)
+
+	return app
 }
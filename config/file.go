@@ -0,0 +1,327 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readFile loads a YAML or TOML config file. Nested keys are joined with
+// underscores and upper-cased, so "db: {host: x}" in YAML and "[db] host =
+// 'x'" in TOML both set DB_HOST, the name of the environment variable. Lists
+// become comma separated values.
+//
+// Only the subset needed for flat settings is supported: mappings, scalars
+// and lists of scalars in YAML, tables, key/value pairs and single-line
+// arrays in TOML.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return parseYAML(string(data))
+	case ".toml":
+		return parseTOML(string(data))
+	default:
+		return nil, fmt.Errorf("unsupported config file type %q, expected .yaml, .yml or .toml", filepath.Ext(path))
+	}
+}
+
+func parseYAML(data string) (map[string]string, error) {
+	values := map[string]string{}
+	set := func(lineNo int, key, value string) error {
+		if _, ok := values[key]; ok {
+			return fmt.Errorf("line %d: %s is set twice", lineNo, key)
+		}
+		values[key] = value
+		return nil
+	}
+
+	type scope struct {
+		indent int
+		prefix string
+	}
+	scopes := []scope{{indent: 0}}
+
+	// A key without a value is followed by either a nested mapping or a list
+	var (
+		pendingKey    string
+		pendingIndent int
+		pendingLine   int
+		listKey       string
+		listIndent    int
+		listLine      int
+		list          []string
+	)
+	flushList := func() error {
+		if listKey == "" {
+			return nil
+		}
+		err := set(listLine, listKey, strings.Join(list, ","))
+		listKey, list = "", nil
+		return err
+	}
+
+	for i, line := range strings.Split(data, "\n") {
+		lineNo := i + 1
+		text := strings.TrimRight(stripComment(line), " \t\r")
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		indent := len(text) - len(strings.TrimLeft(text, " "))
+		if text[indent] == '\t' {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", lineNo)
+		}
+
+		if trimmed == "-" || strings.HasPrefix(trimmed, "- ") {
+			switch {
+			case listKey != "" && indent == listIndent:
+			case pendingKey != "" && indent >= pendingIndent:
+				listKey, listIndent, listLine = pendingKey, indent, pendingLine
+				pendingKey = ""
+			default:
+				return nil, fmt.Errorf("line %d: unexpected list item", lineNo)
+			}
+			item, err := yamlScalar(strings.TrimSpace(trimmed[1:]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			list = append(list, item)
+			continue
+		}
+		if err := flushList(); err != nil {
+			return nil, err
+		}
+
+		if pendingKey != "" {
+			if indent > pendingIndent {
+				scopes = append(scopes, scope{indent: indent, prefix: pendingKey})
+			} else if err := set(pendingLine, pendingKey, ""); err != nil {
+				return nil, err
+			}
+			pendingKey = ""
+		}
+		for len(scopes) > 1 && indent < scopes[len(scopes)-1].indent {
+			scopes = scopes[:len(scopes)-1]
+		}
+		current := scopes[len(scopes)-1]
+		if indent != current.indent {
+			return nil, fmt.Errorf("line %d: inconsistent indentation", lineNo)
+		}
+
+		key, rest, ok := strings.Cut(trimmed, ":")
+		if !ok || key == "" || (rest != "" && rest[0] != ' ') {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", lineNo)
+		}
+		name := joinKey(current.prefix, key)
+
+		rest = strings.TrimSpace(rest)
+		if rest == "" {
+			pendingKey, pendingIndent, pendingLine = name, indent, lineNo
+			continue
+		}
+		value, err := yamlValue(rest)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if err := set(lineNo, name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := flushList(); err != nil {
+		return nil, err
+	}
+	if pendingKey != "" {
+		if err := set(pendingLine, pendingKey, ""); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func yamlValue(raw string) (string, error) {
+	switch raw[0] {
+	case '[':
+		return flowList(raw, yamlScalar)
+	case '{':
+		return "", fmt.Errorf("inline mappings are not supported, use nested keys")
+	case '|', '>':
+		return "", fmt.Errorf("block scalars are not supported, use a quoted string or a _FILE setting")
+	case '&', '*', '!':
+		return "", fmt.Errorf("anchors, aliases and tags are not supported")
+	}
+	return yamlScalar(raw)
+}
+
+func yamlScalar(raw string) (string, error) {
+	switch {
+	case raw == "~" || raw == "null":
+		return "", nil
+	case strings.HasPrefix(raw, `"`):
+		value, err := strconv.Unquote(raw)
+		if err != nil {
+			return "", fmt.Errorf("invalid double-quoted string")
+		}
+		return value, nil
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+			return "", fmt.Errorf("unterminated single-quoted string")
+		}
+		return strings.ReplaceAll(raw[1:len(raw)-1], "''", "'"), nil
+	}
+	return raw, nil
+}
+
+func parseTOML(data string) (map[string]string, error) {
+	values := map[string]string{}
+	prefix := ""
+
+	for i, line := range strings.Split(data, "\n") {
+		lineNo := i + 1
+		text := strings.TrimSpace(stripComment(line))
+		if text == "" {
+			continue
+		}
+
+		if strings.HasPrefix(text, "[") {
+			if strings.HasPrefix(text, "[[") {
+				return nil, fmt.Errorf("line %d: arrays of tables are not supported", lineNo)
+			}
+			if !strings.HasSuffix(text, "]") {
+				return nil, fmt.Errorf("line %d: unterminated table header", lineNo)
+			}
+			prefix = joinKey("", strings.TrimSpace(text[1:len(text)-1]))
+			continue
+		}
+
+		key, raw, ok := strings.Cut(text, "=")
+		key, raw = strings.TrimSpace(key), strings.TrimSpace(raw)
+		if !ok || key == "" || raw == "" {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+
+		var value string
+		var err error
+		if strings.HasPrefix(raw, "[") {
+			value, err = flowList(raw, tomlScalar)
+		} else {
+			value, err = tomlScalar(raw)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+
+		name := joinKey(prefix, key)
+		if _, ok := values[name]; ok {
+			return nil, fmt.Errorf("line %d: %s is set twice", lineNo, name)
+		}
+		values[name] = value
+	}
+
+	return values, nil
+}
+
+func tomlScalar(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"""`) || strings.HasPrefix(raw, "'''"):
+		return "", fmt.Errorf("multi-line strings are not supported, use a _FILE setting")
+	case strings.HasPrefix(raw, `"`):
+		value, err := strconv.Unquote(raw)
+		if err != nil {
+			return "", fmt.Errorf("invalid basic string")
+		}
+		return value, nil
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+			return "", fmt.Errorf("unterminated literal string")
+		}
+		return raw[1 : len(raw)-1], nil
+	case raw == "true" || raw == "false":
+		return raw, nil
+	}
+
+	if _, err := strconv.ParseFloat(strings.ReplaceAll(raw, "_", ""), 64); err != nil {
+		return "", fmt.Errorf("strings must be quoted")
+	}
+	return strings.ReplaceAll(raw, "_", ""), nil
+}
+
+// flowList parses a single-line list such as [80, 100] or ["a", "b"].
+func flowList(raw string, scalar func(string) (string, error)) (string, error) {
+	if !strings.HasSuffix(raw, "]") {
+		return "", fmt.Errorf("lists must be written on one line")
+	}
+
+	var items []string
+	for _, part := range splitOutsideQuotes(raw[1:len(raw)-1], ',') {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		item, err := scalar(part)
+		if err != nil {
+			return "", err
+		}
+		items = append(items, item)
+	}
+	return strings.Join(items, ","), nil
+}
+
+// stripComment drops a # comment that is not inside quotes.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\':
+			// The escaped character cannot close the string
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func splitOutsideQuotes(s string, sep rune) []string {
+	var parts []string
+	var quote rune
+	start := 0
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// joinKey turns a nested key into the environment variable name.
+func joinKey(prefix, key string) string {
+	key = strings.Trim(strings.TrimSpace(key), `"'`)
+	key = strings.ToUpper(strings.NewReplacer(".", "_", "-", "_", " ", "").Replace(key))
+	if prefix == "" {
+		return key
+	}
+	return prefix + "_" + key
+}
@@ -0,0 +1,264 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "flat keys",
+			data: "env: production\nport: 8080\n",
+			want: map[string]string{"ENV": "production", "PORT": "8080"},
+		},
+		{
+			name: "nested mappings are joined with underscores",
+			data: "db:\n  host: db.internal\n  ssl-mode: require\njwt:\n  access:\n    exp_minutes: 15\nport: 80\n",
+			want: map[string]string{"DB_HOST": "db.internal", "DB_SSL_MODE": "require", "JWT_ACCESS_EXP_MINUTES": "15", "PORT": "80"},
+		},
+		{
+			name: "comments, blank lines and document marker",
+			data: "---\n# settings\nenv: test # inline\n\nurl: http://example.com/#anchor\n",
+			want: map[string]string{"ENV": "test", "URL": "http://example.com/#anchor"},
+		},
+		{
+			name: "quoted values",
+			data: "a: \"has # hash\"\nb: 'it''s'\nc: \"tab\\there \\\"q\\\"\"\nd: '# not a comment'\n",
+			want: map[string]string{"A": "has # hash", "B": "it's", "C": "tab\there \"q\"", "D": "# not a comment"},
+		},
+		{
+			name: "null and empty values",
+			data: "a: ~\nb: null\nc:\nd: x\n",
+			want: map[string]string{"A": "", "B": "", "C": "", "D": "x"},
+		},
+		{
+			name: "block and flow lists",
+			data: "cors_origins:\n  - https://a.example.com\n  - 'https://b.example.com'\nthresholds: [80, 100]\n",
+			want: map[string]string{"CORS_ORIGINS": "https://a.example.com,https://b.example.com", "THRESHOLDS": "80,100"},
+		},
+		{
+			name: "block list at the same indent as its key",
+			data: "origins:\n- https://a.example.com\n- https://b.example.com\nport: 80\n",
+			want: map[string]string{"ORIGINS": "https://a.example.com,https://b.example.com", "PORT": "80"},
+		},
+		{
+			name: "trailing key without value",
+			data: "port: 80\nsmtp_password:",
+			want: map[string]string{"PORT": "80", "SMTP_PASSWORD": ""},
+		},
+		{
+			name:    "duplicate key",
+			data:    "port: 80\nport: 81\n",
+			wantErr: "line 2: PORT is set twice",
+		},
+		{
+			name:    "duplicate key through nesting",
+			data:    "db_host: a\ndb:\n  host: b\n",
+			wantErr: "line 3: DB_HOST is set twice",
+		},
+		{
+			name:    "duplicate list",
+			data:    "origins:\n  - a\norigins: [b]\n",
+			wantErr: "ORIGINS is set twice",
+		},
+		{
+			name:    "tab indentation",
+			data:    "db:\n\thost: x\n",
+			wantErr: "line 2: tabs are not allowed",
+		},
+		{
+			name:    "inconsistent indentation",
+			data:    "db:\n    host: x\n  port: 5432\n",
+			wantErr: "line 3: inconsistent indentation",
+		},
+		{
+			name:    "list item without a key",
+			data:    "port: 80\n  - a\n",
+			wantErr: "line 2: unexpected list item",
+		},
+		{
+			name:    "list item after the list ended",
+			data:    "origins:\n  - a\nport: 80\n  - b\n",
+			wantErr: "line 4: unexpected list item",
+		},
+		{
+			name:    "missing colon",
+			data:    "port 80\n",
+			wantErr: `line 1: expected "key: value"`,
+		},
+		{
+			name:    "multi-line flow list",
+			data:    "thresholds: [80,\n  100]\n",
+			wantErr: "line 1: lists must be written on one line",
+		},
+		{
+			name:    "inline mapping",
+			data:    "db: {host: x}\n",
+			wantErr: "inline mappings are not supported",
+		},
+		{
+			name:    "block scalar",
+			data:    "key: |\n  secret\n",
+			wantErr: "block scalars are not supported",
+		},
+		{
+			name:    "anchor",
+			data:    "key: &anchor x\n",
+			wantErr: "anchors, aliases and tags are not supported",
+		},
+		{
+			name:    "unterminated single quote",
+			data:    "key: 'open\n",
+			wantErr: "unterminated single-quoted string",
+		},
+		{
+			name:    "invalid double quote",
+			data:    "key: \"open\n",
+			wantErr: "invalid double-quoted string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML(tt.data)
+			checkParsed(t, got, err, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "top-level keys and tables",
+			data: "env = \"production\"\nport = 8_080\n\n[db]\nhost = \"db.internal\"\nmax-open-conns = 25\n\n[smtp]\nenabled = true\n",
+			want: map[string]string{"ENV": "production", "PORT": "8080", "DB_HOST": "db.internal", "DB_MAX_OPEN_CONNS": "25", "SMTP_ENABLED": "true"},
+		},
+		{
+			name: "dotted table and key names",
+			data: "[jwt.access]\n\"exp.minutes\" = 15\n",
+			want: map[string]string{"JWT_ACCESS_EXP_MINUTES": "15"},
+		},
+		{
+			name: "basic and literal strings",
+			data: "a = \"has # hash\"\nb = 'C:\\path\\no escapes'\nc = \"esc \\\"q\\\" \\\\\" # comment\n",
+			want: map[string]string{"A": "has # hash", "B": `C:\path\no escapes`, "C": `esc "q" \`},
+		},
+		{
+			name: "arrays",
+			data: "origins = [\"https://a.example.com\", 'https://b.example.com']\nthresholds = [80, 100,]\nempty = []\n",
+			want: map[string]string{"ORIGINS": "https://a.example.com,https://b.example.com", "THRESHOLDS": "80,100", "EMPTY": ""},
+		},
+		{
+			name: "quoted comma inside an array",
+			data: "names = [\"a,b\", \"c\"]\n",
+			want: map[string]string{"NAMES": "a,b,c"},
+		},
+		{
+			name:    "duplicate key",
+			data:    "port = 80\nport = 81\n",
+			wantErr: "line 2: PORT is set twice",
+		},
+		{
+			name:    "duplicate key through a table",
+			data:    "db_host = \"a\"\n[db]\nhost = \"b\"\n",
+			wantErr: "line 3: DB_HOST is set twice",
+		},
+		{
+			name:    "unquoted string",
+			data:    "env = production\n",
+			wantErr: "line 1: strings must be quoted",
+		},
+		{
+			name:    "missing value",
+			data:    "env =\n",
+			wantErr: "line 1: expected key = value",
+		},
+		{
+			name:    "missing equals sign",
+			data:    "[db]\nhost\n",
+			wantErr: "line 2: expected key = value",
+		},
+		{
+			name:    "array of tables",
+			data:    "[[providers]]\nname = \"x\"\n",
+			wantErr: "arrays of tables are not supported",
+		},
+		{
+			name:    "unterminated table header",
+			data:    "[db\n",
+			wantErr: "unterminated table header",
+		},
+		{
+			name:    "multi-line array",
+			data:    "origins = [\n  \"a\",\n]\n",
+			wantErr: "lists must be written on one line",
+		},
+		{
+			name:    "multi-line string",
+			data:    "key = \"\"\"\nsecret\n\"\"\"\n",
+			wantErr: "multi-line strings are not supported",
+		},
+		{
+			name:    "unterminated literal string",
+			data:    "key = 'open\n",
+			wantErr: "unterminated literal string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTOML(tt.data)
+			checkParsed(t, got, err, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func TestStripComment(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"key: value # comment", "key: value "},
+		{"# whole line", ""},
+		{"url: http://example.com/#frag", "url: http://example.com/#frag"},
+		{`key: "a # b" # c`, `key: "a # b" `},
+		{`key: 'a # b'`, `key: 'a # b'`},
+		{`key: "escaped \" # still quoted" # c`, `key: "escaped \" # still quoted" `},
+		{"key: value\t# tab before comment", "key: value\t"},
+	}
+
+	for _, tt := range tests {
+		if got := stripComment(tt.line); got != tt.want {
+			t.Errorf("stripComment(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func checkParsed(t *testing.T, got map[string]string, err error, want map[string]string, wantErr string) {
+	t.Helper()
+
+	if wantErr != "" {
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Fatalf("error = %v, want one containing %q", err, wantErr)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("values = %v, want %v", got, want)
+	}
+}
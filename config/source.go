@@ -0,0 +1,188 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Setting is one loaded value, as shown by "app config print".
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+	Secret bool
+}
+
+// loader reads settings from the environment, then the config file, and
+// collects every problem instead of stopping at the first one.
+type loader struct {
+	file     map[string]string
+	fileName string
+	used     map[string]bool
+	settings []Setting
+	problems []string
+}
+
+func newLoader(fileName string) (*loader, error) {
+	l := &loader{fileName: fileName, file: map[string]string{}, used: map[string]bool{}}
+	if fileName == "" {
+		return l, nil
+	}
+
+	values, err := readFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONFIG_FILE: %w", err)
+	}
+	l.file = values
+	return l, nil
+}
+
+func (l *loader) problemf(format string, args ...interface{}) {
+	l.problems = append(l.problems, fmt.Sprintf(format, args...))
+}
+
+// lookup returns the value of key and where it came from. KEY_FILE names a
+// file holding the value, as Docker secrets are mounted. Empty values count
+// as unset.
+func (l *loader) lookup(key string) (string, string, bool) {
+	l.used[key], l.used[key+"_FILE"] = true, true
+
+	if value, source, ok := l.lookupIn(key, "environment", os.Getenv); ok {
+		return value, source, true
+	}
+	return l.lookupIn(key, l.fileName, func(name string) string { return l.file[name] })
+}
+
+func (l *loader) lookupIn(key, source string, get func(string) string) (string, string, bool) {
+	value, path := get(key), get(key+"_FILE")
+	switch {
+	case value != "" && path != "":
+		l.problemf("%s and %s_FILE are both set in the %s", key, key, source)
+		return value, source, true
+	case path != "":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			l.problemf("%s_FILE: %v", key, err)
+			return "", "", false
+		}
+		return strings.TrimRight(string(data), "\r\n"), source + " (" + key + "_FILE)", true
+	case value != "":
+		return value, source, true
+	}
+	return "", "", false
+}
+
+// has reports whether key is set, without recording it as a setting.
+func (l *loader) has(key string) bool {
+	l.used[key], l.used[key+"_FILE"] = true, true
+	for _, get := range []func(string) string{os.Getenv, func(name string) string { return l.file[name] }} {
+		if get(key) != "" || get(key+"_FILE") != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *loader) get(key, fallback string, secret bool) (string, bool) {
+	value, source, ok := l.lookup(key)
+	if !ok {
+		value, source = fallback, "default"
+	}
+	l.settings = append(l.settings, Setting{Key: key, Value: value, Source: source, Secret: secret})
+	return value, ok
+}
+
+func (l *loader) string(key, fallback string) string {
+	value, _ := l.get(key, fallback, false)
+	return value
+}
+
+func (l *loader) secret(key, fallback string) string {
+	value, _ := l.get(key, fallback, true)
+	return value
+}
+
+func (l *loader) int(key string, fallback int) int {
+	return l.intBetween(key, fallback, math.MinInt, math.MaxInt)
+}
+
+// intBetween reads an integer that must lie within [min, max].
+func (l *loader) intBetween(key string, fallback, min, max int) int {
+	raw, ok := l.get(key, strconv.Itoa(fallback), false)
+	if !ok {
+		return fallback
+	}
+
+	value, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil {
+		l.problemf("%s: %q is not a whole number", key, raw)
+		return fallback
+	}
+	if value < min || value > max {
+		l.problemf("%s: %d is out of range, expected %d to %d", key, value, min, max)
+		return fallback
+	}
+	return value
+}
+
+func (l *loader) bool(key string, fallback bool) bool {
+	raw, ok := l.get(key, strconv.FormatBool(fallback), false)
+	if !ok {
+		return fallback
+	}
+
+	value, err := strconv.ParseBool(strings.TrimSpace(raw))
+	if err != nil {
+		l.problemf("%s: %q is not true or false", key, raw)
+		return fallback
+	}
+	return value
+}
+
+// duration reads a whole number of units, the unit being part of the key
+// name as in JWT_REFRESH_EXP_HOURS.
+func (l *loader) duration(key string, fallback int, unit time.Duration) time.Duration {
+	return time.Duration(l.int(key, fallback)) * unit
+}
+
+// list reads a comma separated list.
+func (l *loader) list(key, fallback string) []string {
+	raw, _ := l.get(key, fallback, false)
+
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// fields reads a list separated by commas or spaces.
+func (l *loader) fields(key, fallback string) []string {
+	raw, _ := l.get(key, fallback, false)
+	return strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// checkUnused reports settings in the config file that nothing reads,
+// which are usually typos.
+func (l *loader) checkUnused() {
+	var unused []string
+	for key := range l.file {
+		if !l.used[key] {
+			unused = append(unused, key)
+		}
+	}
+	sort.Strings(unused)
+	for _, key := range unused {
+		l.problemf("%s: unknown setting %s", l.fileName, key)
+	}
+}
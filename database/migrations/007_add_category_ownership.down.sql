@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS categories_user_name_key;
+DROP INDEX IF EXISTS categories_default_name_key;
+
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS archived_at,
+    DROP COLUMN IF EXISTS user_id;
@@ -0,0 +1,29 @@
+ALTER TABLE categories
+    ADD COLUMN user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    ADD COLUMN archived_at TIMESTAMP,
+    ADD COLUMN created_at TIMESTAMP DEFAULT NOW(),
+    ADD COLUMN updated_at TIMESTAMP DEFAULT NOW();
+
+-- Categories without an owner are system defaults shared by every user.
+CREATE UNIQUE INDEX categories_default_name_key ON categories (LOWER(name)) WHERE user_id IS NULL;
+CREATE UNIQUE INDEX categories_user_name_key ON categories (user_id, LOWER(name)) WHERE user_id IS NOT NULL;
+
+INSERT INTO categories (name)
+SELECT d.name
+FROM (VALUES
+    ('Food'),
+    ('Transport'),
+    ('Shopping'),
+    ('Bills'),
+    ('Entertainment'),
+    ('Health'),
+    ('Education'),
+    ('Salary'),
+    ('Bonus'),
+    ('Investment'),
+    ('Gift'),
+    ('Other')
+) AS d(name)
+WHERE NOT EXISTS (
+    SELECT 1 FROM categories c WHERE c.user_id IS NULL AND LOWER(c.name) = LOWER(d.name)
+);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS maximum_spends_user_id_key;
@@ -0,0 +1,7 @@
+-- Keep only the most recently updated limits row per user before enforcing uniqueness.
+DELETE FROM maximum_spends a
+USING maximum_spends b
+WHERE a.user_id = b.user_id
+  AND (COALESCE(a.updated_at, 'epoch'), a.ctid) < (COALESCE(b.updated_at, 'epoch'), b.ctid);
+
+CREATE UNIQUE INDEX maximum_spends_user_id_key ON maximum_spends (user_id);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS alerts_user_triggered_at_idx;
+DROP INDEX IF EXISTS alerts_user_period_threshold_key;
+
+ALTER TABLE alerts
+    DROP COLUMN IF EXISTS read_at,
+    DROP COLUMN IF EXISTS limit_amount,
+    DROP COLUMN IF EXISTS spent,
+    DROP COLUMN IF EXISTS period_start,
+    DROP COLUMN IF EXISTS threshold;
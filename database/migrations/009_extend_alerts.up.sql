@@ -0,0 +1,10 @@
+ALTER TABLE alerts
+    ADD COLUMN threshold SMALLINT,
+    ADD COLUMN period_start DATE,
+    ADD COLUMN spent DECIMAL(12,2),
+    ADD COLUMN limit_amount DECIMAL(12,2),
+    ADD COLUMN read_at TIMESTAMP;
+
+-- A threshold fires at most once per user, period type and period.
+CREATE UNIQUE INDEX alerts_user_period_threshold_key ON alerts (user_id, type, period_start, threshold);
+CREATE INDEX alerts_user_triggered_at_idx ON alerts (user_id, triggered_at DESC);
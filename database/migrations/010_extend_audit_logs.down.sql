@@ -0,0 +1,23 @@
+DROP TRIGGER IF EXISTS audit_logs_append_only_trigger ON audit_logs;
+DROP TRIGGER IF EXISTS audit_logs_chain_trigger ON audit_logs;
+DROP FUNCTION IF EXISTS audit_logs_prevent_modification();
+DROP FUNCTION IF EXISTS audit_logs_chain();
+DROP FUNCTION IF EXISTS audit_log_hash(TEXT, UUID, TEXT, TEXT, TEXT, TEXT, TEXT, TEXT, JSONB, JSONB, TIMESTAMP);
+
+DROP INDEX IF EXISTS audit_logs_user_created_at_idx;
+DROP INDEX IF EXISTS audit_logs_seq_key;
+
+ALTER TABLE audit_logs
+    DROP COLUMN IF EXISTS hash,
+    DROP COLUMN IF EXISTS prev_hash,
+    DROP COLUMN IF EXISTS metadata,
+    DROP COLUMN IF EXISTS changes,
+    DROP COLUMN IF EXISTS request_id,
+    DROP COLUMN IF EXISTS user_agent,
+    DROP COLUMN IF EXISTS ip_address,
+    DROP COLUMN IF EXISTS resource_id,
+    DROP COLUMN IF EXISTS resource_type,
+    DROP COLUMN IF EXISTS seq;
+
+DELETE FROM audit_logs WHERE user_id IS NULL;
+ALTER TABLE audit_logs ALTER COLUMN user_id SET NOT NULL;
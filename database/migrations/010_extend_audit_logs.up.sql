@@ -0,0 +1,103 @@
+-- Failed logins for unknown emails have no user to attach to.
+ALTER TABLE audit_logs ALTER COLUMN user_id DROP NOT NULL;
+
+ALTER TABLE audit_logs
+    ADD COLUMN seq BIGSERIAL,
+    ADD COLUMN resource_type VARCHAR(50),
+    ADD COLUMN resource_id VARCHAR(100),
+    ADD COLUMN ip_address VARCHAR(45),
+    ADD COLUMN user_agent TEXT,
+    ADD COLUMN request_id VARCHAR(100),
+    ADD COLUMN changes JSONB,
+    ADD COLUMN metadata JSONB,
+    ADD COLUMN prev_hash CHAR(64),
+    ADD COLUMN hash CHAR(64);
+
+CREATE UNIQUE INDEX audit_logs_seq_key ON audit_logs (seq);
+CREATE INDEX audit_logs_user_created_at_idx ON audit_logs (user_id, created_at DESC);
+
+-- Each row's hash covers its content and the previous row's hash, so editing
+-- or removing any row breaks the chain from that point on. created_at is
+-- hashed in a fixed format, its ::TEXT form follows the session's DateStyle.
+-- The text forms of uuid and jsonb are canonical.
+CREATE OR REPLACE FUNCTION audit_log_hash(
+    p_prev_hash TEXT,
+    p_user_id UUID,
+    p_action TEXT,
+    p_resource_type TEXT,
+    p_resource_id TEXT,
+    p_ip_address TEXT,
+    p_user_agent TEXT,
+    p_request_id TEXT,
+    p_changes JSONB,
+    p_metadata JSONB,
+    p_created_at TIMESTAMP
+) RETURNS CHAR(64) AS $$
+    SELECT encode(digest(concat_ws('|',
+        COALESCE(p_prev_hash, ''),
+        COALESCE(p_user_id::TEXT, ''),
+        COALESCE(p_action, ''),
+        COALESCE(p_resource_type, ''),
+        COALESCE(p_resource_id, ''),
+        COALESCE(p_ip_address, ''),
+        COALESCE(p_user_agent, ''),
+        COALESCE(p_request_id, ''),
+        COALESCE(p_changes::TEXT, ''),
+        COALESCE(p_metadata::TEXT, ''),
+        COALESCE(to_char(p_created_at, 'YYYY-MM-DD"T"HH24:MI:SS.US'), '')
+    ), 'sha256'), 'hex')
+$$ LANGUAGE SQL STABLE;
+
+CREATE OR REPLACE FUNCTION audit_logs_chain() RETURNS TRIGGER AS $$
+BEGIN
+    -- Serialize writers so every row links to the row committed before it.
+    PERFORM pg_advisory_xact_lock(hashtext('audit_logs_chain'));
+
+    -- The column default drew seq before the lock was taken, so concurrent
+    -- writers could hold the lock in a different order. Draw it again under
+    -- the lock so seq order is chain order.
+    NEW.seq := nextval(pg_get_serial_sequence('audit_logs', 'seq'));
+
+    SELECT hash INTO NEW.prev_hash FROM audit_logs ORDER BY seq DESC LIMIT 1;
+
+    NEW.hash := audit_log_hash(
+        NEW.prev_hash, NEW.user_id, NEW.action, NEW.resource_type, NEW.resource_id,
+        NEW.ip_address, NEW.user_agent, NEW.request_id, NEW.changes, NEW.metadata, NEW.created_at
+    );
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION audit_logs_prevent_modification() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+-- Rows written before the chain existed start it, in the order they got seq.
+DO $$
+DECLARE
+    r audit_logs%ROWTYPE;
+    prev CHAR(64);
+BEGIN
+    FOR r IN SELECT * FROM audit_logs ORDER BY seq LOOP
+        UPDATE audit_logs
+        SET prev_hash = prev,
+            hash = audit_log_hash(
+                prev, r.user_id, r.action, r.resource_type, r.resource_id,
+                r.ip_address, r.user_agent, r.request_id, r.changes, r.metadata, r.created_at
+            )
+        WHERE seq = r.seq
+        RETURNING hash INTO prev;
+    END LOOP;
+END;
+$$;
+
+CREATE TRIGGER audit_logs_chain_trigger
+    BEFORE INSERT ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_chain();
+
+CREATE TRIGGER audit_logs_append_only_trigger
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_prevent_modification();
@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT NOW();
+
+UPDATE users SET updated_at = created_at WHERE updated_at IS NULL;
+
+-- Registration and profile updates rely on a unique violation to detect taken emails.
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_unique_idx ON users (LOWER(email));
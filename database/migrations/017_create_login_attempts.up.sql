@@ -0,0 +1,9 @@
+-- Failed login counters keyed by "email:<address>" or "ip:<address>".
+CREATE TABLE login_attempts (
+    key VARCHAR(320) PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX login_attempts_expires_at_idx ON login_attempts (expires_at);
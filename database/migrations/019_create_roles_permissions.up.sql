@@ -0,0 +1,50 @@
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(50) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    name VARCHAR(100) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role VARCHAR(50) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    permission VARCHAR(100) NOT NULL REFERENCES permissions(name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('user', 'Regular account, can only access its own data'),
+    ('support', 'Support staff, read-only access to any account'),
+    ('admin', 'Administrator, full access to any account')
+ON CONFLICT (name) DO NOTHING;
+
+-- Access to a user's own resources is implied; permissions only cover access
+-- to other users' data.
+INSERT INTO permissions (name, description) VALUES
+    ('users:read:any', 'List and view any user account'),
+    ('users:disable:any', 'Disable and re-enable any user account'),
+    ('audit_logs:read:any', 'View the audit log of any user'),
+    ('transactions:read:any', 'View the transactions of any user')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('support', 'users:read:any'),
+    ('support', 'audit_logs:read:any'),
+    ('support', 'transactions:read:any'),
+    ('admin', 'users:read:any'),
+    ('admin', 'users:disable:any'),
+    ('admin', 'audit_logs:read:any'),
+    ('admin', 'transactions:read:any')
+ON CONFLICT DO NOTHING;
+
+-- Every account starts as 'user'. Staff are promoted by hand, for example:
+--   UPDATE users SET role = 'admin' WHERE email = 'ops@example.com';
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(50) NOT NULL DEFAULT 'user' REFERENCES roles(name),
+    ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS users_role_idx ON users (role) WHERE role <> 'user';
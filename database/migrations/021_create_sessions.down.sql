@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS refresh_tokens DROP CONSTRAINT IF EXISTS refresh_tokens_session_id_fkey;
+
+DROP TABLE IF EXISTS sessions;
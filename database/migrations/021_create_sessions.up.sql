@@ -0,0 +1,24 @@
+-- A session is one login on one device. Its id is the family_id shared by the
+-- refresh tokens rotated from that login, and the sid claim of its access tokens.
+CREATE TABLE sessions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device VARCHAR(100) NOT NULL DEFAULT '',
+    user_agent TEXT,
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP DEFAULT NOW(),
+    last_seen_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
+
+-- Logins made before sessions existed have no device details.
+INSERT INTO sessions (id, user_id, created_at, last_seen_at)
+SELECT family_id, user_id, MIN(created_at), MAX(created_at)
+FROM refresh_tokens
+GROUP BY family_id, user_id
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE refresh_tokens
+    ADD CONSTRAINT refresh_tokens_session_id_fkey
+    FOREIGN KEY (family_id) REFERENCES sessions(id) ON DELETE CASCADE;
@@ -0,0 +1,4 @@
+-- An email change only takes effect once the new address is verified; until
+-- then it is kept here and the account keeps its current email.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS pending_email VARCHAR(255);
@@ -0,0 +1,4 @@
+-- When the last password reset link was sent, to rate limit them per account
+-- the same way verification_sent_at does for verification links.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS password_reset_sent_at TIMESTAMP;
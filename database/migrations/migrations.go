@@ -0,0 +1,8 @@
+// Package migrations embeds the SQL migrations into the binary so that
+// pkg/migrate can apply them without the files being deployed alongside.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
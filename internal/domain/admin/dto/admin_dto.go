@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"devsecops-be/pkg/response"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserFilter struct {
+	Search   string `query:"search" validate:"omitempty,max=255"`
+	Role     string `query:"role" validate:"omitempty,max=50"`
+	Disabled *bool  `query:"disabled"`
+	Page     int    `query:"page" validate:"omitempty,min=1"`
+	Limit    int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type DisableUserRequest struct {
+	Reason string `json:"reason" validate:"omitempty,max=500"`
+}
+
+type UserData struct {
+	ID            uuid.UUID  `json:"id"`
+	Name          string     `json:"name"`
+	Email         string     `json:"email"`
+	Role          string     `json:"role"`
+	EmailVerified bool       `json:"email_verified"`
+	MFAEnabled    bool       `json:"mfa_enabled"`
+	DisabledAt    *time.Time `json:"disabled_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+type UserListResponse struct {
+	Users      []UserData          `json:"users"`
+	Pagination response.Pagination `json:"pagination"`
+}
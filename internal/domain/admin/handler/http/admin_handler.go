@@ -0,0 +1,195 @@
+package http
+
+import (
+	"devsecops-be/internal/domain/admin/dto"
+	"devsecops-be/internal/domain/admin/service"
+	auditDto "devsecops-be/internal/domain/audit/dto"
+	transactionDto "devsecops-be/internal/domain/transaction/dto"
+	"devsecops-be/internal/middleware"
+	"devsecops-be/pkg/errors"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/response"
+	"devsecops-be/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AdminHandler struct {
+	adminService service.AdminService
+	validator    validator.Validator
+	logger       logger.Logger
+}
+
+func NewAdminHandler(
+	adminService service.AdminService,
+	validator validator.Validator,
+	logger logger.Logger,
+) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+		validator:    validator,
+		logger:       logger,
+	}
+}
+
+func (h *AdminHandler) ListUsers(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var filter dto.UserFilter
+
+	if err := c.QueryParser(&filter); err != nil {
+		h.logger.Warn(ctx, "Invalid query parameters in admin list users", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid query parameters", nil)
+	}
+
+	if err := h.validator.Validate(filter); err != nil {
+		h.logger.Warn(ctx, "Validation failed in admin list users", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.adminService.ListUsers(ctx, filter)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Users retrieved successfully", result)
+}
+
+func (h *AdminHandler) GetUser(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID", nil)
+	}
+
+	result, err := h.adminService.GetUser(ctx, id)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "User retrieved successfully", result)
+}
+
+func (h *AdminHandler) DisableUser(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID", nil)
+	}
+
+	// The body is optional, it only carries the reason recorded in the audit log
+	var req dto.DisableUserRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			h.logger.Warn(ctx, "Invalid request body in disable user", logger.Fields{
+				"error": err.Error(),
+			})
+			return response.BadRequest(c, "Invalid request body", nil)
+		}
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in disable user", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.adminService.DisableUser(ctx, actorID, id, req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "User disabled successfully", result)
+}
+
+func (h *AdminHandler) EnableUser(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID", nil)
+	}
+
+	result, err := h.adminService.EnableUser(ctx, actorID, id)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "User enabled successfully", result)
+}
+
+func (h *AdminHandler) ListUserAuditLogs(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID", nil)
+	}
+
+	var filter auditDto.AuditLogFilter
+
+	if err := c.QueryParser(&filter); err != nil {
+		h.logger.Warn(ctx, "Invalid query parameters in admin list audit logs", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid query parameters", nil)
+	}
+
+	if err := h.validator.Validate(filter); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.adminService.ListUserAuditLogs(ctx, id, filter)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Audit logs retrieved successfully", result)
+}
+
+func (h *AdminHandler) ListUserTransactions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID", nil)
+	}
+
+	var filter transactionDto.TransactionFilter
+
+	if err := c.QueryParser(&filter); err != nil {
+		h.logger.Warn(ctx, "Invalid query parameters in admin list transactions", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid query parameters", nil)
+	}
+
+	if err := h.validator.Validate(filter); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.adminService.ListUserTransactions(ctx, id, filter)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Transactions retrieved successfully", result)
+}
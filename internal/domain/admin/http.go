@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"database/sql"
+	"devsecops-be/internal/domain/admin/handler/http"
+	"devsecops-be/internal/domain/admin/repository"
+	"devsecops-be/internal/domain/admin/service"
+	auditService "devsecops-be/internal/domain/audit/service"
+	transactionService "devsecops-be/internal/domain/transaction/service"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/rbac"
+	"devsecops-be/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AdminModule struct {
+	Handler *http.AdminHandler
+	Service service.AdminService
+}
+
+func NewAdminModule(
+	db *sql.DB,
+	sessions service.SessionRevoker,
+	audit auditService.AuditService,
+	transactions transactionService.TransactionService,
+	logger logger.Logger,
+) *AdminModule {
+	// Initialize dependencies
+	adminRepo := repository.NewAdminRepository(db)
+	validator := validator.NewValidator()
+
+	// Initialize service
+	adminService := service.NewAdminService(adminRepo, sessions, audit, transactions, logger)
+
+	// Initialize handler
+	adminHandler := http.NewAdminHandler(adminService, validator, logger)
+
+	return &AdminModule{
+		Handler: adminHandler,
+		Service: adminService,
+	}
+}
+
+// RegisterRoutes mounts the support and administration routes. Every route
+// requires a permission in addition to authentication; requirePermission
+// builds the guard for one permission.
+func (m *AdminModule) RegisterRoutes(app *fiber.App, authMiddleware fiber.Handler, requirePermission func(permission string) fiber.Handler) {
+	admin := app.Group("/api/v1/admin", authMiddleware)
+
+	admin.Get("/users", requirePermission(rbac.PermUsersReadAny), m.Handler.ListUsers)
+	admin.Get("/users/:id", requirePermission(rbac.PermUsersReadAny), m.Handler.GetUser)
+	admin.Post("/users/:id/disable", requirePermission(rbac.PermUsersDisableAny), m.Handler.DisableUser)
+	admin.Post("/users/:id/enable", requirePermission(rbac.PermUsersDisableAny), m.Handler.EnableUser)
+	admin.Get("/users/:id/audit-logs", requirePermission(rbac.PermAuditLogsReadAny), m.Handler.ListUserAuditLogs)
+	admin.Get("/users/:id/transactions", requirePermission(rbac.PermTransactionsReadAny), m.Handler.ListUserTransactions)
+}
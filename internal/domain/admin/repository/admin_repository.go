@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AdminRepository interface {
+	ListUsers(ctx context.Context, filter UserQuery, limit, offset int) ([]User, int, error)
+	GetUser(ctx context.Context, id uuid.UUID) (*User, error)
+	GetUserByEmail(ctx context.Context, email string) (*User, error)
+	SetDisabled(ctx context.Context, id uuid.UUID, disabled bool) (*User, error)
+	SetRole(ctx context.Context, id uuid.UUID, role string) (*User, error)
+}
+
+// UserQuery narrows ListUsers. Empty fields match every user.
+type UserQuery struct {
+	Search   string
+	Role     string
+	Disabled *bool
+}
+
+type User struct {
+	ID         uuid.UUID    `db:"id"`
+	Name       string       `db:"name"`
+	Email      string       `db:"email"`
+	Role       string       `db:"role"`
+	VerifiedAt sql.NullTime `db:"verified_at"`
+	DisabledAt sql.NullTime `db:"disabled_at"`
+	MFAEnabled bool         `db:"mfa_enabled"`
+	CreatedAt  time.Time    `db:"created_at"`
+	UpdatedAt  time.Time    `db:"updated_at"`
+}
+
+const userColumns = `u.id, u.name, u.email, u.role, u.verified_at, u.disabled_at,
+        EXISTS (SELECT 1 FROM user_mfa m WHERE m.user_id = u.id AND m.enabled_at IS NOT NULL),
+        u.created_at, u.updated_at`
+
+const userFilter = `
+        ($1 = '' OR u.name ILIKE '%' || $1 || '%' OR u.email ILIKE '%' || $1 || '%')
+        AND ($2 = '' OR u.role = $2)
+        AND ($3::BOOLEAN IS NULL OR (u.disabled_at IS NOT NULL) = $3)`
+
+type adminRepository struct {
+	db *sql.DB
+}
+
+func NewAdminRepository(db *sql.DB) AdminRepository {
+	return &adminRepository{db: db}
+}
+
+func (r *adminRepository) ListUsers(ctx context.Context, filter UserQuery, limit, offset int) ([]User, int, error) {
+	var total int
+	countQuery := `SELECT COUNT(*) FROM users u WHERE` + userFilter
+	if err := r.db.QueryRowContext(ctx, countQuery, filter.Search, filter.Role, filter.Disabled).Scan(&total); err != nil {
+		return nil, 0, errors.WrapDatabaseError(err, "failed to count users")
+	}
+
+	query := `
+        SELECT ` + userColumns + `
+        FROM users u
+        WHERE` + userFilter + `
+        ORDER BY u.created_at DESC, u.id
+        LIMIT $4 OFFSET $5
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, filter.Search, filter.Role, filter.Disabled, limit, offset)
+	if err != nil {
+		return nil, 0, errors.WrapDatabaseError(err, "failed to list users")
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, errors.WrapDatabaseError(err, "failed to scan user")
+		}
+		users = append(users, *user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.WrapDatabaseError(err, "failed to list users")
+	}
+
+	return users, total, nil
+}
+
+func (r *adminRepository) GetUser(ctx context.Context, id uuid.UUID) (*User, error) {
+	query := `
+        SELECT ` + userColumns + `
+        FROM users u
+        WHERE u.id = $1
+    `
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to get user")
+	}
+
+	return user, nil
+}
+
+func (r *adminRepository) GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	query := `
+        SELECT ` + userColumns + `
+        FROM users u
+        WHERE u.email = $1
+    `
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, email))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to get user")
+	}
+
+	return user, nil
+}
+
+// SetDisabled disables or re-enables the account. Disabling an already
+// disabled account keeps the original disabled_at.
+func (r *adminRepository) SetDisabled(ctx context.Context, id uuid.UUID, disabled bool) (*User, error) {
+	query := `
+        UPDATE users u
+        SET disabled_at = CASE WHEN $2 THEN COALESCE(u.disabled_at, NOW()) END,
+            updated_at = NOW()
+        WHERE u.id = $1
+        RETURNING ` + userColumns
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, id, disabled))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to update user")
+	}
+
+	return user, nil
+}
+
+func (r *adminRepository) SetRole(ctx context.Context, id uuid.UUID, role string) (*User, error) {
+	query := `
+        UPDATE users u
+        SET role = $2, updated_at = NOW()
+        WHERE u.id = $1
+        RETURNING ` + userColumns
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, id, role))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to update user role")
+	}
+
+	return user, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUser(row rowScanner) (*User, error) {
+	var user User
+	err := row.Scan(
+		&user.ID, &user.Name, &user.Email, &user.Role, &user.VerifiedAt, &user.DisabledAt,
+		&user.MFAEnabled, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"devsecops-be/internal/domain/admin/dto"
+	"devsecops-be/internal/domain/admin/repository"
+	auditDto "devsecops-be/internal/domain/audit/dto"
+	auditService "devsecops-be/internal/domain/audit/service"
+	transactionDto "devsecops-be/internal/domain/transaction/dto"
+	transactionService "devsecops-be/internal/domain/transaction/service"
+	"devsecops-be/pkg/errors"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/rbac"
+	"devsecops-be/pkg/response"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPage  = 1
+	defaultLimit = 20
+)
+
+// SystemActor is the actor of changes made outside a user request, such as
+// from the command line. Audit logs record them without a user.
+var SystemActor = uuid.Nil
+
+type AdminService interface {
+	ListUsers(ctx context.Context, filter dto.UserFilter) (*dto.UserListResponse, error)
+	GetUser(ctx context.Context, id uuid.UUID) (*dto.UserData, error)
+	GetUserByEmail(ctx context.Context, email string) (*dto.UserData, error)
+	DisableUser(ctx context.Context, actorID, id uuid.UUID, req dto.DisableUserRequest) (*dto.UserData, error)
+	EnableUser(ctx context.Context, actorID, id uuid.UUID) (*dto.UserData, error)
+	SetRole(ctx context.Context, actorID, id uuid.UUID, role string) (*dto.UserData, error)
+	ListUserAuditLogs(ctx context.Context, id uuid.UUID, filter auditDto.AuditLogFilter) (*auditDto.AuditLogListResponse, error)
+	ListUserTransactions(ctx context.Context, id uuid.UUID, filter transactionDto.TransactionFilter) (*transactionDto.TransactionListResponse, error)
+}
+
+// SessionRevoker signs a user out everywhere. Disabled accounts must lose
+// their existing sessions, not just the ability to log in again.
+type SessionRevoker interface {
+	RevokeAllSessions(ctx context.Context, userID uuid.UUID) error
+}
+
+type adminService struct {
+	adminRepo    repository.AdminRepository
+	sessions     SessionRevoker
+	audit        auditService.AuditService
+	transactions transactionService.TransactionService
+	logger       logger.Logger
+}
+
+func NewAdminService(
+	adminRepo repository.AdminRepository,
+	sessions SessionRevoker,
+	audit auditService.AuditService,
+	transactions transactionService.TransactionService,
+	logger logger.Logger,
+) AdminService {
+	return &adminService{
+		adminRepo:    adminRepo,
+		sessions:     sessions,
+		audit:        audit,
+		transactions: transactions,
+		logger:       logger,
+	}
+}
+
+func (s *adminService) ListUsers(ctx context.Context, filter dto.UserFilter) (*dto.UserListResponse, error) {
+	if filter.Page <= 0 {
+		filter.Page = defaultPage
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
+	}
+
+	users, total, err := s.adminRepo.ListUsers(ctx, repository.UserQuery{
+		Search:   filter.Search,
+		Role:     filter.Role,
+		Disabled: filter.Disabled,
+	}, filter.Limit, (filter.Page-1)*filter.Limit)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list users", err)
+		return nil, err
+	}
+
+	data := make([]dto.UserData, 0, len(users))
+	for i := range users {
+		data = append(data, toUserData(&users[i]))
+	}
+
+	return &dto.UserListResponse{
+		Users:      data,
+		Pagination: response.NewPagination(filter.Page, filter.Limit, total),
+	}, nil
+}
+
+func (s *adminService) GetUser(ctx context.Context, id uuid.UUID) (*dto.UserData, error) {
+	user, err := s.adminRepo.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	data := toUserData(user)
+	return &data, nil
+}
+
+func (s *adminService) GetUserByEmail(ctx context.Context, email string) (*dto.UserData, error) {
+	user, err := s.adminRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	data := toUserData(user)
+	return &data, nil
+}
+
+// DisableUser blocks the account from logging in and revokes its sessions.
+func (s *adminService) DisableUser(ctx context.Context, actorID, id uuid.UUID, req dto.DisableUserRequest) (*dto.UserData, error) {
+	if actorID != SystemActor && actorID == id {
+		return nil, errors.ErrCannotDisableSelf
+	}
+
+	before, err := s.adminRepo.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.adminRepo.SetDisabled(ctx, id, true)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to disable user", err, logger.Fields{
+			"user_id": id,
+		})
+		return nil, err
+	}
+
+	if err := s.sessions.RevokeAllSessions(ctx, id); err != nil {
+		s.logger.Error(ctx, "Failed to revoke sessions of disabled user", err, logger.Fields{
+			"user_id": id,
+		})
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "User disabled", logger.Fields{
+		"user_id":  id,
+		"actor_id": actorID,
+	})
+
+	data := toUserData(user)
+	s.audit.Record(ctx, auditService.Event{
+		UserID:       actorRef(actorID),
+		Action:       auditService.ActionUserDisable,
+		ResourceType: "user",
+		ResourceID:   id.String(),
+		Changes:      auditService.Diff(toUserData(before), data),
+		Metadata:     reasonMetadata(req.Reason),
+	})
+
+	return &data, nil
+}
+
+func (s *adminService) EnableUser(ctx context.Context, actorID, id uuid.UUID) (*dto.UserData, error) {
+	before, err := s.adminRepo.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.adminRepo.SetDisabled(ctx, id, false)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to enable user", err, logger.Fields{
+			"user_id": id,
+		})
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "User enabled", logger.Fields{
+		"user_id":  id,
+		"actor_id": actorID,
+	})
+
+	data := toUserData(user)
+	s.audit.Record(ctx, auditService.Event{
+		UserID:       actorRef(actorID),
+		Action:       auditService.ActionUserEnable,
+		ResourceType: "user",
+		ResourceID:   id.String(),
+		Changes:      auditService.Diff(toUserData(before), data),
+	})
+
+	return &data, nil
+}
+
+// SetRole changes the role of a user. A promotion applies to access tokens
+// issued from the next refresh on; a demotion signs the user out everywhere so
+// tokens carrying the old role stop working right away.
+func (s *adminService) SetRole(ctx context.Context, actorID, id uuid.UUID, role string) (*dto.UserData, error) {
+	switch role {
+	case rbac.RoleUser, rbac.RoleSupport, rbac.RoleAdmin:
+	default:
+		return nil, errors.ErrInvalidRole
+	}
+
+	before, err := s.adminRepo.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.adminRepo.SetRole(ctx, id, role)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to change user role", err, logger.Fields{
+			"user_id": id,
+		})
+		return nil, err
+	}
+
+	if rbac.IsDowngrade(before.Role, role) {
+		if err := s.sessions.RevokeAllSessions(ctx, id); err != nil {
+			s.logger.Error(ctx, "Failed to revoke sessions of demoted user", err, logger.Fields{
+				"user_id": id,
+			})
+			return nil, err
+		}
+	}
+
+	s.logger.Info(ctx, "User role changed", logger.Fields{
+		"user_id":  id,
+		"actor_id": actorID,
+		"role":     role,
+	})
+
+	data := toUserData(user)
+	s.audit.Record(ctx, auditService.Event{
+		UserID:       actorRef(actorID),
+		Action:       auditService.ActionUserRoleChange,
+		ResourceType: "user",
+		ResourceID:   id.String(),
+		Changes:      auditService.Diff(toUserData(before), data),
+	})
+
+	return &data, nil
+}
+
+func (s *adminService) ListUserAuditLogs(ctx context.Context, id uuid.UUID, filter auditDto.AuditLogFilter) (*auditDto.AuditLogListResponse, error) {
+	if _, err := s.adminRepo.GetUser(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.audit.List(ctx, id, filter)
+}
+
+func (s *adminService) ListUserTransactions(ctx context.Context, id uuid.UUID, filter transactionDto.TransactionFilter) (*transactionDto.TransactionListResponse, error) {
+	if _, err := s.adminRepo.GetUser(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.transactions.List(ctx, id, filter)
+}
+
+// actorRef returns the actor recorded in audit logs, none for SystemActor.
+func actorRef(actorID uuid.UUID) *uuid.UUID {
+	if actorID == SystemActor {
+		return nil
+	}
+	return &actorID
+}
+
+func reasonMetadata(reason string) map[string]interface{} {
+	if reason == "" {
+		return nil
+	}
+	return map[string]interface{}{"reason": reason}
+}
+
+func toUserData(user *repository.User) dto.UserData {
+	data := dto.UserData{
+		ID:            user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		Role:          user.Role,
+		EmailVerified: user.VerifiedAt.Valid,
+		MFAEnabled:    user.MFAEnabled,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+	}
+	if user.DisabledAt.Valid {
+		disabledAt := user.DisabledAt.Time
+		data.DisabledAt = &disabledAt
+	}
+	return data
+}
@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"devsecops-be/pkg/response"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AlertFilter struct {
+	Unread bool `query:"unread"`
+	Page   int  `query:"page" validate:"omitempty,min=1"`
+	Limit  int  `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type AlertData struct {
+	ID          uuid.UUID  `json:"id"`
+	Type        string     `json:"type"`
+	Message     string     `json:"message"`
+	Threshold   *int       `json:"threshold"`
+	PeriodStart *string    `json:"period_start"`
+	Spent       *float64   `json:"spent"`
+	LimitAmount *float64   `json:"limit_amount"`
+	Read        bool       `json:"read"`
+	ReadAt      *time.Time `json:"read_at"`
+	TriggeredAt time.Time  `json:"triggered_at"`
+}
+
+type AlertListResponse struct {
+	Alerts      []AlertData         `json:"alerts"`
+	UnreadCount int                 `json:"unread_count"`
+	Pagination  response.Pagination `json:"pagination"`
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"devsecops-be/internal/domain/alert/dto"
+	"devsecops-be/internal/domain/alert/service"
+	"devsecops-be/internal/middleware"
+	"devsecops-be/pkg/errors"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/response"
+	"devsecops-be/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AlertHandler struct {
+	alertService service.AlertService
+	validator    validator.Validator
+	logger       logger.Logger
+}
+
+func NewAlertHandler(
+	alertService service.AlertService,
+	validator validator.Validator,
+	logger logger.Logger,
+) *AlertHandler {
+	return &AlertHandler{
+		alertService: alertService,
+		validator:    validator,
+		logger:       logger,
+	}
+}
+
+func (h *AlertHandler) List(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	var filter dto.AlertFilter
+
+	if err := c.QueryParser(&filter); err != nil {
+		h.logger.Warn(ctx, "Invalid query parameters in list alerts", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid query parameters", nil)
+	}
+
+	if err := h.validator.Validate(filter); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.alertService.List(ctx, userID, filter)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Alerts retrieved successfully", result)
+}
+
+func (h *AlertHandler) MarkRead(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid alert ID", nil)
+	}
+
+	result, err := h.alertService.MarkRead(ctx, userID, id)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Alert marked as read", result)
+}
+
+func (h *AlertHandler) MarkAllRead(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	updated, err := h.alertService.MarkAllRead(ctx, userID)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Alerts marked as read", fiber.Map{
+		"updated": updated,
+	})
+}
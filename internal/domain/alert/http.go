@@ -0,0 +1,44 @@
+package alert
+
+import (
+	"database/sql"
+	"devsecops-be/internal/domain/alert/handler/http"
+	"devsecops-be/internal/domain/alert/repository"
+	"devsecops-be/internal/domain/alert/service"
+	budgetRepository "devsecops-be/internal/domain/budget/repository"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AlertModule struct {
+	Handler *http.AlertHandler
+	Service service.AlertService
+}
+
+func NewAlertModule(db *sql.DB, thresholds []int, appLogger logger.Logger) *AlertModule {
+	// Initialize dependencies
+	alertRepo := repository.NewAlertRepository(db)
+	budgetRepo := budgetRepository.NewBudgetRepository(db)
+	validator := validator.NewValidator()
+
+	// Initialize service
+	alertService := service.NewAlertService(alertRepo, budgetRepo, thresholds, appLogger)
+
+	// Initialize handler
+	alertHandler := http.NewAlertHandler(alertService, validator, appLogger)
+
+	return &AlertModule{
+		Handler: alertHandler,
+		Service: alertService,
+	}
+}
+
+func (m *AlertModule) RegisterRoutes(app *fiber.App, authMiddleware fiber.Handler) {
+	alerts := app.Group("/api/v1/alerts", authMiddleware)
+
+	alerts.Get("/", m.Handler.List)
+	alerts.Post("/read-all", m.Handler.MarkAllRead)
+	alerts.Patch("/:id/read", m.Handler.MarkRead)
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AlertRepository interface {
+	Create(ctx context.Context, alert *Alert) (bool, error)
+	List(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]Alert, int, error)
+	CountUnread(ctx context.Context, userID uuid.UUID) (int, error)
+	MarkRead(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*Alert, error)
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+type Alert struct {
+	ID          uuid.UUID       `db:"id"`
+	UserID      uuid.UUID       `db:"user_id"`
+	Message     string          `db:"message"`
+	Type        string          `db:"type"`
+	Threshold   sql.NullInt32   `db:"threshold"`
+	PeriodStart sql.NullTime    `db:"period_start"`
+	Spent       sql.NullFloat64 `db:"spent"`
+	LimitAmount sql.NullFloat64 `db:"limit_amount"`
+	ReadAt      sql.NullTime    `db:"read_at"`
+	TriggeredAt time.Time       `db:"triggered_at"`
+}
+
+const alertColumns = `id, user_id, COALESCE(message, ''), COALESCE(type, ''), threshold, period_start, spent, limit_amount, read_at, triggered_at`
+
+type alertRepository struct {
+	db *sql.DB
+}
+
+func NewAlertRepository(db *sql.DB) AlertRepository {
+	return &alertRepository{db: db}
+}
+
+// Create inserts the alert unless the same threshold already fired for the
+// period. It reports whether a new row was written.
+func (r *alertRepository) Create(ctx context.Context, alert *Alert) (bool, error) {
+	query := `
+        INSERT INTO alerts (user_id, message, type, threshold, period_start, spent, limit_amount)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        ON CONFLICT (user_id, type, period_start, threshold) DO NOTHING
+    `
+
+	result, err := r.db.ExecContext(ctx, query,
+		alert.UserID, alert.Message, alert.Type, alert.Threshold,
+		alert.PeriodStart, alert.Spent, alert.LimitAmount)
+	if err != nil {
+		return false, errors.WrapDatabaseError(err, "failed to create alert")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.WrapDatabaseError(err, "failed to create alert")
+	}
+
+	return affected > 0, nil
+}
+
+func (r *alertRepository) List(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]Alert, int, error) {
+	var total int
+	countQuery := `SELECT COUNT(*) FROM alerts WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)`
+	if err := r.db.QueryRowContext(ctx, countQuery, userID, unreadOnly).Scan(&total); err != nil {
+		return nil, 0, errors.WrapDatabaseError(err, "failed to count alerts")
+	}
+
+	query := `
+        SELECT ` + alertColumns + `
+        FROM alerts
+        WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+        ORDER BY triggered_at DESC
+        LIMIT $3 OFFSET $4
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, 0, errors.WrapDatabaseError(err, "failed to list alerts")
+	}
+	defer rows.Close()
+
+	alerts := []Alert{}
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			return nil, 0, errors.WrapDatabaseError(err, "failed to scan alert")
+		}
+		alerts = append(alerts, *alert)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.WrapDatabaseError(err, "failed to iterate alerts")
+	}
+
+	return alerts, total, nil
+}
+
+func (r *alertRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM alerts WHERE user_id = $1 AND read_at IS NULL`
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, errors.WrapDatabaseError(err, "failed to count unread alerts")
+	}
+	return count, nil
+}
+
+func (r *alertRepository) MarkRead(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*Alert, error) {
+	query := `
+        UPDATE alerts
+        SET read_at = COALESCE(read_at, NOW())
+        WHERE id = $1 AND user_id = $2
+        RETURNING ` + alertColumns
+
+	alert, err := scanAlert(r.db.QueryRowContext(ctx, query, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrAlertNotFound
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to mark alert as read")
+	}
+
+	return alert, nil
+}
+
+func (r *alertRepository) MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error) {
+	result, err := r.db.ExecContext(ctx, `UPDATE alerts SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, errors.WrapDatabaseError(err, "failed to mark alerts as read")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.WrapDatabaseError(err, "failed to mark alerts as read")
+	}
+
+	return int(affected), nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAlert(row rowScanner) (*Alert, error) {
+	var alert Alert
+	err := row.Scan(
+		&alert.ID, &alert.UserID, &alert.Message, &alert.Type, &alert.Threshold,
+		&alert.PeriodStart, &alert.Spent, &alert.LimitAmount, &alert.ReadAt, &alert.TriggeredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/internal/domain/alert/dto"
+	"devsecops-be/internal/domain/alert/repository"
+	budgetDto "devsecops-be/internal/domain/budget/dto"
+	budgetRepository "devsecops-be/internal/domain/budget/repository"
+	"devsecops-be/pkg/errors"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/response"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPage  = 1
+	defaultLimit = 20
+)
+
+type AlertService interface {
+	List(ctx context.Context, userID uuid.UUID, filter dto.AlertFilter) (*dto.AlertListResponse, error)
+	MarkRead(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*dto.AlertData, error)
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error)
+	CheckSpending(ctx context.Context, userID uuid.UUID, date time.Time) error
+}
+
+type alertService struct {
+	alertRepo  repository.AlertRepository
+	budgetRepo budgetRepository.BudgetRepository
+	thresholds []int
+	logger     logger.Logger
+}
+
+func NewAlertService(
+	alertRepo repository.AlertRepository,
+	budgetRepo budgetRepository.BudgetRepository,
+	thresholds []int,
+	logger logger.Logger,
+) AlertService {
+	sorted := append([]int(nil), thresholds...)
+	sort.Ints(sorted)
+
+	return &alertService{
+		alertRepo:  alertRepo,
+		budgetRepo: budgetRepo,
+		thresholds: sorted,
+		logger:     logger,
+	}
+}
+
+func (s *alertService) List(ctx context.Context, userID uuid.UUID, filter dto.AlertFilter) (*dto.AlertListResponse, error) {
+	if filter.Page <= 0 {
+		filter.Page = defaultPage
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
+	}
+
+	alerts, total, err := s.alertRepo.List(ctx, userID, filter.Unread, filter.Limit, (filter.Page-1)*filter.Limit)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list alerts", err, logger.Fields{
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	unread, err := s.alertRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]dto.AlertData, 0, len(alerts))
+	for i := range alerts {
+		data = append(data, *toAlertData(&alerts[i]))
+	}
+
+	return &dto.AlertListResponse{
+		Alerts:      data,
+		UnreadCount: unread,
+		Pagination:  response.NewPagination(filter.Page, filter.Limit, total),
+	}, nil
+}
+
+func (s *alertService) MarkRead(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*dto.AlertData, error) {
+	alert, err := s.alertRepo.MarkRead(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toAlertData(alert), nil
+}
+
+func (s *alertService) MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error) {
+	return s.alertRepo.MarkAllRead(ctx, userID)
+}
+
+// CheckSpending compares the user's expense totals for the periods containing
+// date against their maximum_spends row and records an alert for the highest
+// threshold crossed in each period. Thresholds already alerted for a period are
+// skipped by the repository.
+func (s *alertService) CheckSpending(ctx context.Context, userID uuid.UUID, date time.Time) error {
+	if len(s.thresholds) == 0 {
+		return nil
+	}
+
+	limits, err := s.budgetRepo.GetLimits(ctx, userID)
+	if err != nil {
+		if err == errors.ErrSpendingLimitNotFound {
+			return nil
+		}
+		return err
+	}
+
+	totals, err := s.budgetRepo.GetExpenseTotals(ctx, userID, date)
+	if err != nil {
+		return err
+	}
+
+	for _, period := range budgetDto.Periods {
+		limit := limits.LimitFor(period)
+		if !limit.Valid || limit.Float64 <= 0 {
+			continue
+		}
+
+		spent := totals.For(period)
+		threshold, crossed := s.highestCrossed(spent / limit.Float64 * 100)
+		if !crossed {
+			continue
+		}
+
+		periodStart, _ := budgetDto.PeriodRange(period, date)
+
+		created, err := s.alertRepo.Create(ctx, &repository.Alert{
+			UserID:      userID,
+			Message:     buildMessage(period, threshold, spent, limit.Float64),
+			Type:        period,
+			Threshold:   sql.NullInt32{Int32: int32(threshold), Valid: true},
+			PeriodStart: sql.NullTime{Time: periodStart, Valid: true},
+			Spent:       sql.NullFloat64{Float64: spent, Valid: true},
+			LimitAmount: limit,
+		})
+		if err != nil {
+			return err
+		}
+
+		if created {
+			s.logger.Info(ctx, "Spending alert triggered", logger.Fields{
+				"user_id":   userID,
+				"period":    period,
+				"threshold": threshold,
+			})
+		}
+	}
+
+	return nil
+}
+
+func (s *alertService) highestCrossed(percentage float64) (int, bool) {
+	for i := len(s.thresholds) - 1; i >= 0; i-- {
+		if percentage >= float64(s.thresholds[i]) {
+			return s.thresholds[i], true
+		}
+	}
+	return 0, false
+}
+
+// ParseThresholds parses a comma separated list of percentages such as "80,100".
+func ParseThresholds(raw string) ([]int, error) {
+	var thresholds []int
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		value, err := strconv.Atoi(part)
+		if err != nil || value <= 0 || value > 1000 {
+			return nil, fmt.Errorf("invalid alert threshold %q", part)
+		}
+		thresholds = append(thresholds, value)
+	}
+	return thresholds, nil
+}
+
+func buildMessage(period string, threshold int, spent, limit float64) string {
+	if threshold >= 100 {
+		return fmt.Sprintf("You have exceeded %d%% of your %s spending limit (%.2f of %.2f)", threshold, period, spent, limit)
+	}
+	return fmt.Sprintf("You have used %d%% of your %s spending limit (%.2f of %.2f)", threshold, period, spent, limit)
+}
+
+func toAlertData(alert *repository.Alert) *dto.AlertData {
+	data := &dto.AlertData{
+		ID:          alert.ID,
+		Type:        alert.Type,
+		Message:     alert.Message,
+		Read:        alert.ReadAt.Valid,
+		TriggeredAt: alert.TriggeredAt,
+	}
+	if alert.Threshold.Valid {
+		threshold := int(alert.Threshold.Int32)
+		data.Threshold = &threshold
+	}
+	if alert.PeriodStart.Valid {
+		periodStart := alert.PeriodStart.Time.Format(budgetDto.DateLayout)
+		data.PeriodStart = &periodStart
+	}
+	if alert.Spent.Valid {
+		spent := alert.Spent.Float64
+		data.Spent = &spent
+	}
+	if alert.LimitAmount.Valid {
+		limitAmount := alert.LimitAmount.Float64
+		data.LimitAmount = &limitAmount
+	}
+	if alert.ReadAt.Valid {
+		readAt := alert.ReadAt.Time
+		data.ReadAt = &readAt
+	}
+	return data
+}
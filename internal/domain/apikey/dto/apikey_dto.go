@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// API key scopes. Read-only keys may only make safe (GET, HEAD, OPTIONS) requests.
+const (
+	ScopeRead      = "read"
+	ScopeReadWrite = "read_write"
+)
+
+// Principal is the owner and scope of a valid API key.
+type Principal struct {
+	KeyID  uuid.UUID
+	UserID uuid.UUID
+	Role   string
+	Scope  string
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string `json:"name" validate:"required,min=1,max=100"`
+	Scope         string `json:"scope" validate:"required,oneof=read read_write"`
+	ExpiresInDays *int   `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+type APIKeyData struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scope      string     `json:"scope"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP *string    `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyResponse carries the plain key. It is only ever returned here;
+// afterwards only the prefix is known.
+type CreateAPIKeyResponse struct {
+	Key    string     `json:"key"`
+	APIKey APIKeyData `json:"api_key"`
+}
+
+type APIKeyListResponse struct {
+	APIKeys []APIKeyData `json:"api_keys"`
+}
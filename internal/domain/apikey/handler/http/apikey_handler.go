@@ -0,0 +1,100 @@
+package http
+
+import (
+	"devsecops-be/internal/domain/apikey/dto"
+	"devsecops-be/internal/domain/apikey/service"
+	"devsecops-be/internal/middleware"
+	"devsecops-be/pkg/errors"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/response"
+	"devsecops-be/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type APIKeyHandler struct {
+	apiKeyService service.APIKeyService
+	validator     validator.Validator
+	logger        logger.Logger
+}
+
+func NewAPIKeyHandler(
+	apiKeyService service.APIKeyService,
+	validator validator.Validator,
+	logger logger.Logger,
+) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		validator:     validator,
+		logger:        logger,
+	}
+}
+
+func (h *APIKeyHandler) Create(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	var req dto.CreateAPIKeyRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in create API key", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in create API key", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.apiKeyService.Create(ctx, userID, req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Created(c, "API key created successfully, store it now as it will not be shown again", result)
+}
+
+func (h *APIKeyHandler) List(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	result, err := h.apiKeyService.List(ctx, userID)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "API keys retrieved successfully", result)
+}
+
+func (h *APIKeyHandler) Revoke(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid API key ID", nil)
+	}
+
+	if err := h.apiKeyService.Revoke(ctx, userID, id); err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "API key revoked successfully", nil)
+}
@@ -0,0 +1,45 @@
+package apikey
+
+import (
+	"database/sql"
+	"devsecops-be/internal/domain/apikey/handler/http"
+	"devsecops-be/internal/domain/apikey/repository"
+	"devsecops-be/internal/domain/apikey/service"
+	auditService "devsecops-be/internal/domain/audit/service"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type APIKeyModule struct {
+	Handler *http.APIKeyHandler
+	Service service.APIKeyService
+}
+
+func NewAPIKeyModule(db *sql.DB, audit auditService.AuditService, logger logger.Logger) *APIKeyModule {
+	// Initialize dependencies
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	validator := validator.NewValidator()
+
+	// Initialize service
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, audit, logger)
+
+	// Initialize handler
+	apiKeyHandler := http.NewAPIKeyHandler(apiKeyService, validator, logger)
+
+	return &APIKeyModule{
+		Handler: apiKeyHandler,
+		Service: apiKeyService,
+	}
+}
+
+// RegisterRoutes mounts the key management routes. authMiddleware must not
+// accept API keys, so a key can never be used to mint or revoke keys.
+func (m *APIKeyModule) RegisterRoutes(app *fiber.App, authMiddleware fiber.Handler) {
+	apiKeys := app.Group("/api/v1/api-keys", authMiddleware)
+
+	apiKeys.Post("/", m.Handler.Create)
+	apiKeys.Get("/", m.Handler.List)
+	apiKeys.Delete("/:id", m.Handler.Revoke)
+}
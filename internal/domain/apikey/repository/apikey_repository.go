@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *APIKey) (*APIKey, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (*APIKeyOwner, error)
+	Revoke(ctx context.Context, userID, id uuid.UUID) (*APIKey, error)
+	Touch(ctx context.Context, id uuid.UUID, ip string, interval time.Duration) error
+}
+
+type APIKey struct {
+	ID         uuid.UUID      `db:"id"`
+	UserID     uuid.UUID      `db:"user_id"`
+	Name       string         `db:"name"`
+	Prefix     string         `db:"prefix"`
+	KeyHash    string         `db:"key_hash"`
+	Scope      string         `db:"scope"`
+	ExpiresAt  sql.NullTime   `db:"expires_at"`
+	LastUsedAt sql.NullTime   `db:"last_used_at"`
+	LastUsedIP sql.NullString `db:"last_used_ip"`
+	RevokedAt  sql.NullTime   `db:"revoked_at"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
+
+// APIKeyOwner is a key together with the owner fields needed to authenticate it.
+type APIKeyOwner struct {
+	APIKey
+	Role           string       `db:"role"`
+	UserDisabledAt sql.NullTime `db:"disabled_at"`
+}
+
+const apiKeyColumns = `k.id, k.user_id, k.name, k.prefix, k.key_hash, k.scope, k.expires_at, k.last_used_at, k.last_used_ip, k.revoked_at, k.created_at`
+
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *APIKey) (*APIKey, error) {
+	query := `
+        INSERT INTO api_keys AS k (user_id, name, prefix, key_hash, scope, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING ` + apiKeyColumns
+
+	created, err := scanAPIKey(r.db.QueryRowContext(ctx, query,
+		key.UserID, key.Name, key.Prefix, key.KeyHash, key.Scope, key.ExpiresAt))
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "failed to create API key")
+	}
+
+	return created, nil
+}
+
+func (r *apiKeyRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]APIKey, error) {
+	query := `
+        SELECT ` + apiKeyColumns + `
+        FROM api_keys k
+        WHERE k.user_id = $1
+        ORDER BY k.created_at DESC
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "failed to list API keys")
+	}
+	defer rows.Close()
+
+	var keys []APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, errors.WrapDatabaseError(err, "failed to scan API key")
+		}
+		keys = append(keys, *key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapDatabaseError(err, "failed to list API keys")
+	}
+
+	return keys, nil
+}
+
+func (r *apiKeyRepository) GetByHash(ctx context.Context, keyHash string) (*APIKeyOwner, error) {
+	query := `
+        SELECT ` + apiKeyColumns + `, u.role, u.disabled_at
+        FROM api_keys k
+        JOIN users u ON u.id = k.user_id
+        WHERE k.key_hash = $1
+    `
+
+	var key APIKeyOwner
+	err := r.db.QueryRowContext(ctx, query, keyHash).Scan(
+		&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.KeyHash, &key.Scope, &key.ExpiresAt,
+		&key.LastUsedAt, &key.LastUsedIP, &key.RevokedAt, &key.CreatedAt, &key.Role, &key.UserDisabledAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrInvalidToken
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to get API key")
+	}
+
+	return &key, nil
+}
+
+// Revoke revokes one of the user's keys. Revoking a revoked key is a no-op
+// that returns the key unchanged.
+func (r *apiKeyRepository) Revoke(ctx context.Context, userID, id uuid.UUID) (*APIKey, error) {
+	query := `
+        UPDATE api_keys k
+        SET revoked_at = COALESCE(k.revoked_at, NOW())
+        WHERE k.id = $1 AND k.user_id = $2
+        RETURNING ` + apiKeyColumns
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrAPIKeyNotFound
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to revoke API key")
+	}
+
+	return key, nil
+}
+
+// Touch records that the key was used. Writes are skipped while the previous
+// one is more recent than interval so busy keys do not update on every request.
+func (r *apiKeyRepository) Touch(ctx context.Context, id uuid.UUID, ip string, interval time.Duration) error {
+	query := `
+        UPDATE api_keys
+        SET last_used_at = $2, last_used_ip = NULLIF($3, '')
+        WHERE id = $1 AND (last_used_at IS NULL OR last_used_at <= $4 OR last_used_ip IS DISTINCT FROM NULLIF($3, ''))
+    `
+
+	now := time.Now().UTC()
+	if _, err := r.db.ExecContext(ctx, query, id, now, ip, now.Add(-interval)); err != nil {
+		return errors.WrapDatabaseError(err, "failed to record API key usage")
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(row rowScanner) (*APIKey, error) {
+	var key APIKey
+	err := row.Scan(
+		&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.KeyHash, &key.Scope, &key.ExpiresAt,
+		&key.LastUsedAt, &key.LastUsedIP, &key.RevokedAt, &key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
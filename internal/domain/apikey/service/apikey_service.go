@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"devsecops-be/internal/domain/apikey/dto"
+	"devsecops-be/internal/domain/apikey/repository"
+	auditService "devsecops-be/internal/domain/audit/service"
+	"devsecops-be/pkg/errors"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/requestinfo"
+	"devsecops-be/pkg/token"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// keyPrefix marks our keys so secret scanners and users can recognize them.
+	keyPrefix = "dsk_"
+	// touchInterval limits how often last-used tracking writes to the database.
+	touchInterval = time.Minute
+)
+
+type APIKeyService interface {
+	Create(ctx context.Context, userID uuid.UUID, req dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error)
+	List(ctx context.Context, userID uuid.UUID) (*dto.APIKeyListResponse, error)
+	Revoke(ctx context.Context, userID, id uuid.UUID) error
+	AuthenticateAPIKey(ctx context.Context, key string) (*dto.Principal, error)
+}
+
+type apiKeyService struct {
+	apiKeyRepo repository.APIKeyRepository
+	audit      auditService.AuditService
+	logger     logger.Logger
+}
+
+func NewAPIKeyService(
+	apiKeyRepo repository.APIKeyRepository,
+	audit auditService.AuditService,
+	logger logger.Logger,
+) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo: apiKeyRepo,
+		audit:      audit,
+		logger:     logger,
+	}
+}
+
+// Create generates a key of the form dsk_<id>_<secret>. The dsk_<id> part is
+// stored as the prefix for display; the full key is only stored hashed.
+func (s *apiKeyService) Create(ctx context.Context, userID uuid.UUID, req dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error) {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return nil, errors.WrapInternalError(err, "failed to generate API key")
+	}
+	secret, err := token.Generate(32)
+	if err != nil {
+		return nil, errors.WrapInternalError(err, "failed to generate API key")
+	}
+
+	prefix := keyPrefix + hex.EncodeToString(id)
+	plainKey := prefix + "_" + secret
+
+	var expiresAt sql.NullTime
+	if req.ExpiresInDays != nil {
+		expiresAt = sql.NullTime{Time: time.Now().UTC().AddDate(0, 0, *req.ExpiresInDays), Valid: true}
+	}
+
+	key, err := s.apiKeyRepo.Create(ctx, &repository.APIKey{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   token.Hash(plainKey),
+		Scope:     req.Scope,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		s.logger.Error(ctx, "Failed to create API key", err, logger.Fields{
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "API key created", logger.Fields{
+		"user_id":    userID,
+		"api_key_id": key.ID,
+		"scope":      key.Scope,
+	})
+
+	data := toAPIKeyData(key)
+	s.audit.Record(ctx, auditService.Event{
+		UserID:       &userID,
+		Action:       auditService.ActionAPIKeyCreate,
+		ResourceType: "api_key",
+		ResourceID:   key.ID.String(),
+		Changes:      auditService.Diff(nil, data),
+	})
+
+	return &dto.CreateAPIKeyResponse{
+		Key:    plainKey,
+		APIKey: data,
+	}, nil
+}
+
+func (s *apiKeyService) List(ctx context.Context, userID uuid.UUID) (*dto.APIKeyListResponse, error) {
+	keys, err := s.apiKeyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list API keys", err, logger.Fields{
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	data := make([]dto.APIKeyData, 0, len(keys))
+	for i := range keys {
+		data = append(data, toAPIKeyData(&keys[i]))
+	}
+
+	return &dto.APIKeyListResponse{APIKeys: data}, nil
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, userID, id uuid.UUID) error {
+	key, err := s.apiKeyRepo.Revoke(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "API key revoked", logger.Fields{
+		"user_id":    userID,
+		"api_key_id": key.ID,
+	})
+
+	s.audit.Record(ctx, auditService.Event{
+		UserID:       &userID,
+		Action:       auditService.ActionAPIKeyRevoke,
+		ResourceType: "api_key",
+		ResourceID:   key.ID.String(),
+	})
+
+	return nil
+}
+
+// AuthenticateAPIKey resolves a key presented in the Authorization header and
+// records its use.
+func (s *apiKeyService) AuthenticateAPIKey(ctx context.Context, key string) (*dto.Principal, error) {
+	stored, err := s.apiKeyRepo.GetByHash(ctx, token.Hash(key))
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.RevokedAt.Valid || (stored.ExpiresAt.Valid && time.Now().UTC().After(stored.ExpiresAt.Time)) {
+		return nil, errors.ErrInvalidToken
+	}
+	if stored.UserDisabledAt.Valid {
+		return nil, errors.ErrAccountDisabled
+	}
+
+	if err := s.apiKeyRepo.Touch(ctx, stored.ID, requestinfo.FromContext(ctx).IP, touchInterval); err != nil {
+		s.logger.Error(ctx, "Failed to record API key usage", err, logger.Fields{
+			"api_key_id": stored.ID,
+		})
+	}
+
+	return &dto.Principal{
+		KeyID:  stored.ID,
+		UserID: stored.UserID,
+		Role:   stored.Role,
+		Scope:  stored.Scope,
+	}, nil
+}
+
+func toAPIKeyData(key *repository.APIKey) dto.APIKeyData {
+	return dto.APIKeyData{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scope:      key.Scope,
+		ExpiresAt:  fromNullTime(key.ExpiresAt),
+		LastUsedAt: fromNullTime(key.LastUsedAt),
+		LastUsedIP: fromNullString(key.LastUsedIP),
+		RevokedAt:  fromNullTime(key.RevokedAt),
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+func fromNullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	value := t.Time
+	return &value
+}
+
+func fromNullString(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	value := s.String
+	return &value
+}
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"devsecops-be/pkg/response"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditLogFilter struct {
+	Action string `query:"action" validate:"omitempty,max=100"`
+	Page   int    `query:"page" validate:"omitempty,min=1"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type AuditLogData struct {
+	ID           uuid.UUID       `json:"id"`
+	UserID       *uuid.UUID      `json:"user_id"`
+	Action       string          `json:"action"`
+	ResourceType *string         `json:"resource_type"`
+	ResourceID   *string         `json:"resource_id"`
+	IPAddress    *string         `json:"ip_address"`
+	UserAgent    *string         `json:"user_agent"`
+	RequestID    *string         `json:"request_id"`
+	Changes      json.RawMessage `json:"changes"`
+	Metadata     json.RawMessage `json:"metadata"`
+	Hash         *string         `json:"hash"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+type AuditLogListResponse struct {
+	AuditLogs  []AuditLogData      `json:"audit_logs"`
+	Pagination response.Pagination `json:"pagination"`
+}
+
+// ChainVerification is the outcome of checking the audit log hash chain.
+type ChainVerification struct {
+	Rows   int64        `json:"rows"`
+	Breaks []ChainBreak `json:"breaks"`
+}
+
+// ChainBreak is a row whose hash does not match its content or whose link
+// does not match the row before it.
+type ChainBreak struct {
+	Seq    int64  `json:"seq"`
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,60 @@
+package http
+
+import (
+	"devsecops-be/internal/domain/audit/dto"
+	"devsecops-be/internal/domain/audit/service"
+	"devsecops-be/internal/middleware"
+	"devsecops-be/pkg/errors"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/response"
+	"devsecops-be/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditHandler struct {
+	auditService service.AuditService
+	validator    validator.Validator
+	logger       logger.Logger
+}
+
+func NewAuditHandler(
+	auditService service.AuditService,
+	validator validator.Validator,
+	logger logger.Logger,
+) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		validator:    validator,
+		logger:       logger,
+	}
+}
+
+func (h *AuditHandler) List(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	var filter dto.AuditLogFilter
+
+	if err := c.QueryParser(&filter); err != nil {
+		h.logger.Warn(ctx, "Invalid query parameters in list audit logs", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid query parameters", nil)
+	}
+
+	if err := h.validator.Validate(filter); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.auditService.List(ctx, userID, filter)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Audit logs retrieved successfully", result)
+}
@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"database/sql"
+	"devsecops-be/internal/domain/audit/handler/http"
+	"devsecops-be/internal/domain/audit/repository"
+	"devsecops-be/internal/domain/audit/service"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditModule struct {
+	Handler *http.AuditHandler
+	Service service.AuditService
+}
+
+func NewAuditModule(db *sql.DB, logger logger.Logger) *AuditModule {
+	// Initialize dependencies
+	auditRepo := repository.NewAuditRepository(db)
+	validator := validator.NewValidator()
+
+	// Initialize service
+	auditService := service.NewAuditService(auditRepo, logger)
+
+	// Initialize handler
+	auditHandler := http.NewAuditHandler(auditService, validator, logger)
+
+	return &AuditModule{
+		Handler: auditHandler,
+		Service: auditService,
+	}
+}
+
+func (m *AuditModule) RegisterRoutes(app *fiber.App, authMiddleware fiber.Handler) {
+	auditLogs := app.Group("/api/v1/audit-logs", authMiddleware)
+
+	auditLogs.Get("/", m.Handler.List)
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditRepository interface {
+	Create(ctx context.Context, log *AuditLog) error
+	ListByUser(ctx context.Context, userID uuid.UUID, action string, limit, offset int) ([]AuditLog, int, error)
+	WalkChain(ctx context.Context, visit func(link ChainLink) error) error
+}
+
+// AuditLog is an append-only row. prev_hash and hash are filled in by the
+// audit_logs_chain trigger, never by the application.
+type AuditLog struct {
+	ID           uuid.UUID      `db:"id"`
+	UserID       uuid.NullUUID  `db:"user_id"`
+	Action       string         `db:"action"`
+	ResourceType sql.NullString `db:"resource_type"`
+	ResourceID   sql.NullString `db:"resource_id"`
+	IPAddress    sql.NullString `db:"ip_address"`
+	UserAgent    sql.NullString `db:"user_agent"`
+	RequestID    sql.NullString `db:"request_id"`
+	Changes      []byte         `db:"changes"`
+	Metadata     []byte         `db:"metadata"`
+	Hash         sql.NullString `db:"hash"`
+	CreatedAt    time.Time      `db:"created_at"`
+}
+
+// ChainLink is one row of the hash chain. ExpectedHash is recomputed by the
+// database from the row's content and PreviousHash is the hash stored on the
+// row before it.
+type ChainLink struct {
+	Seq          int64
+	PrevHash     sql.NullString
+	Hash         sql.NullString
+	ExpectedHash string
+	PreviousHash sql.NullString
+}
+
+const auditLogColumns = `id, user_id, COALESCE(action, ''), resource_type, resource_id, ip_address, user_agent, request_id, changes, metadata, hash, created_at`
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(ctx context.Context, log *AuditLog) error {
+	query := `
+        INSERT INTO audit_logs (user_id, action, resource_type, resource_id, ip_address, user_agent, request_id, changes, metadata)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    `
+
+	_, err := r.db.ExecContext(ctx, query,
+		log.UserID, log.Action, log.ResourceType, log.ResourceID, log.IPAddress,
+		log.UserAgent, log.RequestID, nullJSON(log.Changes), nullJSON(log.Metadata))
+	if err != nil {
+		return errors.WrapDatabaseError(err, "failed to write audit log")
+	}
+
+	return nil
+}
+
+func (r *auditRepository) ListByUser(ctx context.Context, userID uuid.UUID, action string, limit, offset int) ([]AuditLog, int, error) {
+	var total int
+	countQuery := `SELECT COUNT(*) FROM audit_logs WHERE user_id = $1 AND ($2 = '' OR action = $2)`
+	if err := r.db.QueryRowContext(ctx, countQuery, userID, action).Scan(&total); err != nil {
+		return nil, 0, errors.WrapDatabaseError(err, "failed to count audit logs")
+	}
+
+	query := `
+        SELECT ` + auditLogColumns + `
+        FROM audit_logs
+        WHERE user_id = $1 AND ($2 = '' OR action = $2)
+        ORDER BY created_at DESC, seq DESC
+        LIMIT $3 OFFSET $4
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, userID, action, limit, offset)
+	if err != nil {
+		return nil, 0, errors.WrapDatabaseError(err, "failed to list audit logs")
+	}
+	defer rows.Close()
+
+	logs := []AuditLog{}
+	for rows.Next() {
+		var log AuditLog
+		err := rows.Scan(
+			&log.ID, &log.UserID, &log.Action, &log.ResourceType, &log.ResourceID,
+			&log.IPAddress, &log.UserAgent, &log.RequestID, &log.Changes, &log.Metadata,
+			&log.Hash, &log.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, errors.WrapDatabaseError(err, "failed to scan audit log")
+		}
+		logs = append(logs, log)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.WrapDatabaseError(err, "failed to iterate audit logs")
+	}
+
+	return logs, total, nil
+}
+
+// WalkChain calls visit for every row in chain order without loading the
+// whole table.
+func (r *auditRepository) WalkChain(ctx context.Context, visit func(link ChainLink) error) error {
+	query := `
+        SELECT seq, prev_hash, hash,
+            audit_log_hash(prev_hash, user_id, action, resource_type, resource_id,
+                ip_address, user_agent, request_id, changes, metadata, created_at),
+            LAG(hash) OVER (ORDER BY seq)
+        FROM audit_logs
+        ORDER BY seq
+    `
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return errors.WrapDatabaseError(err, "failed to read audit chain")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var link ChainLink
+		if err := rows.Scan(&link.Seq, &link.PrevHash, &link.Hash, &link.ExpectedHash, &link.PreviousHash); err != nil {
+			return errors.WrapDatabaseError(err, "failed to scan audit chain")
+		}
+		if err := visit(link); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errors.WrapDatabaseError(err, "failed to iterate audit chain")
+	}
+
+	return nil
+}
+
+func nullJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
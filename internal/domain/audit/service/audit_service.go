@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/internal/domain/audit/dto"
+	"devsecops-be/internal/domain/audit/repository"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/requestinfo"
+	"devsecops-be/pkg/response"
+	"encoding/json"
+	"reflect"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPage  = 1
+	defaultLimit = 20
+	// maxUserAgentLength bounds the user_agent TEXT column; headers can be
+	// as large as the server accepts.
+	maxUserAgentLength = 512
+)
+
+// Audit actions recorded in audit_logs.action.
+const (
+	ActionLoginSuccess         = "auth.login.success"
+	ActionLoginFailure         = "auth.login.failure"
+	ActionLoginLockout         = "auth.login.lockout"
+	ActionRegister             = "auth.register"
+	ActionPasswordChange       = "auth.password.change"
+	ActionPasswordResetRequest = "auth.password.reset.request"
+	ActionPasswordReset        = "auth.password.reset"
+	ActionEmailVerify          = "auth.email.verify"
+	ActionEmailChange          = "auth.email.change"
+	ActionMFAEnable            = "auth.mfa.enable"
+	ActionMFADisable           = "auth.mfa.disable"
+	ActionMFARecoveryCodes     = "auth.mfa.recovery_codes.regenerate"
+	ActionTokenRefresh         = "auth.token.refresh"
+	ActionTokenReuse           = "auth.token.reuse"
+	ActionLogout               = "auth.logout"
+	ActionLogoutAll            = "auth.logout.all"
+	ActionSessionRevoke        = "auth.session.revoke"
+	ActionIdentityLink         = "auth.identity.link"
+	ActionIdentityUnlink       = "auth.identity.unlink"
+	ActionProfileUpdate        = "user.profile.update"
+	ActionAPIKeyCreate         = "api_key.create"
+	ActionAPIKeyRevoke         = "api_key.revoke"
+	ActionUserDisable          = "admin.user.disable"
+	ActionUserEnable           = "admin.user.enable"
+	ActionUserRoleChange       = "admin.user.role_change"
+	ActionTransactionCreate    = "transaction.create"
+	ActionTransactionUpdate    = "transaction.update"
+	ActionTransactionDelete    = "transaction.delete"
+)
+
+// Event is a single security-relevant action. Request metadata (IP, user
+// agent, request ID) is taken from the context passed to Record.
+type Event struct {
+	UserID       *uuid.UUID
+	Action       string
+	ResourceType string
+	ResourceID   string
+	Changes      map[string]FieldChange
+	Metadata     map[string]interface{}
+}
+
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+type AuditService interface {
+	Record(ctx context.Context, event Event)
+	List(ctx context.Context, userID uuid.UUID, filter dto.AuditLogFilter) (*dto.AuditLogListResponse, error)
+	Verify(ctx context.Context) (*dto.ChainVerification, error)
+}
+
+type auditService struct {
+	auditRepo repository.AuditRepository
+	logger    logger.Logger
+}
+
+func NewAuditService(
+	auditRepo repository.AuditRepository,
+	logger logger.Logger,
+) AuditService {
+	return &auditService{
+		auditRepo: auditRepo,
+		logger:    logger,
+	}
+}
+
+// Record writes the event to audit_logs. Failures are logged and swallowed so
+// that auditing never changes the outcome of the audited operation.
+func (s *auditService) Record(ctx context.Context, event Event) {
+	info := requestinfo.FromContext(ctx)
+
+	// Values are cut to their column size: an oversized value sent by a
+	// client must not make the insert fail and leave the event unrecorded
+	entry := &repository.AuditLog{
+		Action:       truncate(event.Action, 100),
+		ResourceType: toNullString(truncate(event.ResourceType, 50)),
+		ResourceID:   toNullString(truncate(event.ResourceID, 100)),
+		IPAddress:    toNullString(truncate(info.IP, 45)),
+		UserAgent:    toNullString(truncate(info.UserAgent, maxUserAgentLength)),
+		RequestID:    toNullString(truncate(info.RequestID, 100)),
+	}
+	if event.UserID != nil {
+		entry.UserID = uuid.NullUUID{UUID: *event.UserID, Valid: true}
+	}
+
+	var err error
+	if len(event.Changes) > 0 {
+		if entry.Changes, err = json.Marshal(event.Changes); err != nil {
+			s.logger.Error(ctx, "Failed to encode audit changes", err, logger.Fields{
+				"action": event.Action,
+			})
+		}
+	}
+	if len(event.Metadata) > 0 {
+		if entry.Metadata, err = json.Marshal(event.Metadata); err != nil {
+			s.logger.Error(ctx, "Failed to encode audit metadata", err, logger.Fields{
+				"action": event.Action,
+			})
+		}
+	}
+
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		s.logger.Error(ctx, "Failed to write audit log", err, logger.Fields{
+			"action":     event.Action,
+			"user_id":    event.UserID,
+			"request_id": info.RequestID,
+		})
+	}
+}
+
+func (s *auditService) List(ctx context.Context, userID uuid.UUID, filter dto.AuditLogFilter) (*dto.AuditLogListResponse, error) {
+	if filter.Page <= 0 {
+		filter.Page = defaultPage
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
+	}
+
+	logs, total, err := s.auditRepo.ListByUser(ctx, userID, filter.Action, filter.Limit, (filter.Page-1)*filter.Limit)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list audit logs", err, logger.Fields{
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	data := make([]dto.AuditLogData, 0, len(logs))
+	for i := range logs {
+		data = append(data, toAuditLogData(&logs[i]))
+	}
+
+	return &dto.AuditLogListResponse{
+		AuditLogs:  data,
+		Pagination: response.NewPagination(filter.Page, filter.Limit, total),
+	}, nil
+}
+
+// Verify recomputes every row's hash and checks that each row links to the
+// one before it. An edited row fails its own hash, a removed row breaks the
+// link of the row after it. Removing the newest rows cannot be detected from
+// the table alone, compare the result with a previously recorded row count.
+func (s *auditService) Verify(ctx context.Context) (*dto.ChainVerification, error) {
+	result := &dto.ChainVerification{Breaks: []dto.ChainBreak{}}
+
+	err := s.auditRepo.WalkChain(ctx, func(link repository.ChainLink) error {
+		result.Rows++
+		switch {
+		case !link.Hash.Valid:
+			result.Breaks = append(result.Breaks, dto.ChainBreak{Seq: link.Seq, Reason: "hash is missing"})
+		case link.Hash.String != link.ExpectedHash:
+			result.Breaks = append(result.Breaks, dto.ChainBreak{Seq: link.Seq, Reason: "hash does not match the row's content"})
+		}
+		if link.PrevHash != link.PreviousHash {
+			result.Breaks = append(result.Breaks, dto.ChainBreak{Seq: link.Seq, Reason: "prev_hash does not match the previous row"})
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(ctx, "Failed to verify audit chain", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Diff compares the JSON representations of before and after and returns the
+// fields whose values differ. Either side may be nil for creates and deletes.
+func Diff(before, after interface{}) map[string]FieldChange {
+	oldFields := toFieldMap(before)
+	newFields := toFieldMap(after)
+
+	changes := map[string]FieldChange{}
+	for key, oldValue := range oldFields {
+		newValue, ok := newFields[key]
+		if !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes[key] = FieldChange{Old: oldValue, New: newValue}
+		}
+	}
+	for key, newValue := range newFields {
+		if _, ok := oldFields[key]; !ok {
+			changes[key] = FieldChange{New: newValue}
+		}
+	}
+
+	return changes
+}
+
+func toFieldMap(value interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil()) {
+		return fields
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fields
+	}
+	_ = json.Unmarshal(data, &fields)
+
+	return fields
+}
+
+func toAuditLogData(log *repository.AuditLog) dto.AuditLogData {
+	data := dto.AuditLogData{
+		ID:           log.ID,
+		Action:       log.Action,
+		ResourceType: fromNullString(log.ResourceType),
+		ResourceID:   fromNullString(log.ResourceID),
+		IPAddress:    fromNullString(log.IPAddress),
+		UserAgent:    fromNullString(log.UserAgent),
+		RequestID:    fromNullString(log.RequestID),
+		Changes:      log.Changes,
+		Metadata:     log.Metadata,
+		Hash:         fromNullString(log.Hash),
+		CreatedAt:    log.CreatedAt,
+	}
+	if log.UserID.Valid {
+		userID := log.UserID.UUID
+		data.UserID = &userID
+	}
+	return data
+}
+
+// truncate cuts s to at most n runes.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+func toNullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func fromNullString(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	value := s.String
+	return &value
+}
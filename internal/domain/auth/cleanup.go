@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/internal/domain/auth/repository"
+	"devsecops-be/pkg/cleanup"
+	"devsecops-be/pkg/logger"
+	"time"
+)
+
+// StartSessionCleanup deletes sessions whose refresh tokens have all expired
+// every interval until ctx is cancelled.
+func StartSessionCleanup(ctx context.Context, db *sql.DB, interval time.Duration, log logger.Logger) {
+	sessionRepo := repository.NewSessionRepository(db)
+
+	cleanup.Every(ctx, interval, "expired sessions", sessionRepo.DeleteExpired, log)
+}
@@ -7,25 +7,135 @@ import (
 
 type LoginRequest struct {
     Email    string `json:"email" validate:"required,email" example:"user@example.com"`
-    Password string `json:"password" validate:"required,min=6,max=100" example:"password123"`
+    Password string `json:"password" validate:"required,max=256" example:"password123"`
 }
 
 type RegisterRequest struct {
     Name     string `json:"name" validate:"required,min=2,max=100" example:"John Doe"`
     Email    string `json:"email" validate:"required,email,max=255" example:"user@example.com"`
-    Password string `json:"password" validate:"required,min=6,max=100" example:"password123"`
+    Password string `json:"password" validate:"required,max=256" example:"correct-horse-battery-staple"`
+}
+
+type RefreshRequest struct {
+    RefreshToken string `json:"refresh_token" validate:"required,max=255"`
+}
+
+type LogoutRequest struct {
+    RefreshToken string `json:"refresh_token" validate:"omitempty,max=255"`
+}
+
+// UpdateProfileRequest changes the name and/or email. An email change needs
+// the current password and only takes effect once the new address is verified.
+type UpdateProfileRequest struct {
+    Name            *string `json:"name" validate:"omitempty,min=2,max=100" example:"John Doe"`
+    Email           *string `json:"email" validate:"omitempty,email,max=255" example:"user@example.com"`
+    CurrentPassword string  `json:"current_password" validate:"required_with=Email,max=256"`
+}
+
+type ChangePasswordRequest struct {
+    CurrentPassword string `json:"current_password" validate:"required,max=256"`
+    NewPassword     string `json:"new_password" validate:"required,max=256,nefield=CurrentPassword"`
+}
+
+type ForgotPasswordRequest struct {
+    Email string `json:"email" validate:"required,email,max=255" example:"user@example.com"`
+}
+
+type ResetPasswordRequest struct {
+    Token       string `json:"token" validate:"required,max=255"`
+    NewPassword string `json:"new_password" validate:"required,max=256"`
+}
+
+type VerifyEmailRequest struct {
+    Token string `json:"token" validate:"required,max=2048"`
+}
+
+type MFALoginRequest struct {
+    MFAToken string `json:"mfa_token" validate:"required,max=4096"`
+    Code     string `json:"code" validate:"required,max=32" example:"123456"`
+}
+
+type MFACodeRequest struct {
+    Code string `json:"code" validate:"required,max=32" example:"123456"`
+}
+
+type MFADisableRequest struct {
+    Password string `json:"password" validate:"required,max=256"`
+    Code     string `json:"code" validate:"required,max=32" example:"123456"`
+}
+
+// MFAChallengeResponse is returned by login instead of tokens when the account
+// has two-factor authentication enabled.
+type MFAChallengeResponse struct {
+    MFARequired bool      `json:"mfa_required"`
+    MFAToken    string    `json:"mfa_token"`
+    ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type MFASetupResponse struct {
+    Secret          string `json:"secret"`
+    ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFARecoveryCodesResponse struct {
+    RecoveryCodes []string `json:"recovery_codes"`
 }
 
 type AuthResponse struct {
-    Token     string    `json:"token"`
-    User      UserData  `json:"user"`
-    ExpiresAt time.Time `json:"expires_at"`
+    Token            string    `json:"token"`
+    User             UserData  `json:"user"`
+    ExpiresAt        time.Time `json:"expires_at"`
+    RefreshToken     string    `json:"refresh_token"`
+    RefreshExpiresAt time.Time `json:"refresh_expires_at"`
 }
 
 
 type UserData struct {
-    ID        uuid.UUID `db:"id"`
-    Name      string    `json:"name"`
-    Email     string    `json:"email"`
-    CreatedAt time.Time `json:"created_at"`
-}
\ No newline at end of file
+    ID            uuid.UUID `db:"id"`
+    Name          string    `json:"name"`
+    Email         string    `json:"email"`
+    Role          string    `json:"role"`
+    EmailVerified bool      `json:"email_verified"`
+    // PendingEmail is the new address of an email change awaiting verification.
+    PendingEmail  *string   `json:"pending_email,omitempty"`
+    CreatedAt     time.Time `json:"created_at"`
+}
+type SessionData struct {
+    ID         uuid.UUID `json:"id"`
+    Device     string    `json:"device"`
+    UserAgent  *string   `json:"user_agent"`
+    IPAddress  *string   `json:"ip_address"`
+    CreatedAt  time.Time `json:"created_at"`
+    LastSeenAt time.Time `json:"last_seen_at"`
+    // Current marks the session the request was made with.
+    Current bool `json:"current"`
+}
+
+type SessionListResponse struct {
+    Sessions []SessionData `json:"sessions"`
+}
+
+type OIDCCallbackRequest struct {
+    Code  string `json:"code" validate:"required,max=2048"`
+    State string `json:"state" validate:"required,max=128"`
+}
+
+// OIDCAuthorizeResponse tells the client where to send the user. The client
+// should keep state and check it matches the one the provider redirects back with.
+type OIDCAuthorizeResponse struct {
+    AuthorizationURL string    `json:"authorization_url"`
+    State            string    `json:"state"`
+    ExpiresAt        time.Time `json:"expires_at"`
+}
+
+type IdentityData struct {
+    ID          uuid.UUID  `json:"id"`
+    Provider    string     `json:"provider"`
+    Email       *string    `json:"email"`
+    CreatedAt   time.Time  `json:"created_at"`
+    LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+type IdentityListResponse struct {
+    Identities []IdentityData `json:"identities"`
+}
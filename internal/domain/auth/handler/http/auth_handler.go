@@ -3,12 +3,14 @@ package http
 import (
 	"devsecops-be/internal/domain/auth/dto"
 	"devsecops-be/internal/domain/auth/service"
+	"devsecops-be/internal/middleware"
 	"devsecops-be/pkg/errors"
 	"devsecops-be/pkg/logger"
 	"devsecops-be/pkg/response"
 	"devsecops-be/pkg/validator"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type AuthHandler struct {
@@ -30,7 +32,7 @@ func NewAuthHandler(
 }
 
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	var req dto.LoginRequest
 
@@ -48,16 +50,20 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Validation failed", err)
 	}
 
-	result, err := h.authService.Login(ctx, req)
+	result, challenge, err := h.authService.Login(ctx, req)
 	if err != nil {
 		return errors.HandleHTTPError(c, err)
 	}
 
+	if challenge != nil {
+		return response.Success(c, "Two-factor authentication required", challenge)
+	}
+
 	return response.Success(c, "Login successful", result)
 }
 
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	var req dto.RegisterRequest
 
@@ -82,3 +88,548 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 
 	return response.Created(c, "Registration successful", result)
 }
+
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var req dto.RefreshRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in token refresh", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.authService.Refresh(ctx, req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Token refreshed successfully", result)
+}
+
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+	jti, expiresAt := middleware.GetTokenID(c)
+	sessionID, _ := middleware.GetSessionID(c)
+
+	var req dto.LogoutRequest
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.BadRequest(c, "Invalid request body", nil)
+		}
+		if err := h.validator.Validate(req); err != nil {
+			return response.BadRequest(c, "Validation failed", err)
+		}
+	}
+
+	if err := h.authService.Logout(ctx, userID, jti, expiresAt, sessionID, req); err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Logout successful", nil)
+}
+
+func (h *AuthHandler) LogoutAll(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	if err := h.authService.LogoutAll(ctx, userID); err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Logged out from all sessions", nil)
+}
+
+func (h *AuthHandler) GetProfile(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	result, err := h.authService.GetProfile(ctx, userID)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Profile retrieved successfully", result)
+}
+
+func (h *AuthHandler) UpdateProfile(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	var req dto.UpdateProfileRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in profile update", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in profile update", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	if req.Name == nil && req.Email == nil {
+		return response.BadRequest(c, "No fields to update", nil)
+	}
+
+	result, err := h.authService.UpdateProfile(ctx, userID, req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Profile updated successfully", result)
+}
+
+func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	var req dto.ChangePasswordRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in password change", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in password change", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.authService.ChangePassword(ctx, userID, req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Password changed successfully", result)
+}
+
+func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var req dto.ForgotPasswordRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in forgot password", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in forgot password", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	if err := h.authService.ForgotPassword(ctx, req); err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "If an account exists for this email, a password reset link has been sent", nil)
+}
+
+func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var req dto.ResetPasswordRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in reset password", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in reset password", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	if err := h.authService.ResetPassword(ctx, req); err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Password has been reset, please log in again", nil)
+}
+
+func (h *AuthHandler) VerifyEmail(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var req dto.VerifyEmailRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in email verification", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in email verification", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	if err := h.authService.VerifyEmail(ctx, req); err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Email verified successfully", nil)
+}
+
+func (h *AuthHandler) ResendVerification(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	if err := h.authService.ResendVerification(ctx, userID); err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Verification email sent", nil)
+}
+
+func (h *AuthHandler) LoginMFA(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var req dto.MFALoginRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in MFA login", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in MFA login", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.authService.LoginMFA(ctx, req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Login successful", result)
+}
+
+func (h *AuthHandler) SetupMFA(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	result, err := h.authService.SetupMFA(ctx, userID)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Scan the provisioning URI with an authenticator app, then confirm with a code", result)
+}
+
+func (h *AuthHandler) EnableMFA(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	var req dto.MFACodeRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in MFA enable", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in MFA enable", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.authService.EnableMFA(ctx, userID, req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Two-factor authentication enabled, store the recovery codes somewhere safe", result)
+}
+
+func (h *AuthHandler) DisableMFA(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	var req dto.MFADisableRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in MFA disable", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in MFA disable", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	if err := h.authService.DisableMFA(ctx, userID, req); err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Two-factor authentication disabled", nil)
+}
+
+func (h *AuthHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	var req dto.MFACodeRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in recovery code regeneration", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in recovery code regeneration", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.authService.RegenerateRecoveryCodes(ctx, userID, req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Recovery codes regenerated", result)
+}
+
+func (h *AuthHandler) ListSessions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+	currentSessionID, _ := middleware.GetSessionID(c)
+
+	result, err := h.authService.ListSessions(ctx, userID, currentSessionID)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Sessions retrieved successfully", result)
+}
+
+func (h *AuthHandler) RevokeSession(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid session ID", nil)
+	}
+
+	if err := h.authService.RevokeSession(ctx, userID, sessionID); err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Session revoked successfully", nil)
+}
+
+func (h *AuthHandler) StartOIDCLogin(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	result, err := h.authService.StartOIDCLogin(ctx, c.Params("provider"))
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Authorization URL created", result)
+}
+
+func (h *AuthHandler) OIDCCallback(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var req dto.OIDCCallbackRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in OIDC callback", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in OIDC callback", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, challenge, err := h.authService.OIDCLogin(ctx, c.Params("provider"), req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	if challenge != nil {
+		return response.Success(c, "Two-factor authentication required", challenge)
+	}
+
+	return response.Success(c, "Login successful", result)
+}
+
+func (h *AuthHandler) ListIdentities(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	result, err := h.authService.ListIdentities(ctx, userID)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Identities retrieved successfully", result)
+}
+
+func (h *AuthHandler) StartIdentityLink(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	result, err := h.authService.StartIdentityLink(ctx, userID, c.Params("provider"))
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Authorization URL created", result)
+}
+
+func (h *AuthHandler) LinkIdentity(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	var req dto.OIDCCallbackRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in identity link", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in identity link", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.authService.LinkIdentity(ctx, userID, c.Params("provider"), req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Identity linked successfully", result)
+}
+
+func (h *AuthHandler) UnlinkIdentity(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	identityID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid identity ID", nil)
+	}
+
+	if err := h.authService.UnlinkIdentity(ctx, userID, identityID); err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Identity unlinked successfully", nil)
+}
@@ -2,13 +2,21 @@ package auth
 
 import (
 	"database/sql"
+	auditService "devsecops-be/internal/domain/audit/service"
 	"devsecops-be/internal/domain/auth/handler/http"
 	"devsecops-be/internal/domain/auth/repository"
 	"devsecops-be/internal/domain/auth/service"
 	"devsecops-be/pkg/jwt"
+	"devsecops-be/pkg/lockout"
 	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/mailer"
+	"devsecops-be/pkg/oidc"
 	"devsecops-be/pkg/password"
+	"devsecops-be/pkg/passwordpolicy"
+	"devsecops-be/pkg/revocation"
+	"devsecops-be/pkg/secretbox"
 	"devsecops-be/pkg/validator"
+	"devsecops-be/pkg/worker"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -18,14 +26,47 @@ type AuthModule struct {
 	Service service.AuthService
 }
 
-func NewAuthModule(db *sql.DB, jwtUtil jwt.JWTUtil, logger logger.Logger) *AuthModule {
+// Config holds the auth settings, the lockout policies are applied per
+// account and per client IP.
+type Config struct {
+	Service        service.Config
+	AccountLockout lockout.Policy
+	IPLockout      lockout.Policy
+}
+
+func NewAuthModule(
+	db *sql.DB,
+	jwtUtil jwt.JWTUtil,
+	revocationStore revocation.Store,
+	mailer mailer.Mailer,
+	jobs *worker.Pool,
+	secretBox secretbox.Box,
+	passUtil password.PasswordUtil,
+	passPolicy passwordpolicy.Checker,
+	providers map[string]oidc.Provider,
+	attemptStore lockout.Store,
+	config Config,
+	audit auditService.AuditService,
+	logger logger.Logger,
+) *AuthModule {
 	// Initialize dependencies
 	authRepo := repository.NewAuthRepository(db)
-	passUtil := password.NewPasswordUtil()
+	refreshRepo := repository.NewRefreshTokenRepository(db)
+	resetRepo := repository.NewPasswordResetRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	identityRepo := repository.NewIdentityRepository(db)
 	validator := validator.NewValidator()
 
+	guards := service.LoginGuards{
+		Account: lockout.NewGuard(attemptStore, "email:", config.AccountLockout),
+		IP:      lockout.NewGuard(attemptStore, "ip:", config.IPLockout),
+	}
+
 	// Initialize service
-	authService := service.NewAuthService(authRepo, jwtUtil, passUtil, logger)
+	authService := service.NewAuthService(
+		authRepo, refreshRepo, resetRepo, mfaRepo, sessionRepo, identityRepo, jwtUtil, passUtil, passPolicy, revocationStore, mailer, jobs, secretBox, providers, config.Service, guards, audit, logger,
+	)
 
 	// Initialize handler
 	authHandler := http.NewAuthHandler(authService, validator, logger)
@@ -36,9 +77,35 @@ func NewAuthModule(db *sql.DB, jwtUtil jwt.JWTUtil, logger logger.Logger) *AuthM
 	}
 }
 
-func (m *AuthModule) RegisterRoutes(app *fiber.App) {
+func (m *AuthModule) RegisterRoutes(app *fiber.App, authMiddleware fiber.Handler) {
 	auth := app.Group("/api/v1/auth")
 
 	auth.Post("/login", m.Handler.Login)
+	auth.Post("/login/mfa", m.Handler.LoginMFA)
 	auth.Post("/register", m.Handler.Register)
+	auth.Post("/refresh", m.Handler.Refresh)
+	auth.Post("/forgot-password", m.Handler.ForgotPassword)
+	auth.Post("/reset-password", m.Handler.ResetPassword)
+	auth.Post("/verify-email", m.Handler.VerifyEmail)
+	auth.Post("/verify-email/resend", authMiddleware, m.Handler.ResendVerification)
+	auth.Post("/logout", authMiddleware, m.Handler.Logout)
+	auth.Post("/logout-all", authMiddleware, m.Handler.LogoutAll)
+	auth.Post("/mfa/setup", authMiddleware, m.Handler.SetupMFA)
+	auth.Post("/mfa/enable", authMiddleware, m.Handler.EnableMFA)
+	auth.Post("/mfa/disable", authMiddleware, m.Handler.DisableMFA)
+	auth.Post("/mfa/recovery-codes", authMiddleware, m.Handler.RegenerateRecoveryCodes)
+	auth.Post("/oidc/:provider/authorize", m.Handler.StartOIDCLogin)
+	auth.Post("/oidc/:provider/callback", m.Handler.OIDCCallback)
+
+	users := app.Group("/api/v1/users", authMiddleware)
+
+	users.Get("/me", m.Handler.GetProfile)
+	users.Patch("/me", m.Handler.UpdateProfile)
+	users.Post("/me/password", m.Handler.ChangePassword)
+	users.Get("/me/sessions", m.Handler.ListSessions)
+	users.Delete("/me/sessions/:id", m.Handler.RevokeSession)
+	users.Get("/me/identities", m.Handler.ListIdentities)
+	users.Post("/me/identities/:provider/authorize", m.Handler.StartIdentityLink)
+	users.Post("/me/identities/:provider/callback", m.Handler.LinkIdentity)
+	users.Delete("/me/identities/:id", m.Handler.UnlinkIdentity)
 }
@@ -15,15 +15,25 @@ type AuthRepository interface {
     CreateUser(ctx context.Context, user dto.RegisterRequest, hashedPassword string) (*dto.UserData, error)
     GetUserByEmail(ctx context.Context, email string) (*User, error)
     GetUserByID(ctx context.Context, id uuid.UUID) (*dto.UserData, error)
+    GetUserWithPassword(ctx context.Context, id uuid.UUID) (*User, error)
+    UpdateProfile(ctx context.Context, id uuid.UUID, req dto.UpdateProfileRequest) (*dto.UserData, error)
+    UpdatePassword(ctx context.Context, id uuid.UUID, hashedPassword string) error
+    UpgradePasswordHash(ctx context.Context, id uuid.UUID, currentHash, newHash string) error
+    MarkEmailVerified(ctx context.Context, id uuid.UUID, email string) (bool, error)
+    ReserveVerificationEmail(ctx context.Context, id uuid.UUID, cooldown time.Duration) (bool, error)
+    ReservePasswordResetEmail(ctx context.Context, id uuid.UUID, cooldown time.Duration) (bool, error)
 }
 
 type User struct {
     ID        uuid.UUID `db:"id"`
     Name      string    `db:"name"`
     Email     string    `db:"email"`
-    Password  string    `db:"password"`
-    CreatedAt time.Time `db:"created_at"`
-    UpdatedAt time.Time `db:"updated_at"`
+    Password   string       `db:"password"`
+    Role       string       `db:"role"`
+    VerifiedAt sql.NullTime `db:"verified_at"`
+    DisabledAt sql.NullTime `db:"disabled_at"`
+    CreatedAt  time.Time    `db:"created_at"`
+    UpdatedAt  time.Time    `db:"updated_at"`
 }
 
 type authRepository struct {
@@ -38,12 +48,12 @@ func (r *authRepository) CreateUser(ctx context.Context, user dto.RegisterReques
     query := `
         INSERT INTO users (name, email, password) 
         VALUES ($1, $2, $3) 
-        RETURNING id, name, email, created_at
+        RETURNING id, name, email, role, verified_at IS NOT NULL, created_at
     `
     
     var userData dto.UserData
     err := r.db.QueryRowContext(ctx, query, user.Name, user.Email, hashedPassword).
-        Scan(&userData.ID, &userData.Name, &userData.Email, &userData.CreatedAt)
+        Scan(&userData.ID, &userData.Name, &userData.Email, &userData.Role, &userData.EmailVerified, &userData.CreatedAt)
     
     if err != nil {
         // Handle unique constraint violation (duplicate email)
@@ -58,14 +68,14 @@ func (r *authRepository) CreateUser(ctx context.Context, user dto.RegisterReques
 
 func (r *authRepository) GetUserByEmail(ctx context.Context, email string) (*User, error) {
     query := `
-        SELECT id, name, email, password, created_at, updated_at 
+        SELECT id, name, email, password, role, verified_at, disabled_at, created_at, updated_at 
         FROM users 
         WHERE email = $1
     `
     
     var user User
     err := r.db.QueryRowContext(ctx, query, email).
-        Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt)
+        Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.Role, &user.VerifiedAt, &user.DisabledAt, &user.CreatedAt, &user.UpdatedAt)
     
     if err != nil {
         if err == sql.ErrNoRows {
@@ -79,14 +89,14 @@ func (r *authRepository) GetUserByEmail(ctx context.Context, email string) (*Use
 
 func (r *authRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*dto.UserData, error) {
     query := `
-        SELECT id, name, email, created_at 
+        SELECT id, name, email, role, verified_at IS NOT NULL, pending_email, created_at 
         FROM users 
         WHERE id = $1
     `
     
     var user dto.UserData
     err := r.db.QueryRowContext(ctx, query, id).
-        Scan(&user.ID, &user.Name, &user.Email, &user.CreatedAt)
+        Scan(&user.ID, &user.Name, &user.Email, &user.Role, &user.EmailVerified, &user.PendingEmail, &user.CreatedAt)
     
     if err != nil {
         if err == sql.ErrNoRows {
@@ -97,3 +107,181 @@ func (r *authRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*dto.Us
     
     return &user, nil
 }
+
+func (r *authRepository) GetUserWithPassword(ctx context.Context, id uuid.UUID) (*User, error) {
+    query := `
+        SELECT id, name, email, password, role, verified_at, disabled_at, created_at, updated_at
+        FROM users
+        WHERE id = $1
+    `
+
+    var user User
+    err := r.db.QueryRowContext(ctx, query, id).
+        Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.Role, &user.VerifiedAt, &user.DisabledAt, &user.CreatedAt, &user.UpdatedAt)
+
+    if err != nil {
+        if err == sql.ErrNoRows {
+            return nil, errors.ErrUserNotFound
+        }
+        return nil, errors.WrapDatabaseError(err, "failed to get user by ID")
+    }
+
+    return &user, nil
+}
+
+// UpdateProfile changes only the fields present in req. A new email is stored
+// as pending until MarkEmailVerified switches to it, and the verification
+// cooldown is reset so its link can be sent right away. Setting the current
+// email again cancels a pending change.
+func (r *authRepository) UpdateProfile(ctx context.Context, id uuid.UUID, req dto.UpdateProfileRequest) (*dto.UserData, error) {
+    query := `
+        UPDATE users
+        SET name = COALESCE($2, name),
+            pending_email = CASE
+                WHEN $3::VARCHAR IS NULL THEN pending_email
+                WHEN $3 = email THEN NULL
+                ELSE $3
+            END,
+            verification_sent_at = CASE
+                WHEN $3::VARCHAR IS NULL OR $3 = email OR $3 = pending_email THEN verification_sent_at
+            END,
+            updated_at = NOW()
+        WHERE id = $1
+        RETURNING id, name, email, role, verified_at IS NOT NULL, pending_email, created_at
+    `
+
+    var user dto.UserData
+    err := r.db.QueryRowContext(ctx, query, id, req.Name, req.Email).
+        Scan(&user.ID, &user.Name, &user.Email, &user.Role, &user.EmailVerified, &user.PendingEmail, &user.CreatedAt)
+
+    if err != nil {
+        if err == sql.ErrNoRows {
+            return nil, errors.ErrUserNotFound
+        }
+        if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+            return nil, errors.ErrUserAlreadyExists
+        }
+        return nil, errors.WrapDatabaseError(err, "failed to update user")
+    }
+
+    return &user, nil
+}
+
+func (r *authRepository) UpdatePassword(ctx context.Context, id uuid.UUID, hashedPassword string) error {
+    query := `
+        UPDATE users
+        SET password = $2, updated_at = NOW()
+        WHERE id = $1
+    `
+
+    result, err := r.db.ExecContext(ctx, query, id, hashedPassword)
+    if err != nil {
+        return errors.WrapDatabaseError(err, "failed to update password")
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return errors.WrapDatabaseError(err, "failed to update password")
+    }
+    if affected == 0 {
+        return errors.ErrUserNotFound
+    }
+
+    return nil
+}
+
+// UpgradePasswordHash replaces the hash of an unchanged password. It is a no-op
+// when the password was changed concurrently, so a rehash never undoes a reset.
+func (r *authRepository) UpgradePasswordHash(ctx context.Context, id uuid.UUID, currentHash, newHash string) error {
+    query := `
+        UPDATE users
+        SET password = $3
+        WHERE id = $1 AND password = $2
+    `
+
+    if _, err := r.db.ExecContext(ctx, query, id, currentHash, newHash); err != nil {
+        return errors.WrapDatabaseError(err, "failed to upgrade password hash")
+    }
+
+    return nil
+}
+
+// MarkEmailVerified verifies email if it is still the user's current or
+// pending address; a verified pending address replaces the current one. It
+// returns false when the address has changed since the link was issued.
+func (r *authRepository) MarkEmailVerified(ctx context.Context, id uuid.UUID, email string) (bool, error) {
+    query := `
+        UPDATE users
+        SET email = $2,
+            pending_email = NULL,
+            verified_at = CASE WHEN email = $2 THEN COALESCE(verified_at, NOW()) ELSE NOW() END,
+            updated_at = NOW()
+        WHERE id = $1 AND (email = $2 OR pending_email = $2)
+    `
+
+    result, err := r.db.ExecContext(ctx, query, id, email)
+    if err != nil {
+        if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+            return false, errors.ErrUserAlreadyExists
+        }
+        return false, errors.WrapDatabaseError(err, "failed to verify email")
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return false, errors.WrapDatabaseError(err, "failed to verify email")
+    }
+
+    return affected > 0, nil
+}
+
+// ReserveVerificationEmail records that a verification email is being sent.
+// It returns false when the previous one was sent less than cooldown ago.
+func (r *authRepository) ReserveVerificationEmail(ctx context.Context, id uuid.UUID, cooldown time.Duration) (bool, error) {
+    query := `
+        UPDATE users
+        SET verification_sent_at = $2
+        WHERE id = $1 AND (verification_sent_at IS NULL OR verification_sent_at <= $3)
+    `
+
+    reserved, err := r.reserveEmail(ctx, query, id, cooldown)
+    if err != nil {
+        return false, errors.WrapDatabaseError(err, "failed to record verification email")
+    }
+
+    return reserved, nil
+}
+
+// ReservePasswordResetEmail records that a password reset email is being
+// sent. It returns false when the previous one was sent less than cooldown ago.
+func (r *authRepository) ReservePasswordResetEmail(ctx context.Context, id uuid.UUID, cooldown time.Duration) (bool, error) {
+    query := `
+        UPDATE users
+        SET password_reset_sent_at = $2
+        WHERE id = $1 AND (password_reset_sent_at IS NULL OR password_reset_sent_at <= $3)
+    `
+
+    reserved, err := r.reserveEmail(ctx, query, id, cooldown)
+    if err != nil {
+        return false, errors.WrapDatabaseError(err, "failed to record password reset email")
+    }
+
+    return reserved, nil
+}
+
+// reserveEmail runs a reservation query with the current time and the start
+// of the cooldown, reporting whether a row was updated.
+func (r *authRepository) reserveEmail(ctx context.Context, query string, id uuid.UUID, cooldown time.Duration) (bool, error) {
+    now := time.Now().UTC()
+    result, err := r.db.ExecContext(ctx, query, id, now, now.Add(-cooldown))
+    if err != nil {
+        return false, err
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return false, err
+    }
+
+    return affected > 0, nil
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type IdentityRepository interface {
+	Create(ctx context.Context, identity *Identity) (*Identity, error)
+	GetBySubject(ctx context.Context, provider, subject string) (*Identity, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]Identity, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) (*Identity, error)
+	RecordLogin(ctx context.Context, id uuid.UUID, email string) error
+	CreateState(ctx context.Context, state *OIDCState) error
+	ConsumeState(ctx context.Context, stateHash, provider string) (*OIDCState, error)
+}
+
+// Identity links a user to an account at an external identity provider.
+type Identity struct {
+	ID          uuid.UUID      `db:"id"`
+	UserID      uuid.UUID      `db:"user_id"`
+	Provider    string         `db:"provider"`
+	Subject     string         `db:"subject"`
+	Email       sql.NullString `db:"email"`
+	CreatedAt   time.Time      `db:"created_at"`
+	LastLoginAt sql.NullTime   `db:"last_login_at"`
+}
+
+// OIDCState is a pending authorization request.
+type OIDCState struct {
+	StateHash    string        `db:"state_hash"`
+	Provider     string        `db:"provider"`
+	Nonce        string        `db:"nonce"`
+	CodeVerifier string        `db:"code_verifier"`
+	UserID       uuid.NullUUID `db:"user_id"`
+	ExpiresAt    time.Time     `db:"expires_at"`
+}
+
+const identityColumns = `id, user_id, provider, subject, email, created_at, last_login_at`
+
+type identityRepository struct {
+	db *sql.DB
+}
+
+func NewIdentityRepository(db *sql.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (r *identityRepository) Create(ctx context.Context, identity *Identity) (*Identity, error) {
+	query := `
+        INSERT INTO user_identities (user_id, provider, subject, email)
+        VALUES ($1, $2, $3, $4)
+        RETURNING ` + identityColumns
+
+	created, err := scanIdentity(r.db.QueryRowContext(ctx, query,
+		identity.UserID, identity.Provider, identity.Subject, identity.Email))
+	if err != nil {
+		// Either the provider account is linked elsewhere or the user already linked this provider
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, errors.ErrIdentityAlreadyLinked
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to link identity")
+	}
+
+	return created, nil
+}
+
+func (r *identityRepository) GetBySubject(ctx context.Context, provider, subject string) (*Identity, error) {
+	query := `
+        SELECT ` + identityColumns + `
+        FROM user_identities
+        WHERE provider = $1 AND subject = $2
+    `
+
+	identity, err := scanIdentity(r.db.QueryRowContext(ctx, query, provider, subject))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrIdentityNotFound
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to get identity")
+	}
+
+	return identity, nil
+}
+
+func (r *identityRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]Identity, error) {
+	query := `
+        SELECT ` + identityColumns + `
+        FROM user_identities
+        WHERE user_id = $1
+        ORDER BY created_at
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "failed to list identities")
+	}
+	defer rows.Close()
+
+	var identities []Identity
+	for rows.Next() {
+		identity, err := scanIdentity(rows)
+		if err != nil {
+			return nil, errors.WrapDatabaseError(err, "failed to scan identity")
+		}
+		identities = append(identities, *identity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapDatabaseError(err, "failed to list identities")
+	}
+
+	return identities, nil
+}
+
+func (r *identityRepository) Delete(ctx context.Context, userID, id uuid.UUID) (*Identity, error) {
+	query := `
+        DELETE FROM user_identities
+        WHERE id = $1 AND user_id = $2
+        RETURNING ` + identityColumns
+
+	identity, err := scanIdentity(r.db.QueryRowContext(ctx, query, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrIdentityNotFound
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to unlink identity")
+	}
+
+	return identity, nil
+}
+
+// RecordLogin stores the login time and the email the provider reported.
+func (r *identityRepository) RecordLogin(ctx context.Context, id uuid.UUID, email string) error {
+	query := `
+        UPDATE user_identities
+        SET last_login_at = NOW(), email = COALESCE(NULLIF($2, ''), email)
+        WHERE id = $1
+    `
+
+	if _, err := r.db.ExecContext(ctx, query, id, email); err != nil {
+		return errors.WrapDatabaseError(err, "failed to record identity login")
+	}
+
+	return nil
+}
+
+// CreateState stores a pending authorization request. Abandoned requests are
+// pruned at the same time, so the table only holds recent ones.
+func (r *identityRepository) CreateState(ctx context.Context, state *OIDCState) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM oidc_login_states WHERE expires_at < $1`, time.Now().UTC()); err != nil {
+		return errors.WrapDatabaseError(err, "failed to prune login states")
+	}
+
+	query := `
+        INSERT INTO oidc_login_states (state_hash, provider, nonce, code_verifier, user_id, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+    `
+
+	_, err := r.db.ExecContext(ctx, query,
+		state.StateHash, state.Provider, state.Nonce, state.CodeVerifier, state.UserID, state.ExpiresAt.UTC())
+	if err != nil {
+		return errors.WrapDatabaseError(err, "failed to store login state")
+	}
+
+	return nil
+}
+
+// ConsumeState deletes and returns an unexpired state issued for provider, so
+// every state can complete at most one login.
+func (r *identityRepository) ConsumeState(ctx context.Context, stateHash, provider string) (*OIDCState, error) {
+	query := `
+        DELETE FROM oidc_login_states
+        WHERE state_hash = $1 AND provider = $2 AND expires_at > $3
+        RETURNING state_hash, provider, nonce, code_verifier, user_id, expires_at
+    `
+
+	var state OIDCState
+	err := r.db.QueryRowContext(ctx, query, stateHash, provider, time.Now().UTC()).Scan(
+		&state.StateHash, &state.Provider, &state.Nonce, &state.CodeVerifier, &state.UserID, &state.ExpiresAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrInvalidOIDCState
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to consume login state")
+	}
+
+	return &state, nil
+}
+
+func scanIdentity(row rowScanner) (*Identity, error) {
+	var identity Identity
+	err := row.Scan(
+		&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email,
+		&identity.CreatedAt, &identity.LastLoginAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
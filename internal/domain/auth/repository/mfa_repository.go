@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MFARepository interface {
+	Get(ctx context.Context, userID uuid.UUID) (*UserMFA, error)
+	SavePending(ctx context.Context, userID uuid.UUID, encryptedSecret string) error
+	Enable(ctx context.Context, userID uuid.UUID, step int64, codeHashes []string) error
+	ConsumeStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	Delete(ctx context.Context, userID uuid.UUID) error
+}
+
+type UserMFA struct {
+	UserID          uuid.UUID     `db:"user_id"`
+	SecretEncrypted string        `db:"secret_encrypted"`
+	EnabledAt       sql.NullTime  `db:"enabled_at"`
+	LastUsedStep    sql.NullInt64 `db:"last_used_step"`
+	CreatedAt       time.Time     `db:"created_at"`
+}
+
+type mfaRepository struct {
+	db *sql.DB
+}
+
+func NewMFARepository(db *sql.DB) MFARepository {
+	return &mfaRepository{db: db}
+}
+
+func (r *mfaRepository) Get(ctx context.Context, userID uuid.UUID) (*UserMFA, error) {
+	query := `
+        SELECT user_id, secret_encrypted, enabled_at, last_used_step, created_at
+        FROM user_mfa
+        WHERE user_id = $1
+    `
+
+	var mfa UserMFA
+	err := r.db.QueryRowContext(ctx, query, userID).
+		Scan(&mfa.UserID, &mfa.SecretEncrypted, &mfa.EnabledAt, &mfa.LastUsedStep, &mfa.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrMFANotEnabled
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to get MFA settings")
+	}
+
+	return &mfa, nil
+}
+
+// SavePending stores a new, not yet confirmed secret. It fails with
+// ErrMFAAlreadyEnabled instead of replacing the secret of an active enrollment.
+func (r *mfaRepository) SavePending(ctx context.Context, userID uuid.UUID, encryptedSecret string) error {
+	query := `
+        INSERT INTO user_mfa (user_id, secret_encrypted)
+        VALUES ($1, $2)
+        ON CONFLICT (user_id) DO UPDATE
+        SET secret_encrypted = EXCLUDED.secret_encrypted,
+            last_used_step = NULL,
+            created_at = NOW()
+        WHERE user_mfa.enabled_at IS NULL
+    `
+
+	result, err := r.db.ExecContext(ctx, query, userID, encryptedSecret)
+	if err != nil {
+		return errors.WrapDatabaseError(err, "failed to save MFA secret")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.WrapDatabaseError(err, "failed to save MFA secret")
+	}
+	if affected == 0 {
+		return errors.ErrMFAAlreadyEnabled
+	}
+
+	return nil
+}
+
+// Enable confirms a pending enrollment and stores its first recovery codes.
+func (r *mfaRepository) Enable(ctx context.Context, userID uuid.UUID, step int64, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.WrapDatabaseError(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+        UPDATE user_mfa
+        SET enabled_at = NOW(), last_used_step = $2
+        WHERE user_id = $1 AND enabled_at IS NULL
+    `, userID, step)
+	if err != nil {
+		return errors.WrapDatabaseError(err, "failed to enable MFA")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.WrapDatabaseError(err, "failed to enable MFA")
+	}
+	if affected == 0 {
+		return errors.ErrMFAAlreadyEnabled
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.WrapDatabaseError(err, "failed to commit transaction")
+	}
+
+	return nil
+}
+
+// ConsumeStep records step as the last accepted TOTP step. It returns false
+// when a code from this or a later step was already used.
+func (r *mfaRepository) ConsumeStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	query := `
+        UPDATE user_mfa
+        SET last_used_step = $2
+        WHERE user_id = $1 AND (last_used_step IS NULL OR last_used_step < $2)
+    `
+
+	result, err := r.db.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return false, errors.WrapDatabaseError(err, "failed to record MFA code use")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.WrapDatabaseError(err, "failed to record MFA code use")
+	}
+
+	return affected > 0, nil
+}
+
+func (r *mfaRepository) ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	query := `
+        UPDATE mfa_recovery_codes
+        SET used_at = NOW()
+        WHERE id = (
+            SELECT id FROM mfa_recovery_codes
+            WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+            LIMIT 1
+            FOR UPDATE
+        )
+    `
+
+	result, err := r.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, errors.WrapDatabaseError(err, "failed to use recovery code")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.WrapDatabaseError(err, "failed to use recovery code")
+	}
+
+	return affected > 0, nil
+}
+
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.WrapDatabaseError(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.WrapDatabaseError(err, "failed to commit transaction")
+	}
+
+	return nil
+}
+
+func (r *mfaRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return errors.WrapDatabaseError(err, "failed to disable MFA")
+	}
+
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID uuid.UUID, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return errors.WrapDatabaseError(err, "failed to delete recovery codes")
+	}
+
+	for _, codeHash := range codeHashes {
+		_, err := tx.ExecContext(ctx, `
+            INSERT INTO mfa_recovery_codes (user_id, code_hash)
+            VALUES ($1, $2)
+        `, userID, codeHash)
+		if err != nil {
+			return errors.WrapDatabaseError(err, "failed to store recovery code")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PasswordResetRepository interface {
+	Create(ctx context.Context, userID uuid.UUID, tokenHash string, expiresAt time.Time) error
+	Lookup(ctx context.Context, tokenHash string) (uuid.UUID, error)
+	Consume(ctx context.Context, tokenHash string) (uuid.UUID, error)
+	InvalidateForUser(ctx context.Context, userID uuid.UUID) error
+}
+
+type passwordResetRepository struct {
+	db *sql.DB
+}
+
+func NewPasswordResetRepository(db *sql.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+func (r *passwordResetRepository) Create(ctx context.Context, userID uuid.UUID, tokenHash string, expiresAt time.Time) error {
+	query := `
+        INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+        VALUES ($1, $2, $3)
+    `
+
+	if _, err := r.db.ExecContext(ctx, query, userID, tokenHash, expiresAt.UTC()); err != nil {
+		return errors.WrapDatabaseError(err, "failed to create password reset token")
+	}
+
+	return nil
+}
+
+// Lookup returns the owner of an unused, unexpired token without using it.
+func (r *passwordResetRepository) Lookup(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	query := `
+        SELECT user_id
+        FROM password_reset_tokens
+        WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+    `
+
+	var userID uuid.UUID
+	err := r.db.QueryRowContext(ctx, query, tokenHash, time.Now().UTC()).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, errors.ErrInvalidResetToken
+		}
+		return uuid.Nil, errors.WrapDatabaseError(err, "failed to look up password reset token")
+	}
+
+	return userID, nil
+}
+
+// Consume marks an unused, unexpired token as used and returns its owner. The
+// check and update happen in one statement so a token can only be used once.
+func (r *passwordResetRepository) Consume(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	query := `
+        UPDATE password_reset_tokens
+        SET used_at = NOW()
+        WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+        RETURNING user_id
+    `
+
+	var userID uuid.UUID
+	err := r.db.QueryRowContext(ctx, query, tokenHash, time.Now().UTC()).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, errors.ErrInvalidResetToken
+		}
+		return uuid.Nil, errors.WrapDatabaseError(err, "failed to consume password reset token")
+	}
+
+	return userID, nil
+}
+
+// InvalidateForUser marks every outstanding reset token of the user as used.
+func (r *passwordResetRepository) InvalidateForUser(ctx context.Context, userID uuid.UUID) error {
+	query := `
+        UPDATE password_reset_tokens
+        SET used_at = NOW()
+        WHERE user_id = $1 AND used_at IS NULL
+    `
+
+	if _, err := r.db.ExecContext(ctx, query, userID); err != nil {
+		return errors.WrapDatabaseError(err, "failed to invalidate password reset tokens")
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *RefreshToken) (*RefreshToken, error)
+	GetByHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	MarkUsed(ctx context.Context, id uuid.UUID) (bool, error)
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID) error
+}
+
+// RefreshToken is one link in a rotation chain. Every token issued from the
+// same login shares a FamilyID so the whole chain can be revoked at once.
+type RefreshToken struct {
+	ID        uuid.UUID     `db:"id"`
+	UserID    uuid.UUID     `db:"user_id"`
+	FamilyID  uuid.UUID     `db:"family_id"`
+	ParentID  uuid.NullUUID `db:"parent_id"`
+	TokenHash string        `db:"token_hash"`
+	ExpiresAt time.Time     `db:"expires_at"`
+	UsedAt    sql.NullTime  `db:"used_at"`
+	RevokedAt sql.NullTime  `db:"revoked_at"`
+	CreatedAt time.Time     `db:"created_at"`
+}
+
+const refreshTokenColumns = `id, user_id, family_id, parent_id, token_hash, expires_at, used_at, revoked_at, created_at`
+
+type refreshTokenRepository struct {
+	db *sql.DB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token *RefreshToken) (*RefreshToken, error) {
+	query := `
+        INSERT INTO refresh_tokens (user_id, family_id, parent_id, token_hash, expires_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING ` + refreshTokenColumns
+
+	created, err := scanRefreshToken(r.db.QueryRowContext(ctx, query,
+		token.UserID, token.FamilyID, token.ParentID, token.TokenHash, token.ExpiresAt))
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "failed to create refresh token")
+	}
+
+	return created, nil
+}
+
+func (r *refreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	query := `
+        SELECT ` + refreshTokenColumns + `
+        FROM refresh_tokens
+        WHERE token_hash = $1
+    `
+
+	token, err := scanRefreshToken(r.db.QueryRowContext(ctx, query, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrInvalidToken
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to get refresh token")
+	}
+
+	return token, nil
+}
+
+// MarkUsed consumes the token. It returns false when the token was already
+// used or revoked, which callers must treat as reuse.
+func (r *refreshTokenRepository) MarkUsed(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+        UPDATE refresh_tokens
+        SET used_at = NOW()
+        WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL
+    `
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, errors.WrapDatabaseError(err, "failed to mark refresh token as used")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.WrapDatabaseError(err, "failed to mark refresh token as used")
+	}
+
+	return affected > 0, nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	query := `
+        UPDATE refresh_tokens
+        SET revoked_at = NOW()
+        WHERE family_id = $1 AND revoked_at IS NULL
+    `
+
+	if _, err := r.db.ExecContext(ctx, query, familyID); err != nil {
+		return errors.WrapDatabaseError(err, "failed to revoke refresh token family")
+	}
+
+	return nil
+}
+
+func (r *refreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	query := `
+        UPDATE refresh_tokens
+        SET revoked_at = NOW()
+        WHERE user_id = $1 AND revoked_at IS NULL
+    `
+
+	if _, err := r.db.ExecContext(ctx, query, userID); err != nil {
+		return errors.WrapDatabaseError(err, "failed to revoke user refresh tokens")
+	}
+
+	return nil
+}
+
+func scanRefreshToken(row *sql.Row) (*RefreshToken, error) {
+	var token RefreshToken
+	err := row.Scan(
+		&token.ID, &token.UserID, &token.FamilyID, &token.ParentID, &token.TokenHash,
+		&token.ExpiresAt, &token.UsedAt, &token.RevokedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *Session) error
+	Touch(ctx context.Context, id uuid.UUID, userAgent, device, ipAddress string) error
+	ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]Session, error)
+	GetActive(ctx context.Context, userID, id uuid.UUID) (*Session, error)
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// Session is one login on one device. Its ID is the refresh token family ID.
+type Session struct {
+	ID         uuid.UUID      `db:"id"`
+	UserID     uuid.UUID      `db:"user_id"`
+	Device     string         `db:"device"`
+	UserAgent  sql.NullString `db:"user_agent"`
+	IPAddress  sql.NullString `db:"ip_address"`
+	CreatedAt  time.Time      `db:"created_at"`
+	LastSeenAt time.Time      `db:"last_seen_at"`
+}
+
+const sessionColumns = `s.id, s.user_id, s.device, s.user_agent, s.ip_address, s.created_at, s.last_seen_at`
+
+// A session is active while its family still has a refresh token that can be
+// rotated; logging out, revoking and reuse detection all end it that way.
+const activeSession = `
+        EXISTS (
+            SELECT 1 FROM refresh_tokens t
+            WHERE t.family_id = s.id AND t.used_at IS NULL AND t.revoked_at IS NULL AND t.expires_at > $2
+        )`
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *Session) error {
+	query := `
+        INSERT INTO sessions (id, user_id, device, user_agent, ip_address)
+        VALUES ($1, $2, $3, $4, $5)
+    `
+
+	_, err := r.db.ExecContext(ctx, query,
+		session.ID, session.UserID, session.Device, session.UserAgent, session.IPAddress)
+	if err != nil {
+		return errors.WrapDatabaseError(err, "failed to create session")
+	}
+
+	return nil
+}
+
+// Touch records that the session was used again, from the given client.
+func (r *sessionRepository) Touch(ctx context.Context, id uuid.UUID, userAgent, device, ipAddress string) error {
+	query := `
+        UPDATE sessions
+        SET last_seen_at = NOW(),
+            user_agent = COALESCE(NULLIF($2, ''), user_agent),
+            device = COALESCE(NULLIF($3, ''), device),
+            ip_address = COALESCE(NULLIF($4, ''), ip_address)
+        WHERE id = $1
+    `
+
+	if _, err := r.db.ExecContext(ctx, query, id, userAgent, device, ipAddress); err != nil {
+		return errors.WrapDatabaseError(err, "failed to update session")
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]Session, error) {
+	query := `
+        SELECT ` + sessionColumns + `
+        FROM sessions s
+        WHERE s.user_id = $1 AND` + activeSession + `
+        ORDER BY s.last_seen_at DESC
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, userID, time.Now().UTC())
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "failed to list sessions")
+	}
+	defer rows.Close()
+
+	var sessions []Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, errors.WrapDatabaseError(err, "failed to scan session")
+		}
+		sessions = append(sessions, *session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapDatabaseError(err, "failed to list sessions")
+	}
+
+	return sessions, nil
+}
+
+func (r *sessionRepository) GetActive(ctx context.Context, userID, id uuid.UUID) (*Session, error) {
+	query := `
+        SELECT ` + sessionColumns + `
+        FROM sessions s
+        WHERE s.user_id = $1 AND` + activeSession + ` AND s.id = $3
+    `
+
+	session, err := scanSession(r.db.QueryRowContext(ctx, query, userID, time.Now().UTC(), id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrSessionNotFound
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to get session")
+	}
+
+	return session, nil
+}
+
+// DeleteExpired removes sessions whose refresh tokens have all expired, along
+// with those tokens. Sessions younger than an hour are kept because the first
+// refresh token is stored right after its session.
+func (r *sessionRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	query := `
+        DELETE FROM sessions s
+        WHERE s.created_at < NOW() - INTERVAL '1 hour'
+            AND NOT EXISTS (
+                SELECT 1 FROM refresh_tokens t
+                WHERE t.family_id = s.id AND t.expires_at > $1
+            )
+    `
+
+	result, err := r.db.ExecContext(ctx, query, time.Now().UTC())
+	if err != nil {
+		return 0, errors.WrapDatabaseError(err, "failed to delete expired sessions")
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.WrapDatabaseError(err, "failed to delete expired sessions")
+	}
+	return removed, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSession(row rowScanner) (*Session, error) {
+	var session Session
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.Device, &session.UserAgent, &session.IPAddress,
+		&session.CreatedAt, &session.LastSeenAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
@@ -2,57 +2,213 @@ package service
 
 import (
     "context"
+    "crypto/rand"
+    "database/sql"
+    auditService "devsecops-be/internal/domain/audit/service"
     "devsecops-be/internal/domain/auth/dto"
     "devsecops-be/internal/domain/auth/repository"
     "devsecops-be/pkg/errors"
     "devsecops-be/pkg/jwt"
+    "devsecops-be/pkg/lockout"
     "devsecops-be/pkg/logger"
+    "devsecops-be/pkg/mailer"
+    "devsecops-be/pkg/oidc"
     "devsecops-be/pkg/password"
+    "devsecops-be/pkg/passwordpolicy"
+    "devsecops-be/pkg/requestinfo"
+    "devsecops-be/pkg/revocation"
+    "devsecops-be/pkg/secretbox"
+    "devsecops-be/pkg/token"
+    "devsecops-be/pkg/totp"
+    "devsecops-be/pkg/useragent"
+    "devsecops-be/pkg/worker"
+    "encoding/base32"
+    "fmt"
+    "net/url"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/google/uuid"
 )
 
 type AuthService interface {
-    Login(ctx context.Context, req dto.LoginRequest) (*dto.AuthResponse, error)
+    Login(ctx context.Context, req dto.LoginRequest) (*dto.AuthResponse, *dto.MFAChallengeResponse, error)
+    LoginMFA(ctx context.Context, req dto.MFALoginRequest) (*dto.AuthResponse, error)
     Register(ctx context.Context, req dto.RegisterRequest) (*dto.AuthResponse, error)
+    CreateUser(ctx context.Context, req dto.RegisterRequest) (*dto.UserData, error)
+    Refresh(ctx context.Context, req dto.RefreshRequest) (*dto.AuthResponse, error)
+    Logout(ctx context.Context, userID uuid.UUID, jti string, expiresAt time.Time, sessionID uuid.UUID, req dto.LogoutRequest) error
+    LogoutAll(ctx context.Context, userID uuid.UUID) error
+    GetProfile(ctx context.Context, userID uuid.UUID) (*dto.UserData, error)
+    UpdateProfile(ctx context.Context, userID uuid.UUID, req dto.UpdateProfileRequest) (*dto.UserData, error)
+    ChangePassword(ctx context.Context, userID uuid.UUID, req dto.ChangePasswordRequest) (*dto.AuthResponse, error)
+    ForgotPassword(ctx context.Context, req dto.ForgotPasswordRequest) error
+    ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) error
+    VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) error
+    ResendVerification(ctx context.Context, userID uuid.UUID) error
+    IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error)
+    SetupMFA(ctx context.Context, userID uuid.UUID) (*dto.MFASetupResponse, error)
+    EnableMFA(ctx context.Context, userID uuid.UUID, req dto.MFACodeRequest) (*dto.MFARecoveryCodesResponse, error)
+    DisableMFA(ctx context.Context, userID uuid.UUID, req dto.MFADisableRequest) error
+    RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, req dto.MFACodeRequest) (*dto.MFARecoveryCodesResponse, error)
+    ListSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID) (*dto.SessionListResponse, error)
+    RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error
+    RevokeAllSessions(ctx context.Context, userID uuid.UUID) error
+    StartOIDCLogin(ctx context.Context, provider string) (*dto.OIDCAuthorizeResponse, error)
+    OIDCLogin(ctx context.Context, provider string, req dto.OIDCCallbackRequest) (*dto.AuthResponse, *dto.MFAChallengeResponse, error)
+    StartIdentityLink(ctx context.Context, userID uuid.UUID, provider string) (*dto.OIDCAuthorizeResponse, error)
+    LinkIdentity(ctx context.Context, userID uuid.UUID, provider string, req dto.OIDCCallbackRequest) (*dto.IdentityData, error)
+    ListIdentities(ctx context.Context, userID uuid.UUID) (*dto.IdentityListResponse, error)
+    UnlinkIdentity(ctx context.Context, userID uuid.UUID, id uuid.UUID) error
+}
+
+const (
+    recoveryCodeCount = 10
+    // totpSkew accepts codes from one step before and after the current one.
+    totpSkew = 1
+)
+
+// Config holds the settings for links mailed by the auth service. Each URL is
+// a frontend page that receives the token as the "token" query parameter.
+type Config struct {
+    PasswordReset     PasswordResetConfig
+    EmailVerification EmailVerificationConfig
+    MFA               MFAConfig
+    OIDC              OIDCConfig
+}
+
+type PasswordResetConfig struct {
+    URL      string
+    TokenExp time.Duration
+    // ResendCooldown is the minimum time between reset emails to an account,
+    // requests within it are ignored.
+    ResendCooldown time.Duration
+}
+
+// LoginGuards throttle failed logins per account (keyed by email) and per
+// client IP. A successful login only clears the account counter.
+type LoginGuards struct {
+    Account *lockout.Guard
+    IP      *lockout.Guard
+}
+
+type MFAConfig struct {
+    // Issuer is the account label shown in authenticator apps.
+    Issuer          string
+    PendingTokenExp time.Duration
+}
+
+type OIDCConfig struct {
+    // StateExp is how long the user has to finish signing in at the provider.
+    StateExp time.Duration
+}
+
+type EmailVerificationConfig struct {
+    URL            string
+    TokenExp       time.Duration
+    ResendCooldown time.Duration
 }
 
 type authService struct {
-    authRepo repository.AuthRepository
-    jwtUtil  jwt.JWTUtil
-    passUtil password.PasswordUtil
-    logger   logger.Logger
+    authRepo     repository.AuthRepository
+    refreshRepo  repository.RefreshTokenRepository
+    resetRepo    repository.PasswordResetRepository
+    mfaRepo      repository.MFARepository
+    sessionRepo  repository.SessionRepository
+    identityRepo repository.IdentityRepository
+    jwtUtil      jwt.JWTUtil
+    passUtil     password.PasswordUtil
+    passPolicy   passwordpolicy.Checker
+    revocation   revocation.Store
+    mailer       mailer.Mailer
+    jobs         *worker.Pool
+    secretBox    secretbox.Box
+    providers    map[string]oidc.Provider
+    config       Config
+    guards       LoginGuards
+    audit        auditService.AuditService
+    logger       logger.Logger
+
+    // dummyHash is a hash of a random password in the configured format,
+    // made on first use; see checkDummyPassword.
+    dummyHashOnce sync.Once
+    dummyHash     string
 }
 
 func NewAuthService(
     authRepo repository.AuthRepository, 
+    refreshRepo repository.RefreshTokenRepository,
+    resetRepo repository.PasswordResetRepository,
+    mfaRepo repository.MFARepository,
+    sessionRepo repository.SessionRepository,
+    identityRepo repository.IdentityRepository,
     jwtUtil jwt.JWTUtil, 
     passUtil password.PasswordUtil,
+    passPolicy passwordpolicy.Checker,
+    revocationStore revocation.Store,
+    mailer mailer.Mailer,
+    jobs *worker.Pool,
+    secretBox secretbox.Box,
+    providers map[string]oidc.Provider,
+    config Config,
+    guards LoginGuards,
+    audit auditService.AuditService,
     logger logger.Logger,
 ) AuthService {
     return &authService{
-        authRepo: authRepo,
-        jwtUtil:  jwtUtil,
-        passUtil: passUtil,
-        logger:   logger,
+        authRepo:     authRepo,
+        refreshRepo:  refreshRepo,
+        resetRepo:    resetRepo,
+        mfaRepo:      mfaRepo,
+        sessionRepo:  sessionRepo,
+        identityRepo: identityRepo,
+        jwtUtil:      jwtUtil,
+        passUtil:     passUtil,
+        passPolicy:   passPolicy,
+        revocation:   revocationStore,
+        mailer:       mailer,
+        jobs:         jobs,
+        secretBox:    secretBox,
+        providers:    providers,
+        config:       config,
+        guards:       guards,
+        audit:        audit,
+        logger:       logger,
     }
 }
 
-func (s *authService) Login(ctx context.Context, req dto.LoginRequest) (*dto.AuthResponse, error) {
+// Login checks the password. Accounts with two-factor authentication get an
+// MFA challenge instead of tokens and finish signing in through LoginMFA.
+func (s *authService) Login(ctx context.Context, req dto.LoginRequest) (*dto.AuthResponse, *dto.MFAChallengeResponse, error) {
     s.logger.Info(ctx, "Attempting user login", logger.Fields{
         "email": req.Email,
     })
 
+    if err := s.checkLoginAllowed(ctx, req.Email); err != nil {
+        return nil, nil, err
+    }
+
     user, err := s.authRepo.GetUserByEmail(ctx, req.Email)
     if err != nil {
         if err == errors.ErrUserNotFound {
+            s.checkDummyPassword(ctx, req.Password)
             s.logger.Warn(ctx, "Login attempt with non-existent email", logger.Fields{
                 "email": req.Email,
             })
-            return nil, errors.ErrInvalidCredentials
+            s.audit.Record(ctx, auditService.Event{
+                Action:   auditService.ActionLoginFailure,
+                Metadata: map[string]interface{}{"email": req.Email, "reason": "user_not_found"},
+            })
+            if lockErr := s.recordLoginFailure(ctx, req.Email, nil); lockErr != nil {
+                return nil, nil, lockErr
+            }
+            return nil, nil, errors.ErrInvalidCredentials
         }
         s.logger.Error(ctx, "Failed to get user during login", err, logger.Fields{
             "email": req.Email,
         })
-        return nil, err
+        return nil, nil, err
     }
 
     if !s.passUtil.CheckPassword(req.Password, user.Password) {
@@ -60,22 +216,201 @@ func (s *authService) Login(ctx context.Context, req dto.LoginRequest) (*dto.Aut
             "user_id": user.ID,
             "email":   req.Email,
         })
-        return nil, errors.ErrInvalidCredentials
+        s.audit.Record(ctx, auditService.Event{
+            UserID:   &user.ID,
+            Action:   auditService.ActionLoginFailure,
+            Metadata: map[string]interface{}{"email": req.Email, "reason": "invalid_password"},
+        })
+        if lockErr := s.recordLoginFailure(ctx, req.Email, &user.ID); lockErr != nil {
+            return nil, nil, lockErr
+        }
+        return nil, nil, errors.ErrInvalidCredentials
+    }
+
+    s.upgradePasswordHash(ctx, user, req.Password)
+
+    // Checked after the password so the response does not reveal that a
+    // disabled account exists to someone who does not know its password.
+    if err := s.checkNotDisabled(ctx, user); err != nil {
+        return nil, nil, err
+    }
+
+    return s.beginLogin(ctx, user, nil)
+}
+
+// checkDummyPassword checks password against a hash no account has, so a
+// login with an unknown email takes as long as one with a wrong password and
+// response times do not reveal which emails are registered.
+func (s *authService) checkDummyPassword(ctx context.Context, password string) {
+    s.dummyHashOnce.Do(func() {
+        secret, err := token.Generate(32)
+        if err == nil {
+            s.dummyHash, err = s.passUtil.HashPassword(secret)
+        }
+        if err != nil {
+            s.logger.Error(ctx, "Failed to create dummy password hash", err)
+        }
+    })
+
+    s.passUtil.CheckPassword(password, s.dummyHash)
+}
+
+// checkPasswordPolicy rejects a new password that breaks the password policy.
+// field names the request field the violations are reported on.
+func (s *authService) checkPasswordPolicy(ctx context.Context, field, password string, personalInfo ...string) error {
+    violations, err := s.passPolicy.Check(ctx, password, personalInfo...)
+    if err != nil {
+        // An unreadable breach corpus is logged rather than blocking every
+        // sign up and password change; the other rules still apply
+        s.logger.Error(ctx, "Failed to check breached password corpus", err)
+    }
+    if len(violations) == 0 {
+        return nil
+    }
+
+    details := make([]errors.FieldError, 0, len(violations))
+    for _, violation := range violations {
+        details = append(details, errors.FieldError{
+            Field:   field,
+            Code:    violation.Code,
+            Message: violation.Message,
+        })
     }
 
-    userData := dto.UserData{
-        ID:        user.ID,
-        Name:      user.Name,
-        Email:     user.Email,
-        CreatedAt: user.CreatedAt,
+    return errors.ErrPasswordPolicy.WithDetails(details)
+}
+
+// upgradePasswordHash re-hashes a verified password whose stored hash uses an
+// older algorithm or weaker parameters. Failures are logged and never block
+// the login; the upgrade is retried on the next one.
+func (s *authService) upgradePasswordHash(ctx context.Context, user *repository.User, password string) {
+    if !s.passUtil.NeedsRehash(user.Password) {
+        return
     }
 
-    token, expiresAt, err := s.jwtUtil.GenerateToken(user.ID)
+    hashedPassword, err := s.passUtil.HashPassword(password)
     if err != nil {
-        s.logger.Error(ctx, "Failed to generate JWT token", err, logger.Fields{
+        s.logger.Error(ctx, "Failed to rehash password", err, logger.Fields{
+            "user_id": user.ID,
+        })
+        return
+    }
+
+    if err := s.authRepo.UpgradePasswordHash(ctx, user.ID, user.Password, hashedPassword); err != nil {
+        s.logger.Error(ctx, "Failed to store upgraded password hash", err, logger.Fields{
+            "user_id": user.ID,
+        })
+        return
+    }
+
+    s.logger.Info(ctx, "Password hash upgraded", logger.Fields{
+        "user_id": user.ID,
+    })
+}
+
+// beginLogin finishes a login whose first factor succeeded. Accounts with
+// two-factor authentication get an mfa_pending challenge instead of tokens.
+func (s *authService) beginLogin(ctx context.Context, user *repository.User, metadata map[string]interface{}) (*dto.AuthResponse, *dto.MFAChallengeResponse, error) {
+    mfa, err := s.mfaRepo.Get(ctx, user.ID)
+    if err != nil && err != errors.ErrMFANotEnabled {
+        return nil, nil, err
+    }
+    if mfa != nil && mfa.EnabledAt.Valid {
+        mfaToken, expiresAt, err := s.jwtUtil.GenerateTypedToken(user.ID, jwt.TokenTypeMFAPending, s.config.MFA.PendingTokenExp, nil)
+        if err != nil {
+            s.logger.Error(ctx, "Failed to generate MFA token", err, logger.Fields{
+                "user_id": user.ID,
+            })
+            return nil, nil, errors.WrapInternalError(err, "failed to generate token")
+        }
+
+        s.logger.Info(ctx, "Password verified, awaiting second factor", logger.Fields{
+            "user_id": user.ID,
+        })
+
+        return nil, &dto.MFAChallengeResponse{
+            MFARequired: true,
+            MFAToken:    mfaToken,
+            ExpiresAt:   expiresAt,
+        }, nil
+    }
+
+    result, err := s.completeLogin(ctx, user, metadata)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    return result, nil, nil
+}
+
+// LoginMFA finishes a two-step login with the mfa_pending token from Login and
+// either a TOTP code or an unused recovery code.
+func (s *authService) LoginMFA(ctx context.Context, req dto.MFALoginRequest) (*dto.AuthResponse, error) {
+    claims, err := s.jwtUtil.ValidateTypedToken(req.MFAToken, jwt.TokenTypeMFAPending)
+    if err != nil {
+        s.logger.Warn(ctx, "MFA login with invalid token")
+        return nil, errors.ErrInvalidToken
+    }
+
+    rawUserID, _ := claims["user_id"].(string)
+    userID, err := uuid.Parse(rawUserID)
+    if err != nil {
+        return nil, errors.ErrInvalidToken
+    }
+
+    user, err := s.authRepo.GetUserWithPassword(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.checkNotDisabled(ctx, user); err != nil {
+        return nil, err
+    }
+
+    mfa, err := s.mfaRepo.Get(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+    if !mfa.EnabledAt.Valid {
+        return nil, errors.ErrMFANotEnabled
+    }
+
+    if err := s.checkLoginAllowed(ctx, user.Email); err != nil {
+        return nil, err
+    }
+
+    method, err := s.verifySecondFactor(ctx, mfa, req.Code)
+    if err != nil {
+        if err == errors.ErrInvalidMFACode {
+            s.logger.Warn(ctx, "Login attempt with invalid MFA code", logger.Fields{
+                "user_id": userID,
+            })
+            s.audit.Record(ctx, auditService.Event{
+                UserID:   &userID,
+                Action:   auditService.ActionLoginFailure,
+                Metadata: map[string]interface{}{"email": user.Email, "reason": "invalid_mfa_code"},
+            })
+            if lockErr := s.recordLoginFailure(ctx, user.Email, &userID); lockErr != nil {
+                return nil, lockErr
+            }
+        }
+        return nil, err
+    }
+
+    return s.completeLogin(ctx, user, map[string]interface{}{"mfa": method})
+}
+
+// completeLogin issues tokens for an authenticated user and records the login.
+func (s *authService) completeLogin(ctx context.Context, user *repository.User, metadata map[string]interface{}) (*dto.AuthResponse, error) {
+    result, err := s.issueTokens(ctx, toUserData(user), uuid.New(), uuid.NullUUID{})
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.guards.Account.Reset(ctx, loginKey(user.Email)); err != nil {
+        s.logger.Error(ctx, "Failed to reset failed login counter", err, logger.Fields{
             "user_id": user.ID,
         })
-        return nil, errors.WrapInternalError(err, "failed to generate token")
     }
 
     s.logger.Info(ctx, "User login successful", logger.Fields{
@@ -83,11 +418,105 @@ func (s *authService) Login(ctx context.Context, req dto.LoginRequest) (*dto.Aut
         "email":   user.Email,
     })
 
-    return &dto.AuthResponse{
-        Token:     token,
-        User:      userData,
-        ExpiresAt: expiresAt,
-    }, nil
+    s.audit.Record(ctx, auditService.Event{
+        UserID:       &user.ID,
+        Action:       auditService.ActionLoginSuccess,
+        ResourceType: "user",
+        ResourceID:   user.ID.String(),
+        Metadata:     metadata,
+    })
+
+    return result, nil
+}
+
+// checkLoginAllowed rejects the attempt while the account or the client IP is
+// locked out.
+func (s *authService) checkLoginAllowed(ctx context.Context, email string) error {
+    if ip := requestinfo.FromContext(ctx).IP; ip != "" {
+        retryAfter, err := s.guards.IP.Check(ctx, ip)
+        if err != nil {
+            s.logger.Error(ctx, "Failed to check login attempts", err, logger.Fields{
+                "ip": ip,
+            })
+            return errors.WrapInternalError(err, "failed to check login attempts")
+        }
+        if retryAfter > 0 {
+            s.logger.Warn(ctx, "Login attempt from throttled IP", logger.Fields{
+                "ip": ip,
+            })
+            return errors.ErrTooManyLoginAttempts.WithRetryAfter(retryAfter)
+        }
+    }
+
+    retryAfter, err := s.guards.Account.Check(ctx, loginKey(email))
+    if err != nil {
+        s.logger.Error(ctx, "Failed to check login attempts", err, logger.Fields{
+            "email": email,
+        })
+        return errors.WrapInternalError(err, "failed to check login attempts")
+    }
+    if retryAfter > 0 {
+        s.logger.Warn(ctx, "Login attempt on locked account", logger.Fields{
+            "email": email,
+        })
+        return errors.ErrAccountLocked.WithRetryAfter(retryAfter)
+    }
+
+    return nil
+}
+
+// recordLoginFailure counts a failed attempt against the account and the
+// client IP. It returns the lockout error when the failure triggered a
+// lockout, or nil so the caller reports the original failure.
+func (s *authService) recordLoginFailure(ctx context.Context, email string, userID *uuid.UUID) error {
+    var lockErr error
+
+    if ip := requestinfo.FromContext(ctx).IP; ip != "" {
+        lockedFor, err := s.guards.IP.Fail(ctx, ip)
+        if err != nil {
+            s.logger.Error(ctx, "Failed to record failed login", err, logger.Fields{
+                "ip": ip,
+            })
+        } else if lockedFor > 0 {
+            s.recordLockout(ctx, "ip", email, userID, lockedFor)
+            lockErr = errors.ErrTooManyLoginAttempts.WithRetryAfter(lockedFor)
+        }
+    }
+
+    lockedFor, err := s.guards.Account.Fail(ctx, loginKey(email))
+    if err != nil {
+        s.logger.Error(ctx, "Failed to record failed login", err, logger.Fields{
+            "email": email,
+        })
+    } else if lockedFor > 0 {
+        s.recordLockout(ctx, "account", email, userID, lockedFor)
+        lockErr = errors.ErrAccountLocked.WithRetryAfter(lockedFor)
+    }
+
+    return lockErr
+}
+
+func (s *authService) recordLockout(ctx context.Context, scope, email string, userID *uuid.UUID, lockedFor time.Duration) {
+    s.logger.Warn(ctx, "Login locked after repeated failures", logger.Fields{
+        "scope":      scope,
+        "email":      email,
+        "user_id":    userID,
+        "locked_for": lockedFor.String(),
+    })
+
+    s.audit.Record(ctx, auditService.Event{
+        UserID: userID,
+        Action: auditService.ActionLoginLockout,
+        Metadata: map[string]interface{}{
+            "scope":              scope,
+            "email":              email,
+            "locked_for_seconds": int(lockedFor.Seconds()),
+        },
+    })
+}
+
+func loginKey(email string) string {
+    return strings.ToLower(strings.TrimSpace(email))
 }
 
 func (s *authService) Register(ctx context.Context, req dto.RegisterRequest) (*dto.AuthResponse, error) {
@@ -96,6 +525,78 @@ func (s *authService) Register(ctx context.Context, req dto.RegisterRequest) (*d
         "email": req.Email,
     })
 
+    userData, err := s.createUser(ctx, req)
+    if err != nil {
+        return nil, err
+    }
+
+    result, err := s.issueTokens(ctx, *userData, uuid.New(), uuid.NullUUID{})
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.sendVerification(ctx, *userData, userData.Email); err != nil && err != errors.ErrVerificationCooldown {
+        s.logger.Error(ctx, "Failed to send verification email after registration", err, logger.Fields{
+            "user_id": userData.ID,
+        })
+    }
+
+    s.logger.Info(ctx, "User registration successful", logger.Fields{
+        "user_id": userData.ID,
+        "email":   userData.Email,
+    })
+
+    s.audit.Record(ctx, auditService.Event{
+        UserID:       &userData.ID,
+        Action:       auditService.ActionRegister,
+        ResourceType: "user",
+        ResourceID:   userData.ID.String(),
+        Changes: auditService.Diff(nil, map[string]interface{}{
+            "name":  userData.Name,
+            "email": userData.Email,
+        }),
+    })
+
+    return result, nil
+}
+
+// CreateUser creates an account with a verified email without signing it in
+// or sending any email. It is meant for operators, such as the command line.
+func (s *authService) CreateUser(ctx context.Context, req dto.RegisterRequest) (*dto.UserData, error) {
+    userData, err := s.createUser(ctx, req)
+    if err != nil {
+        return nil, err
+    }
+
+    if _, err := s.authRepo.MarkEmailVerified(ctx, userData.ID, userData.Email); err != nil {
+        return nil, err
+    }
+
+    s.logger.Info(ctx, "User created", logger.Fields{
+        "user_id": userData.ID,
+        "email":   userData.Email,
+    })
+
+    s.audit.Record(ctx, auditService.Event{
+        Action:       auditService.ActionRegister,
+        ResourceType: "user",
+        ResourceID:   userData.ID.String(),
+        Changes: auditService.Diff(nil, map[string]interface{}{
+            "name":  userData.Name,
+            "email": userData.Email,
+        }),
+        Metadata: map[string]interface{}{"source": "operator"},
+    })
+
+    return userData, nil
+}
+
+// createUser checks the password policy and stores the new user.
+func (s *authService) createUser(ctx context.Context, req dto.RegisterRequest) (*dto.UserData, error) {
+    if err := s.checkPasswordPolicy(ctx, "password", req.Password, req.Email, req.Name); err != nil {
+        return nil, err
+    }
+
     hashedPassword, err := s.passUtil.HashPassword(req.Password)
     if err != nil {
         s.logger.Error(ctx, "Failed to hash password during registration", err, logger.Fields{
@@ -119,22 +620,1077 @@ func (s *authService) Register(ctx context.Context, req dto.RegisterRequest) (*d
         return nil, err
     }
 
-    token, expiresAt, err := s.jwtUtil.GenerateToken(userData.ID)
+    return userData, nil
+}
+
+// Refresh rotates a refresh token: the presented token is consumed and a new
+// access/refresh pair in the same family is returned. Presenting a token that
+// was already consumed revokes the whole family, since either the client or an
+// attacker is replaying a stolen token.
+func (s *authService) Refresh(ctx context.Context, req dto.RefreshRequest) (*dto.AuthResponse, error) {
+    current, err := s.refreshRepo.GetByHash(ctx, token.Hash(req.RefreshToken))
     if err != nil {
-        s.logger.Error(ctx, "Failed to generate JWT token during registration", err, logger.Fields{
-            "user_id": userData.ID,
+        if err == errors.ErrInvalidToken {
+            s.logger.Warn(ctx, "Refresh attempt with unknown token")
+        }
+        return nil, err
+    }
+
+    if current.RevokedAt.Valid {
+        s.logger.Warn(ctx, "Refresh attempt with revoked token", logger.Fields{
+            "user_id":   current.UserID,
+            "family_id": current.FamilyID,
         })
-        return nil, errors.WrapInternalError(err, "failed to generate token")
+        return nil, errors.ErrInvalidToken
     }
 
-    s.logger.Info(ctx, "User registration successful", logger.Fields{
-        "user_id": userData.ID,
-        "email":   userData.Email,
+    if current.UsedAt.Valid {
+        return nil, s.handleRefreshReuse(ctx, current)
+    }
+
+    if time.Now().UTC().After(current.ExpiresAt) {
+        return nil, errors.ErrInvalidToken
+    }
+
+    consumed, err := s.refreshRepo.MarkUsed(ctx, current.ID)
+    if err != nil {
+        return nil, err
+    }
+    if !consumed {
+        // Another request consumed the token between our read and update.
+        return nil, s.handleRefreshReuse(ctx, current)
+    }
+
+    user, err := s.authRepo.GetUserWithPassword(ctx, current.UserID)
+    if err != nil {
+        return nil, err
+    }
+
+    if user.DisabledAt.Valid {
+        s.logger.Warn(ctx, "Refresh attempt for disabled account", logger.Fields{
+            "user_id": current.UserID,
+        })
+        return nil, errors.ErrAccountDisabled
+    }
+
+    result, err := s.issueTokens(ctx, toUserData(user), current.FamilyID, uuid.NullUUID{UUID: current.ID, Valid: true})
+    if err != nil {
+        return nil, err
+    }
+
+    s.audit.Record(ctx, auditService.Event{
+        UserID:       &current.UserID,
+        Action:       auditService.ActionTokenRefresh,
+        ResourceType: "refresh_token_family",
+        ResourceID:   current.FamilyID.String(),
     })
 
-    return &dto.AuthResponse{
-        Token:     token,
-        User:      *userData,
-        ExpiresAt: expiresAt,
-    }, nil
-}
\ No newline at end of file
+    return result, nil
+}
+
+// Logout revokes the presented access token and ends its session. Tokens
+// issued before sessions existed carry no session ID; for those the refresh
+// token family is only revoked when the refresh token is given.
+func (s *authService) Logout(ctx context.Context, userID uuid.UUID, jti string, expiresAt time.Time, sessionID uuid.UUID, req dto.LogoutRequest) error {
+    if err := s.revocation.Revoke(ctx, jti, userID, expiresAt); err != nil {
+        s.logger.Error(ctx, "Failed to revoke access token", err, logger.Fields{
+            "user_id": userID,
+        })
+        return errors.WrapInternalError(err, "failed to revoke token")
+    }
+
+    if sessionID != uuid.Nil {
+        if err := s.refreshRepo.RevokeFamily(ctx, sessionID); err != nil {
+            return err
+        }
+    }
+
+    if req.RefreshToken != "" {
+        current, err := s.refreshRepo.GetByHash(ctx, token.Hash(req.RefreshToken))
+        if err != nil && err != errors.ErrInvalidToken {
+            return err
+        }
+        if current != nil && current.UserID == userID {
+            if err := s.refreshRepo.RevokeFamily(ctx, current.FamilyID); err != nil {
+                return err
+            }
+        }
+    }
+
+    s.logger.Info(ctx, "User logged out", logger.Fields{
+        "user_id": userID,
+    })
+
+    s.audit.Record(ctx, auditService.Event{
+        UserID:       &userID,
+        Action:       auditService.ActionLogout,
+        ResourceType: "user",
+        ResourceID:   userID.String(),
+    })
+
+    return nil
+}
+
+// LogoutAll revokes every access and refresh token the user currently holds.
+func (s *authService) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+    if err := s.RevokeAllSessions(ctx, userID); err != nil {
+        return err
+    }
+
+    s.logger.Info(ctx, "User logged out from all sessions", logger.Fields{
+        "user_id": userID,
+    })
+
+    s.audit.Record(ctx, auditService.Event{
+        UserID:       &userID,
+        Action:       auditService.ActionLogoutAll,
+        ResourceType: "user",
+        ResourceID:   userID.String(),
+    })
+
+    return nil
+}
+
+func (s *authService) GetProfile(ctx context.Context, userID uuid.UUID) (*dto.UserData, error) {
+    user, err := s.authRepo.GetUserByID(ctx, userID)
+    if err != nil {
+        if err != errors.ErrUserNotFound {
+            s.logger.Error(ctx, "Failed to get user profile", err, logger.Fields{
+                "user_id": userID,
+            })
+        }
+        return nil, err
+    }
+
+    return user, nil
+}
+
+// UpdateProfile changes the name right away. A new email needs the current
+// password and is only switched to once the link mailed to it is verified;
+// the old address is told about the request in case the account was taken
+// over.
+func (s *authService) UpdateProfile(ctx context.Context, userID uuid.UUID, req dto.UpdateProfileRequest) (*dto.UserData, error) {
+    before, err := s.authRepo.GetUserByID(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+
+    emailChange := req.Email != nil && *req.Email != before.Email
+    if emailChange {
+        user, err := s.authRepo.GetUserWithPassword(ctx, userID)
+        if err != nil {
+            return nil, err
+        }
+        if !s.passUtil.CheckPassword(req.CurrentPassword, user.Password) {
+            s.logger.Warn(ctx, "Email change with invalid current password", logger.Fields{
+                "user_id": userID,
+            })
+            return nil, errors.ErrInvalidCredentials
+        }
+
+        if _, err := s.authRepo.GetUserByEmail(ctx, *req.Email); err == nil {
+            s.logger.Warn(ctx, "Profile update with existing email", logger.Fields{
+                "user_id": userID,
+            })
+            return nil, errors.ErrUserAlreadyExists
+        } else if err != errors.ErrUserNotFound {
+            return nil, err
+        }
+    }
+
+    user, err := s.authRepo.UpdateProfile(ctx, userID, req)
+    if err != nil {
+        if err == errors.ErrUserAlreadyExists {
+            s.logger.Warn(ctx, "Profile update with existing email", logger.Fields{
+                "user_id": userID,
+            })
+        } else {
+            s.logger.Error(ctx, "Failed to update user profile", err, logger.Fields{
+                "user_id": userID,
+            })
+        }
+        return nil, err
+    }
+
+    s.logger.Info(ctx, "User profile updated", logger.Fields{
+        "user_id": userID,
+    })
+
+    s.audit.Record(ctx, auditService.Event{
+        UserID:       &userID,
+        Action:       auditService.ActionProfileUpdate,
+        ResourceType: "user",
+        ResourceID:   userID.String(),
+        Changes:      auditService.Diff(before, user),
+    })
+
+    if emailChange {
+        if err := s.sendVerification(ctx, *user, *req.Email); err != nil && err != errors.ErrVerificationCooldown {
+            s.logger.Error(ctx, "Failed to send verification email after email change", err, logger.Fields{
+                "user_id": userID,
+            })
+        }
+        s.sendEmailChangeNotice(ctx, *user, *req.Email)
+    }
+
+    return user, nil
+}
+
+// sendEmailChangeNotice tells the current address that a change to newEmail
+// was requested.
+func (s *authService) sendEmailChangeNotice(ctx context.Context, user dto.UserData, newEmail string) {
+    msg := mailer.Message{
+        To:      user.Email,
+        Subject: "Your email address is being changed",
+        Body: fmt.Sprintf(
+            "Hi %s,\n\nA request was made to change the email address of your account to %s. The change takes effect once the new address is confirmed.\n\nIf you did not request this, change your password right away.",
+            user.Name, newEmail,
+        ),
+    }
+
+    s.runInBackground(ctx, "email_change_notice", func(ctx context.Context) {
+        if err := s.mailer.Send(ctx, msg); err != nil {
+            s.logger.Error(ctx, "Failed to send email change notice", err, logger.Fields{
+                "user_id": user.ID,
+            })
+        }
+    })
+}
+
+// ChangePassword replaces the password after checking the current one. Every
+// existing session is revoked and a fresh token pair is returned so that only
+// the caller stays signed in.
+func (s *authService) ChangePassword(ctx context.Context, userID uuid.UUID, req dto.ChangePasswordRequest) (*dto.AuthResponse, error) {
+    user, err := s.authRepo.GetUserWithPassword(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+
+    if !s.passUtil.CheckPassword(req.CurrentPassword, user.Password) {
+        s.logger.Warn(ctx, "Password change with invalid current password", logger.Fields{
+            "user_id": userID,
+        })
+        return nil, errors.ErrInvalidCredentials
+    }
+
+    if err := s.checkPasswordPolicy(ctx, "new_password", req.NewPassword, user.Email, user.Name); err != nil {
+        return nil, err
+    }
+
+    hashedPassword, err := s.passUtil.HashPassword(req.NewPassword)
+    if err != nil {
+        s.logger.Error(ctx, "Failed to hash password during password change", err, logger.Fields{
+            "user_id": userID,
+        })
+        return nil, errors.WrapInternalError(err, "failed to process password")
+    }
+
+    if err := s.authRepo.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+        s.logger.Error(ctx, "Failed to update password", err, logger.Fields{
+            "user_id": userID,
+        })
+        return nil, err
+    }
+
+    if err := s.RevokeAllSessions(ctx, userID); err != nil {
+        return nil, err
+    }
+
+    result, err := s.issueTokens(ctx, toUserData(user), uuid.New(), uuid.NullUUID{})
+    if err != nil {
+        return nil, err
+    }
+
+    s.logger.Info(ctx, "User password changed", logger.Fields{
+        "user_id": userID,
+    })
+
+    s.audit.Record(ctx, auditService.Event{
+        UserID:       &userID,
+        Action:       auditService.ActionPasswordChange,
+        ResourceType: "user",
+        ResourceID:   userID.String(),
+    })
+
+    return result, nil
+}
+
+// ForgotPassword mails a single-use reset link when the email belongs to an
+// account and no link was sent to it within the resend cooldown. It succeeds
+// either way so the endpoint cannot be used to find out which emails are
+// registered.
+func (s *authService) ForgotPassword(ctx context.Context, req dto.ForgotPasswordRequest) error {
+    user, err := s.authRepo.GetUserByEmail(ctx, req.Email)
+    if err != nil {
+        if err == errors.ErrUserNotFound {
+            s.logger.Info(ctx, "Password reset requested for non-existent email", logger.Fields{
+                "email": req.Email,
+            })
+            return nil
+        }
+        s.logger.Error(ctx, "Failed to get user during password reset request", err, logger.Fields{
+            "email": req.Email,
+        })
+        return err
+    }
+
+    // Issue the token and deliver it in the background, so neither the
+    // response time nor the status code depends on whether the account exists.
+    s.runInBackground(ctx, "password_reset", func(ctx context.Context) {
+        s.issuePasswordReset(ctx, user)
+    })
+
+    return nil
+}
+
+// issuePasswordReset replaces the user's reset token and mails the link,
+// unless one was sent within the resend cooldown. Failures are logged, the
+// caller has already answered the request.
+func (s *authService) issuePasswordReset(ctx context.Context, user *repository.User) {
+    reserved, err := s.authRepo.ReservePasswordResetEmail(ctx, user.ID, s.config.PasswordReset.ResendCooldown)
+    if err != nil {
+        s.logger.Error(ctx, "Failed to record password reset email", err, logger.Fields{
+            "user_id": user.ID,
+        })
+        return
+    }
+    if !reserved {
+        s.logger.Info(ctx, "Password reset requested during cooldown", logger.Fields{
+            "user_id": user.ID,
+        })
+        return
+    }
+
+    resetToken, err := token.Generate(32)
+    if err != nil {
+        s.logger.Error(ctx, "Failed to generate password reset token", err, logger.Fields{
+            "user_id": user.ID,
+        })
+        return
+    }
+
+    // Only the most recent link stays valid.
+    if err := s.resetRepo.InvalidateForUser(ctx, user.ID); err != nil {
+        s.logger.Error(ctx, "Failed to invalidate password reset tokens", err, logger.Fields{
+            "user_id": user.ID,
+        })
+        return
+    }
+
+    expiresAt := time.Now().UTC().Add(s.config.PasswordReset.TokenExp)
+    if err := s.resetRepo.Create(ctx, user.ID, token.Hash(resetToken), expiresAt); err != nil {
+        s.logger.Error(ctx, "Failed to store password reset token", err, logger.Fields{
+            "user_id": user.ID,
+        })
+        return
+    }
+
+    s.audit.Record(ctx, auditService.Event{
+        UserID:       &user.ID,
+        Action:       auditService.ActionPasswordResetRequest,
+        ResourceType: "user",
+        ResourceID:   user.ID.String(),
+    })
+
+    s.sendPasswordResetMail(ctx, user, resetToken)
+}
+
+func (s *authService) sendPasswordResetMail(ctx context.Context, user *repository.User, resetToken string) {
+    link, err := tokenLink(s.config.PasswordReset.URL, resetToken)
+    if err != nil {
+        s.logger.Error(ctx, "Invalid password reset URL", err)
+        return
+    }
+
+    err = s.mailer.Send(ctx, mailer.Message{
+        To:      user.Email,
+        Subject: "Reset your password",
+        Body: fmt.Sprintf(
+            "Hi %s,\n\nUse the link below to choose a new password. It expires in %d minutes and can only be used once.\n\n%s\n\nIf you did not request a password reset, you can ignore this email.",
+            user.Name, int(s.config.PasswordReset.TokenExp.Minutes()), link,
+        ),
+    })
+    if err != nil {
+        s.logger.Error(ctx, "Failed to send password reset email", err, logger.Fields{
+            "user_id": user.ID,
+        })
+    }
+}
+
+// ResetPassword sets a new password using a token from ForgotPassword and
+// signs the user out everywhere.
+func (s *authService) ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) error {
+    // The policy is checked before the token is consumed so that a rejected
+    // password does not burn the link
+    userID, err := s.resetRepo.Lookup(ctx, token.Hash(req.Token))
+    if err != nil {
+        if err == errors.ErrInvalidResetToken {
+            s.logger.Warn(ctx, "Password reset with invalid token")
+        }
+        return err
+    }
+
+    user, err := s.authRepo.GetUserWithPassword(ctx, userID)
+    if err != nil {
+        return err
+    }
+
+    if err := s.checkPasswordPolicy(ctx, "new_password", req.NewPassword, user.Email, user.Name); err != nil {
+        return err
+    }
+
+    if userID, err = s.resetRepo.Consume(ctx, token.Hash(req.Token)); err != nil {
+        return err
+    }
+
+    hashedPassword, err := s.passUtil.HashPassword(req.NewPassword)
+    if err != nil {
+        s.logger.Error(ctx, "Failed to hash password during password reset", err, logger.Fields{
+            "user_id": userID,
+        })
+        return errors.WrapInternalError(err, "failed to process password")
+    }
+
+    if err := s.authRepo.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+        s.logger.Error(ctx, "Failed to update password during password reset", err, logger.Fields{
+            "user_id": userID,
+        })
+        return err
+    }
+
+    if err := s.resetRepo.InvalidateForUser(ctx, userID); err != nil {
+        return err
+    }
+
+    if err := s.RevokeAllSessions(ctx, userID); err != nil {
+        return err
+    }
+
+    s.logger.Info(ctx, "User password reset", logger.Fields{
+        "user_id": userID,
+    })
+
+    s.audit.Record(ctx, auditService.Event{
+        UserID:       &userID,
+        Action:       auditService.ActionPasswordReset,
+        ResourceType: "user",
+        ResourceID:   userID.String(),
+    })
+
+    return nil
+}
+
+// VerifyEmail marks the address in a verification link as verified, switching
+// to it when it is the user's pending email. Links for an address the user has
+// since changed are rejected.
+func (s *authService) VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) error {
+    claims, err := s.jwtUtil.ValidateTypedToken(req.Token, jwt.TokenTypeEmailVerification)
+    if err != nil {
+        s.logger.Warn(ctx, "Email verification with invalid token")
+        return errors.ErrInvalidVerificationToken
+    }
+
+    rawUserID, _ := claims["user_id"].(string)
+    email, _ := claims["email"].(string)
+    userID, err := uuid.Parse(rawUserID)
+    if err != nil || email == "" {
+        return errors.ErrInvalidVerificationToken
+    }
+
+    before, err := s.authRepo.GetUserByID(ctx, userID)
+    if err != nil {
+        if err == errors.ErrUserNotFound {
+            return errors.ErrInvalidVerificationToken
+        }
+        return err
+    }
+
+    verified, err := s.authRepo.MarkEmailVerified(ctx, userID, email)
+    if err != nil {
+        if err == errors.ErrUserAlreadyExists {
+            s.logger.Warn(ctx, "Email change verified for an address taken since", logger.Fields{
+                "user_id": userID,
+            })
+        } else {
+            s.logger.Error(ctx, "Failed to verify email", err, logger.Fields{
+                "user_id": userID,
+            })
+        }
+        return err
+    }
+    if !verified {
+        s.logger.Warn(ctx, "Email verification for outdated address", logger.Fields{
+            "user_id": userID,
+        })
+        return errors.ErrInvalidVerificationToken
+    }
+
+    s.logger.Info(ctx, "User email verified", logger.Fields{
+        "user_id": userID,
+    })
+
+    s.audit.Record(ctx, auditService.Event{
+        UserID:       &userID,
+        Action:       auditService.ActionEmailVerify,
+        ResourceType: "user",
+        ResourceID:   userID.String(),
+        Metadata:     map[string]interface{}{"email": email},
+    })
+
+    if email != before.Email {
+        s.logger.Info(ctx, "User email changed", logger.Fields{
+            "user_id": userID,
+        })
+
+        s.audit.Record(ctx, auditService.Event{
+            UserID:       &userID,
+            Action:       auditService.ActionEmailChange,
+            ResourceType: "user",
+            ResourceID:   userID.String(),
+            Changes: map[string]auditService.FieldChange{
+                "email": {Old: before.Email, New: email},
+            },
+        })
+    }
+
+    return nil
+}
+
+// ResendVerification mails a new link for the pending email, or for the
+// current one while it is unverified.
+func (s *authService) ResendVerification(ctx context.Context, userID uuid.UUID) error {
+    user, err := s.authRepo.GetUserByID(ctx, userID)
+    if err != nil {
+        return err
+    }
+
+    email := user.Email
+    if user.PendingEmail != nil {
+        email = *user.PendingEmail
+    } else if user.EmailVerified {
+        return errors.ErrEmailAlreadyVerified
+    }
+
+    if err := s.sendVerification(ctx, *user, email); err != nil {
+        if err == errors.ErrVerificationCooldown {
+            s.logger.Warn(ctx, "Verification email requested during cooldown", logger.Fields{
+                "user_id": userID,
+            })
+        }
+        return err
+    }
+
+    return nil
+}
+
+func (s *authService) IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error) {
+    user, err := s.authRepo.GetUserByID(ctx, userID)
+    if err != nil {
+        return false, err
+    }
+
+    return user.EmailVerified, nil
+}
+
+// sendVerification mails a signed verification link for email, the user's
+// current or pending address, unless one was sent within the resend cooldown.
+func (s *authService) sendVerification(ctx context.Context, user dto.UserData, email string) error {
+    reserved, err := s.authRepo.ReserveVerificationEmail(ctx, user.ID, s.config.EmailVerification.ResendCooldown)
+    if err != nil {
+        return err
+    }
+    if !reserved {
+        return errors.ErrVerificationCooldown
+    }
+
+    verificationToken, _, err := s.jwtUtil.GenerateTypedToken(
+        user.ID, jwt.TokenTypeEmailVerification, s.config.EmailVerification.TokenExp, map[string]interface{}{"email": email},
+    )
+    if err != nil {
+        return errors.WrapInternalError(err, "failed to generate verification token")
+    }
+
+    link, err := tokenLink(s.config.EmailVerification.URL, verificationToken)
+    if err != nil {
+        return errors.WrapInternalError(err, "invalid email verification URL")
+    }
+
+    msg := mailer.Message{
+        To:      email,
+        Subject: "Verify your email address",
+        Body: fmt.Sprintf(
+            "Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in %d hours.\n\n%s",
+            user.Name, int(s.config.EmailVerification.TokenExp.Hours()), link,
+        ),
+    }
+
+    s.runInBackground(ctx, "verification_email", func(ctx context.Context) {
+        if err := s.mailer.Send(ctx, msg); err != nil {
+            s.logger.Error(ctx, "Failed to send verification email", err, logger.Fields{
+                "user_id": user.ID,
+            })
+        }
+    })
+
+    return nil
+}
+
+// runInBackground queues job on the worker pool with a context that outlives
+// the request. When the queue is full the job is dropped and logged.
+func (s *authService) runInBackground(ctx context.Context, name string, job func(ctx context.Context)) {
+    ctx = context.WithoutCancel(ctx)
+    if !s.jobs.Submit(func() { job(ctx) }) {
+        s.logger.Warn(ctx, "Background job dropped, the queue is full", logger.Fields{
+            "job": name,
+        })
+    }
+}
+
+// SetupMFA starts enrollment with a new secret. Two-factor authentication is
+// not enforced until the secret is confirmed with EnableMFA.
+func (s *authService) SetupMFA(ctx context.Context, userID uuid.UUID) (*dto.MFASetupResponse, error) {
+    user, err := s.authRepo.GetUserByID(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+
+    secret, err := totp.GenerateSecret()
+    if err != nil {
+        return nil, errors.WrapInternalError(err, "failed to generate MFA secret")
+    }
+
+    encrypted, err := s.secretBox.Seal([]byte(secret))
+    if err != nil {
+        return nil, errors.WrapInternalError(err, "failed to encrypt MFA secret")
+    }
+
+    if err := s.mfaRepo.SavePending(ctx, userID, encrypted); err != nil {
+        if err != errors.ErrMFAAlreadyEnabled {
+            s.logger.Error(ctx, "Failed to save MFA secret", err, logger.Fields{
+                "user_id": userID,
+            })
+        }
+        return nil, err
+    }
+
+    return &dto.MFASetupResponse{
+        Secret:          secret,
+        ProvisioningURI: totp.ProvisioningURI(s.config.MFA.Issuer, user.Email, secret),
+    }, nil
+}
+
+// EnableMFA confirms enrollment with a code from the authenticator app and
+// returns the recovery codes. They are only shown once.
+func (s *authService) EnableMFA(ctx context.Context, userID uuid.UUID, req dto.MFACodeRequest) (*dto.MFARecoveryCodesResponse, error) {
+    mfa, err := s.mfaRepo.Get(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+    if mfa.EnabledAt.Valid {
+        return nil, errors.ErrMFAAlreadyEnabled
+    }
+
+    secret, err := s.secretBox.Open(mfa.SecretEncrypted)
+    if err != nil {
+        return nil, errors.WrapInternalError(err, "failed to decrypt MFA secret")
+    }
+
+    step, ok := totp.Validate(string(secret), strings.TrimSpace(req.Code), time.Now(), totpSkew)
+    if !ok {
+        s.logger.Warn(ctx, "MFA enrollment with invalid code", logger.Fields{
+            "user_id": userID,
+        })
+        return nil, errors.ErrInvalidMFACode
+    }
+
+    codes, hashes, err := generateRecoveryCodes()
+    if err != nil {
+        return nil, errors.WrapInternalError(err, "failed to generate recovery codes")
+    }
+
+    if err := s.mfaRepo.Enable(ctx, userID, step, hashes); err != nil {
+        return nil, err
+    }
+
+    s.logger.Info(ctx, "User enabled MFA", logger.Fields{
+        "user_id": userID,
+    })
+
+    s.audit.Record(ctx, auditService.Event{
+        UserID:       &userID,
+        Action:       auditService.ActionMFAEnable,
+        ResourceType: "user",
+        ResourceID:   userID.String(),
+    })
+
+    return &dto.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableMFA turns two-factor authentication off. It requires both the
+// password and a second factor.
+func (s *authService) DisableMFA(ctx context.Context, userID uuid.UUID, req dto.MFADisableRequest) error {
+    user, err := s.authRepo.GetUserWithPassword(ctx, userID)
+    if err != nil {
+        return err
+    }
+
+    if !s.passUtil.CheckPassword(req.Password, user.Password) {
+        s.logger.Warn(ctx, "MFA disable with invalid password", logger.Fields{
+            "user_id": userID,
+        })
+        return errors.ErrInvalidCredentials
+    }
+
+    mfa, err := s.mfaRepo.Get(ctx, userID)
+    if err != nil {
+        return err
+    }
+    if !mfa.EnabledAt.Valid {
+        return errors.ErrMFANotEnabled
+    }
+
+    if _, err := s.verifySecondFactor(ctx, mfa, req.Code); err != nil {
+        return err
+    }
+
+    if err := s.mfaRepo.Delete(ctx, userID); err != nil {
+        return err
+    }
+
+    s.logger.Info(ctx, "User disabled MFA", logger.Fields{
+        "user_id": userID,
+    })
+
+    s.audit.Record(ctx, auditService.Event{
+        UserID:       &userID,
+        Action:       auditService.ActionMFADisable,
+        ResourceType: "user",
+        ResourceID:   userID.String(),
+    })
+
+    return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes, used or not.
+func (s *authService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, req dto.MFACodeRequest) (*dto.MFARecoveryCodesResponse, error) {
+    mfa, err := s.mfaRepo.Get(ctx, userID)
+    if err != nil {
+        return nil, err
+    }
+    if !mfa.EnabledAt.Valid {
+        return nil, errors.ErrMFANotEnabled
+    }
+
+    if _, err := s.verifySecondFactor(ctx, mfa, req.Code); err != nil {
+        return nil, err
+    }
+
+    codes, hashes, err := generateRecoveryCodes()
+    if err != nil {
+        return nil, errors.WrapInternalError(err, "failed to generate recovery codes")
+    }
+
+    if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+        return nil, err
+    }
+
+    s.audit.Record(ctx, auditService.Event{
+        UserID:       &userID,
+        Action:       auditService.ActionMFARecoveryCodes,
+        ResourceType: "user",
+        ResourceID:   userID.String(),
+    })
+
+    return &dto.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// verifySecondFactor accepts a TOTP code or an unused recovery code and
+// returns which one was used. Each TOTP step and recovery code works only once.
+func (s *authService) verifySecondFactor(ctx context.Context, mfa *repository.UserMFA, code string) (string, error) {
+    code = strings.TrimSpace(code)
+
+    if len(code) == totp.Digits {
+        secret, err := s.secretBox.Open(mfa.SecretEncrypted)
+        if err != nil {
+            return "", errors.WrapInternalError(err, "failed to decrypt MFA secret")
+        }
+
+        step, ok := totp.Validate(string(secret), code, time.Now(), totpSkew)
+        if !ok {
+            return "", errors.ErrInvalidMFACode
+        }
+
+        consumed, err := s.mfaRepo.ConsumeStep(ctx, mfa.UserID, step)
+        if err != nil {
+            return "", err
+        }
+        if !consumed {
+            return "", errors.ErrInvalidMFACode
+        }
+
+        return "totp", nil
+    }
+
+    consumed, err := s.mfaRepo.ConsumeRecoveryCode(ctx, mfa.UserID, token.Hash(normalizeRecoveryCode(code)))
+    if err != nil {
+        return "", err
+    }
+    if !consumed {
+        return "", errors.ErrInvalidMFACode
+    }
+
+    return "recovery_code", nil
+}
+
+// generateRecoveryCodes returns codes formatted as "xxxxx-xxxxx" and the
+// hashes to store for them.
+func generateRecoveryCodes() ([]string, []string, error) {
+    encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+    codes := make([]string, 0, recoveryCodeCount)
+    hashes := make([]string, 0, recoveryCodeCount)
+    for i := 0; i < recoveryCodeCount; i++ {
+        raw := make([]byte, 7)
+        if _, err := rand.Read(raw); err != nil {
+            return nil, nil, err
+        }
+        value := strings.ToLower(encoding.EncodeToString(raw))[:10]
+
+        codes = append(codes, value[:5]+"-"+value[5:])
+        hashes = append(hashes, token.Hash(value))
+    }
+
+    return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+    return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// tokenLink appends token to base as the "token" query parameter.
+func tokenLink(base, token string) (string, error) {
+    link, err := url.Parse(base)
+    if err != nil {
+        return "", err
+    }
+
+    query := link.Query()
+    query.Set("token", token)
+    link.RawQuery = query.Encode()
+
+    return link.String(), nil
+}
+
+// ListSessions returns the user's active sessions, most recently used first.
+// The session of the calling access token is marked as current.
+func (s *authService) ListSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID) (*dto.SessionListResponse, error) {
+    sessions, err := s.sessionRepo.ListActiveByUser(ctx, userID)
+    if err != nil {
+        s.logger.Error(ctx, "Failed to list sessions", err, logger.Fields{
+            "user_id": userID,
+        })
+        return nil, err
+    }
+
+    data := make([]dto.SessionData, 0, len(sessions))
+    for i := range sessions {
+        data = append(data, toSessionData(&sessions[i], currentSessionID))
+    }
+
+    return &dto.SessionListResponse{Sessions: data}, nil
+}
+
+// RevokeSession signs one device out: the refresh token family can no longer
+// be rotated and access tokens carrying the session ID are rejected.
+func (s *authService) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+    session, err := s.sessionRepo.GetActive(ctx, userID, sessionID)
+    if err != nil {
+        return err
+    }
+
+    if err := s.refreshRepo.RevokeFamily(ctx, session.ID); err != nil {
+        s.logger.Error(ctx, "Failed to revoke session refresh tokens", err, logger.Fields{
+            "user_id":    userID,
+            "session_id": session.ID,
+        })
+        return err
+    }
+
+    expiresAt := time.Now().UTC().Add(s.jwtUtil.AccessTokenExp())
+    if err := s.revocation.Revoke(ctx, session.ID.String(), userID, expiresAt); err != nil {
+        s.logger.Error(ctx, "Failed to revoke session access tokens", err, logger.Fields{
+            "user_id":    userID,
+            "session_id": session.ID,
+        })
+        return errors.WrapInternalError(err, "failed to revoke session")
+    }
+
+    s.logger.Info(ctx, "Session revoked", logger.Fields{
+        "user_id":    userID,
+        "session_id": session.ID,
+    })
+
+    s.audit.Record(ctx, auditService.Event{
+        UserID:       &userID,
+        Action:       auditService.ActionSessionRevoke,
+        ResourceType: "session",
+        ResourceID:   session.ID.String(),
+        Metadata:     map[string]interface{}{"device": session.Device},
+    })
+
+    return nil
+}
+
+// RevokeAllSessions invalidates every access and refresh token of the user.
+func (s *authService) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+    // Token iat has second precision, so the cutoff is truncated to keep tokens
+    // issued right after the revocation valid.
+    now := time.Now().UTC().Truncate(time.Second)
+    if err := s.revocation.RevokeAllForUser(ctx, userID, now, now.Add(s.jwtUtil.AccessTokenExp())); err != nil {
+        s.logger.Error(ctx, "Failed to revoke user access tokens", err, logger.Fields{
+            "user_id": userID,
+        })
+        return errors.WrapInternalError(err, "failed to revoke tokens")
+    }
+
+    return s.refreshRepo.RevokeAllForUser(ctx, userID)
+}
+
+// checkNotDisabled rejects logins to accounts disabled by an administrator.
+func (s *authService) checkNotDisabled(ctx context.Context, user *repository.User) error {
+    if !user.DisabledAt.Valid {
+        return nil
+    }
+
+    s.logger.Warn(ctx, "Login attempt for disabled account", logger.Fields{
+        "user_id": user.ID,
+    })
+    s.audit.Record(ctx, auditService.Event{
+        UserID:   &user.ID,
+        Action:   auditService.ActionLoginFailure,
+        Metadata: map[string]interface{}{"email": user.Email, "reason": "account_disabled"},
+    })
+
+    return errors.ErrAccountDisabled
+}
+
+func (s *authService) handleRefreshReuse(ctx context.Context, current *repository.RefreshToken) error {
+    s.logger.Warn(ctx, "Refresh token reuse detected, revoking token family", logger.Fields{
+        "user_id":   current.UserID,
+        "family_id": current.FamilyID,
+    })
+
+    if err := s.refreshRepo.RevokeFamily(ctx, current.FamilyID); err != nil {
+        s.logger.Error(ctx, "Failed to revoke refresh token family", err, logger.Fields{
+            "family_id": current.FamilyID,
+        })
+        return err
+    }
+
+    s.audit.Record(ctx, auditService.Event{
+        UserID:       &current.UserID,
+        Action:       auditService.ActionTokenReuse,
+        ResourceType: "refresh_token_family",
+        ResourceID:   current.FamilyID.String(),
+    })
+
+    return errors.ErrRefreshTokenReused
+}
+
+// issueTokens creates an access token and a refresh token belonging to familyID.
+// A new family starts a session for the calling device; rotating an existing
+// family updates when and from where that session was last seen.
+func (s *authService) issueTokens(ctx context.Context, userData dto.UserData, familyID uuid.UUID, parentID uuid.NullUUID) (*dto.AuthResponse, error) {
+    info := requestinfo.FromContext(ctx)
+
+    if parentID.Valid {
+        // Refreshes without a User-Agent keep the device recorded at login
+        device := ""
+        if info.UserAgent != "" {
+            device = useragent.Describe(info.UserAgent)
+        }
+        if err := s.sessionRepo.Touch(ctx, familyID, info.UserAgent, device, info.IP); err != nil {
+            s.logger.Error(ctx, "Failed to update session", err, logger.Fields{
+                "user_id":    userData.ID,
+                "session_id": familyID,
+            })
+        }
+    } else {
+        err := s.sessionRepo.Create(ctx, &repository.Session{
+            ID:        familyID,
+            UserID:    userData.ID,
+            Device:    useragent.Describe(info.UserAgent),
+            UserAgent: sql.NullString{String: info.UserAgent, Valid: info.UserAgent != ""},
+            IPAddress: sql.NullString{String: info.IP, Valid: info.IP != ""},
+        })
+        if err != nil {
+            s.logger.Error(ctx, "Failed to create session", err, logger.Fields{
+                "user_id": userData.ID,
+            })
+            return nil, err
+        }
+    }
+
+    accessToken, expiresAt, err := s.jwtUtil.GenerateToken(userData.ID, userData.Role, familyID)
+    if err != nil {
+        s.logger.Error(ctx, "Failed to generate JWT token", err, logger.Fields{
+            "user_id": userData.ID,
+        })
+        return nil, errors.WrapInternalError(err, "failed to generate token")
+    }
+
+    refreshToken, err := token.Generate(32)
+    if err != nil {
+        return nil, errors.WrapInternalError(err, "failed to generate refresh token")
+    }
+
+    stored, err := s.refreshRepo.Create(ctx, &repository.RefreshToken{
+        UserID:    userData.ID,
+        FamilyID:  familyID,
+        ParentID:  parentID,
+        TokenHash: token.Hash(refreshToken),
+        ExpiresAt: time.Now().UTC().Add(s.jwtUtil.RefreshTokenExp()),
+    })
+    if err != nil {
+        s.logger.Error(ctx, "Failed to store refresh token", err, logger.Fields{
+            "user_id": userData.ID,
+        })
+        return nil, err
+    }
+
+    return &dto.AuthResponse{
+        Token:            accessToken,
+        User:             userData,
+        ExpiresAt:        expiresAt,
+        RefreshToken:     refreshToken,
+        RefreshExpiresAt: stored.ExpiresAt,
+    }, nil
+}
+
+func toUserData(user *repository.User) dto.UserData {
+    return dto.UserData{
+        ID:            user.ID,
+        Name:          user.Name,
+        Email:         user.Email,
+        Role:          user.Role,
+        EmailVerified: user.VerifiedAt.Valid,
+        CreatedAt:     user.CreatedAt,
+    }
+}
+
+func toSessionData(session *repository.Session, currentSessionID uuid.UUID) dto.SessionData {
+    data := dto.SessionData{
+        ID:         session.ID,
+        Device:     session.Device,
+        CreatedAt:  session.CreatedAt,
+        LastSeenAt: session.LastSeenAt,
+        Current:    session.ID == currentSessionID,
+    }
+    if session.UserAgent.Valid {
+        data.UserAgent = &session.UserAgent.String
+    }
+    if session.IPAddress.Valid {
+        data.IPAddress = &session.IPAddress.String
+    }
+    return data
+}
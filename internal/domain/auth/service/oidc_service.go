@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	auditService "devsecops-be/internal/domain/audit/service"
+	"devsecops-be/internal/domain/auth/dto"
+	"devsecops-be/internal/domain/auth/repository"
+	"devsecops-be/pkg/errors"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/oidc"
+	"devsecops-be/pkg/token"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StartOIDCLogin begins a login with an identity provider. The state, nonce
+// and PKCE verifier are kept server side until the callback.
+func (s *authService) StartOIDCLogin(ctx context.Context, provider string) (*dto.OIDCAuthorizeResponse, error) {
+	return s.startOIDCFlow(ctx, provider, uuid.NullUUID{})
+}
+
+// OIDCLogin finishes a login started with StartOIDCLogin. A provider account
+// seen before signs into its linked user. Otherwise the verified email decides:
+// it is linked to an existing account with the same verified email, or a new
+// account is created.
+func (s *authService) OIDCLogin(ctx context.Context, provider string, req dto.OIDCCallbackRequest) (*dto.AuthResponse, *dto.MFAChallengeResponse, error) {
+	identity, state, err := s.finishOIDCFlow(ctx, provider, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if state.UserID.Valid {
+		// A linking request must be finished through LinkIdentity
+		return nil, nil, errors.ErrInvalidOIDCState
+	}
+
+	user, err := s.resolveOIDCUser(ctx, identity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.checkNotDisabled(ctx, user); err != nil {
+		return nil, nil, err
+	}
+
+	return s.beginLogin(ctx, user, map[string]interface{}{"provider": identity.Provider})
+}
+
+// StartIdentityLink begins linking a provider account to a signed-in user.
+func (s *authService) StartIdentityLink(ctx context.Context, userID uuid.UUID, provider string) (*dto.OIDCAuthorizeResponse, error) {
+	return s.startOIDCFlow(ctx, provider, uuid.NullUUID{UUID: userID, Valid: true})
+}
+
+// LinkIdentity finishes a flow started with StartIdentityLink by the same user.
+func (s *authService) LinkIdentity(ctx context.Context, userID uuid.UUID, provider string, req dto.OIDCCallbackRequest) (*dto.IdentityData, error) {
+	identity, state, err := s.finishOIDCFlow(ctx, provider, req)
+	if err != nil {
+		return nil, err
+	}
+	if !state.UserID.Valid || state.UserID.UUID != userID {
+		return nil, errors.ErrInvalidOIDCState
+	}
+
+	existing, err := s.identityRepo.GetBySubject(ctx, identity.Provider, identity.Subject)
+	if err != nil && err != errors.ErrIdentityNotFound {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.UserID != userID {
+			return nil, errors.ErrIdentityAlreadyLinked
+		}
+		data := toIdentityData(existing)
+		return &data, nil
+	}
+
+	linked, err := s.linkIdentity(ctx, userID, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	data := toIdentityData(linked)
+	return &data, nil
+}
+
+func (s *authService) ListIdentities(ctx context.Context, userID uuid.UUID) (*dto.IdentityListResponse, error) {
+	identities, err := s.identityRepo.ListByUser(ctx, userID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list identities", err, logger.Fields{
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	data := make([]dto.IdentityData, 0, len(identities))
+	for i := range identities {
+		data = append(data, toIdentityData(&identities[i]))
+	}
+
+	return &dto.IdentityListResponse{Identities: data}, nil
+}
+
+func (s *authService) UnlinkIdentity(ctx context.Context, userID uuid.UUID, id uuid.UUID) error {
+	identity, err := s.identityRepo.Delete(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "Identity unlinked", logger.Fields{
+		"user_id":  userID,
+		"provider": identity.Provider,
+	})
+
+	s.audit.Record(ctx, auditService.Event{
+		UserID:       &userID,
+		Action:       auditService.ActionIdentityUnlink,
+		ResourceType: "user_identity",
+		ResourceID:   identity.ID.String(),
+		Metadata:     map[string]interface{}{"provider": identity.Provider},
+	})
+
+	return nil
+}
+
+func (s *authService) startOIDCFlow(ctx context.Context, providerName string, userID uuid.NullUUID) (*dto.OIDCAuthorizeResponse, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, errors.ErrOIDCProviderNotFound
+	}
+
+	state, err := token.Generate(32)
+	if err != nil {
+		return nil, errors.WrapInternalError(err, "failed to generate login state")
+	}
+	nonce, err := token.Generate(32)
+	if err != nil {
+		return nil, errors.WrapInternalError(err, "failed to generate login state")
+	}
+	verifier, err := oidc.GenerateVerifier()
+	if err != nil {
+		return nil, errors.WrapInternalError(err, "failed to generate login state")
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.ChallengeS256(verifier))
+	if err != nil {
+		s.logger.Error(ctx, "Failed to build authorization URL", err, logger.Fields{
+			"provider": providerName,
+		})
+		return nil, errors.WrapInternalError(err, "identity provider is unavailable")
+	}
+
+	expiresAt := time.Now().UTC().Add(s.config.OIDC.StateExp)
+	err = s.identityRepo.CreateState(ctx, &repository.OIDCState{
+		StateHash:    token.Hash(state),
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		UserID:       userID,
+		ExpiresAt:    expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.OIDCAuthorizeResponse{
+		AuthorizationURL: authURL,
+		State:            state,
+		ExpiresAt:        expiresAt,
+	}, nil
+}
+
+// finishOIDCFlow consumes the state and redeems the code. Provider errors are
+// logged in full but reported to the client as a generic login failure.
+func (s *authService) finishOIDCFlow(ctx context.Context, providerName string, req dto.OIDCCallbackRequest) (*oidc.Identity, *repository.OIDCState, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, nil, errors.ErrOIDCProviderNotFound
+	}
+
+	state, err := s.identityRepo.ConsumeState(ctx, token.Hash(req.State), providerName)
+	if err != nil {
+		if err == errors.ErrInvalidOIDCState {
+			s.logger.Warn(ctx, "OIDC callback with unknown or expired state", logger.Fields{
+				"provider": providerName,
+			})
+		}
+		return nil, nil, err
+	}
+
+	identity, err := provider.Exchange(ctx, req.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		s.logger.Warn(ctx, "OIDC code exchange failed", logger.Fields{
+			"provider": providerName,
+			"error":    err.Error(),
+		})
+		s.audit.Record(ctx, auditService.Event{
+			Action:   auditService.ActionLoginFailure,
+			Metadata: map[string]interface{}{"provider": providerName, "reason": "oidc_exchange_failed"},
+		})
+		return nil, nil, errors.ErrOIDCLoginFailed
+	}
+
+	return identity, state, nil
+}
+
+func (s *authService) resolveOIDCUser(ctx context.Context, identity *oidc.Identity) (*repository.User, error) {
+	linked, err := s.identityRepo.GetBySubject(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		if err := s.identityRepo.RecordLogin(ctx, linked.ID, identity.Email); err != nil {
+			s.logger.Error(ctx, "Failed to record identity login", err, logger.Fields{
+				"user_id":  linked.UserID,
+				"provider": identity.Provider,
+			})
+		}
+		return s.authRepo.GetUserWithPassword(ctx, linked.UserID)
+	}
+	if err != errors.ErrIdentityNotFound {
+		return nil, err
+	}
+
+	// Unknown provider accounts are matched by email, which must be verified
+	// by the provider or anyone could claim an address they do not own.
+	if identity.Email == "" || !identity.EmailVerified {
+		s.logger.Warn(ctx, "OIDC login without verified email", logger.Fields{
+			"provider": identity.Provider,
+		})
+		return nil, errors.ErrOIDCEmailNotVerified
+	}
+
+	user, err := s.authRepo.GetUserByEmail(ctx, identity.Email)
+	if err != nil && err != errors.ErrUserNotFound {
+		return nil, err
+	}
+
+	if user != nil {
+		// An unverified local account may have been registered by someone else
+		// with this address; linking it would hand them the provider login.
+		if !user.VerifiedAt.Valid {
+			s.logger.Warn(ctx, "OIDC login matched an unverified account", logger.Fields{
+				"user_id":  user.ID,
+				"provider": identity.Provider,
+			})
+			return nil, errors.ErrOIDCAccountConflict
+		}
+	} else {
+		if user, err = s.createOIDCUser(ctx, identity); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := s.linkIdentity(ctx, user.ID, identity); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// createOIDCUser registers a user on first login. The account gets a random
+// password nobody knows; the user can set one through the password reset flow.
+func (s *authService) createOIDCUser(ctx context.Context, identity *oidc.Identity) (*repository.User, error) {
+	randomPassword, err := token.Generate(32)
+	if err != nil {
+		return nil, errors.WrapInternalError(err, "failed to create user")
+	}
+	hashedPassword, err := s.passUtil.HashPassword(randomPassword)
+	if err != nil {
+		return nil, errors.WrapInternalError(err, "failed to create user")
+	}
+
+	name := identity.Name
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	userData, err := s.authRepo.CreateUser(ctx, dto.RegisterRequest{Name: name, Email: identity.Email}, hashedPassword)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to create user from OIDC login", err, logger.Fields{
+			"provider": identity.Provider,
+		})
+		return nil, err
+	}
+
+	if _, err := s.authRepo.MarkEmailVerified(ctx, userData.ID, userData.Email); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "User registered through identity provider", logger.Fields{
+		"user_id":  userData.ID,
+		"provider": identity.Provider,
+	})
+
+	s.audit.Record(ctx, auditService.Event{
+		UserID:       &userData.ID,
+		Action:       auditService.ActionRegister,
+		ResourceType: "user",
+		ResourceID:   userData.ID.String(),
+		Metadata:     map[string]interface{}{"provider": identity.Provider},
+	})
+
+	return s.authRepo.GetUserWithPassword(ctx, userData.ID)
+}
+
+func (s *authService) linkIdentity(ctx context.Context, userID uuid.UUID, identity *oidc.Identity) (*repository.Identity, error) {
+	linked, err := s.identityRepo.Create(ctx, &repository.Identity{
+		UserID:   userID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    sql.NullString{String: identity.Email, Valid: identity.Email != ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Identity linked", logger.Fields{
+		"user_id":  userID,
+		"provider": identity.Provider,
+	})
+
+	s.audit.Record(ctx, auditService.Event{
+		UserID:       &userID,
+		Action:       auditService.ActionIdentityLink,
+		ResourceType: "user_identity",
+		ResourceID:   linked.ID.String(),
+		Metadata:     map[string]interface{}{"provider": identity.Provider},
+	})
+
+	return linked, nil
+}
+
+func toIdentityData(identity *repository.Identity) dto.IdentityData {
+	data := dto.IdentityData{
+		ID:        identity.ID,
+		Provider:  identity.Provider,
+		CreatedAt: identity.CreatedAt,
+	}
+	if identity.Email.Valid {
+		email := identity.Email.String
+		data.Email = &email
+	}
+	if identity.LastLoginAt.Valid {
+		lastLoginAt := identity.LastLoginAt.Time
+		data.LastLoginAt = &lastLoginAt
+	}
+	return data
+}
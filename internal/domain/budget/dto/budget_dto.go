@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+const (
+	PeriodDaily   = "daily"
+	PeriodMonthly = "monthly"
+	PeriodYearly  = "yearly"
+)
+
+var Periods = []string{PeriodDaily, PeriodMonthly, PeriodYearly}
+
+type SetLimitsRequest struct {
+	DailyLimit   *float64 `json:"daily_limit" validate:"omitempty,gt=0,lt=10000000000" example:"100000"`
+	MonthlyLimit *float64 `json:"monthly_limit" validate:"omitempty,gt=0,lt=10000000000" example:"3000000"`
+	YearlyLimit  *float64 `json:"yearly_limit" validate:"omitempty,gt=0,lt=10000000000" example:"36000000"`
+}
+
+// UpdateLimitsRequest changes the limits that are sent and keeps the others.
+// A limit is removed with its clear_ flag, which cannot be combined with a
+// new value for the same limit.
+type UpdateLimitsRequest struct {
+	DailyLimit        *float64 `json:"daily_limit" validate:"omitempty,gt=0,lt=10000000000" example:"100000"`
+	MonthlyLimit      *float64 `json:"monthly_limit" validate:"omitempty,gt=0,lt=10000000000" example:"3000000"`
+	YearlyLimit       *float64 `json:"yearly_limit" validate:"omitempty,gt=0,lt=10000000000" example:"36000000"`
+	ClearDailyLimit   bool     `json:"clear_daily_limit" validate:"excluded_with=DailyLimit"`
+	ClearMonthlyLimit bool     `json:"clear_monthly_limit" validate:"excluded_with=MonthlyLimit"`
+	ClearYearlyLimit  bool     `json:"clear_yearly_limit" validate:"excluded_with=YearlyLimit"`
+}
+
+type UsageRequest struct {
+	Date string `query:"date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type LimitsData struct {
+	DailyLimit   *float64  `json:"daily_limit"`
+	MonthlyLimit *float64  `json:"monthly_limit"`
+	YearlyLimit  *float64  `json:"yearly_limit"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type PeriodUsage struct {
+	Period     string   `json:"period"`
+	StartDate  string   `json:"start_date"`
+	EndDate    string   `json:"end_date"`
+	Limit      *float64 `json:"limit"`
+	Spent      float64  `json:"spent"`
+	Remaining  *float64 `json:"remaining"`
+	Percentage *float64 `json:"percentage"`
+	Exceeded   bool     `json:"exceeded"`
+}
+
+type UsageResponse struct {
+	Date    string        `json:"date"`
+	Periods []PeriodUsage `json:"periods"`
+}
+
+// PeriodRange returns the first and last day of the period containing date.
+func PeriodRange(period string, date time.Time) (time.Time, time.Time) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case PeriodMonthly:
+		start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1)
+	case PeriodYearly:
+		start := time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, -1)
+	default:
+		return day, day
+	}
+}
@@ -0,0 +1,158 @@
+package http
+
+import (
+	"devsecops-be/internal/domain/budget/dto"
+	"devsecops-be/internal/domain/budget/service"
+	"devsecops-be/internal/middleware"
+	"devsecops-be/pkg/errors"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/response"
+	"devsecops-be/pkg/validator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type BudgetHandler struct {
+	budgetService service.BudgetService
+	validator     validator.Validator
+	logger        logger.Logger
+}
+
+func NewBudgetHandler(
+	budgetService service.BudgetService,
+	validator validator.Validator,
+	logger logger.Logger,
+) *BudgetHandler {
+	return &BudgetHandler{
+		budgetService: budgetService,
+		validator:     validator,
+		logger:        logger,
+	}
+}
+
+func (h *BudgetHandler) GetLimits(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	result, err := h.budgetService.GetLimits(ctx, userID)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Spending limits retrieved successfully", result)
+}
+
+func (h *BudgetHandler) SetLimits(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	var req dto.SetLimitsRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in set spending limits", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in set spending limits", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.budgetService.SetLimits(ctx, userID, req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Spending limits saved successfully", result)
+}
+
+func (h *BudgetHandler) UpdateLimits(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	var req dto.UpdateLimitsRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in update spending limits", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in update spending limits", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.budgetService.UpdateLimits(ctx, userID, req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Spending limits updated successfully", result)
+}
+
+func (h *BudgetHandler) ClearLimits(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	if err := h.budgetService.ClearLimits(ctx, userID); err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Spending limits cleared successfully", nil)
+}
+
+func (h *BudgetHandler) GetUsage(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	var req dto.UsageRequest
+
+	if err := c.QueryParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid query parameters", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	date := time.Now()
+	if req.Date != "" {
+		date, _ = time.Parse(dto.DateLayout, req.Date)
+	}
+
+	result, err := h.budgetService.GetUsage(ctx, userID, date)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Spending usage retrieved successfully", result)
+}
@@ -0,0 +1,44 @@
+package budget
+
+import (
+	"database/sql"
+	"devsecops-be/internal/domain/budget/handler/http"
+	"devsecops-be/internal/domain/budget/repository"
+	"devsecops-be/internal/domain/budget/service"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type BudgetModule struct {
+	Handler *http.BudgetHandler
+	Service service.BudgetService
+}
+
+func NewBudgetModule(db *sql.DB, logger logger.Logger) *BudgetModule {
+	// Initialize dependencies
+	budgetRepo := repository.NewBudgetRepository(db)
+	validator := validator.NewValidator()
+
+	// Initialize service
+	budgetService := service.NewBudgetService(budgetRepo, logger)
+
+	// Initialize handler
+	budgetHandler := http.NewBudgetHandler(budgetService, validator, logger)
+
+	return &BudgetModule{
+		Handler: budgetHandler,
+		Service: budgetService,
+	}
+}
+
+func (m *BudgetModule) RegisterRoutes(app *fiber.App, authMiddleware fiber.Handler) {
+	budget := app.Group("/api/v1/budget", authMiddleware)
+
+	budget.Get("/limits", m.Handler.GetLimits)
+	budget.Put("/limits", m.Handler.SetLimits)
+	budget.Patch("/limits", m.Handler.UpdateLimits)
+	budget.Delete("/limits", m.Handler.ClearLimits)
+	budget.Get("/usage", m.Handler.GetUsage)
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/internal/domain/budget/dto"
+	"devsecops-be/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BudgetRepository interface {
+	GetLimits(ctx context.Context, userID uuid.UUID) (*MaximumSpend, error)
+	UpsertLimits(ctx context.Context, limits *MaximumSpend) (*MaximumSpend, error)
+	DeleteLimits(ctx context.Context, userID uuid.UUID) error
+	GetExpenseTotals(ctx context.Context, userID uuid.UUID, date time.Time) (*ExpenseTotals, error)
+}
+
+type MaximumSpend struct {
+	ID           uuid.UUID       `db:"id"`
+	UserID       uuid.UUID       `db:"user_id"`
+	DailyLimit   sql.NullFloat64 `db:"daily_limit"`
+	MonthlyLimit sql.NullFloat64 `db:"monthly_limit"`
+	YearlyLimit  sql.NullFloat64 `db:"yearly_limit"`
+	CreatedAt    time.Time       `db:"created_at"`
+	UpdatedAt    time.Time       `db:"updated_at"`
+}
+
+func (m *MaximumSpend) LimitFor(period string) sql.NullFloat64 {
+	switch period {
+	case dto.PeriodDaily:
+		return m.DailyLimit
+	case dto.PeriodMonthly:
+		return m.MonthlyLimit
+	case dto.PeriodYearly:
+		return m.YearlyLimit
+	}
+	return sql.NullFloat64{}
+}
+
+// ExpenseTotals holds the expense sums for the day, month and year containing a date.
+type ExpenseTotals struct {
+	Daily   float64
+	Monthly float64
+	Yearly  float64
+}
+
+func (t *ExpenseTotals) For(period string) float64 {
+	switch period {
+	case dto.PeriodDaily:
+		return t.Daily
+	case dto.PeriodMonthly:
+		return t.Monthly
+	case dto.PeriodYearly:
+		return t.Yearly
+	}
+	return 0
+}
+
+const maximumSpendColumns = `id, user_id, daily_limit, monthly_limit, yearly_limit, created_at, updated_at`
+
+type budgetRepository struct {
+	db *sql.DB
+}
+
+func NewBudgetRepository(db *sql.DB) BudgetRepository {
+	return &budgetRepository{db: db}
+}
+
+func (r *budgetRepository) GetLimits(ctx context.Context, userID uuid.UUID) (*MaximumSpend, error) {
+	query := `
+        SELECT ` + maximumSpendColumns + `
+        FROM maximum_spends
+        WHERE user_id = $1
+    `
+
+	limits, err := scanMaximumSpend(r.db.QueryRowContext(ctx, query, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrSpendingLimitNotFound
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to get spending limits")
+	}
+
+	return limits, nil
+}
+
+func (r *budgetRepository) UpsertLimits(ctx context.Context, limits *MaximumSpend) (*MaximumSpend, error) {
+	query := `
+        INSERT INTO maximum_spends (user_id, daily_limit, monthly_limit, yearly_limit)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (user_id) DO UPDATE
+        SET daily_limit = EXCLUDED.daily_limit,
+            monthly_limit = EXCLUDED.monthly_limit,
+            yearly_limit = EXCLUDED.yearly_limit,
+            updated_at = NOW()
+        RETURNING ` + maximumSpendColumns
+
+	saved, err := scanMaximumSpend(r.db.QueryRowContext(ctx, query,
+		limits.UserID, limits.DailyLimit, limits.MonthlyLimit, limits.YearlyLimit))
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "failed to save spending limits")
+	}
+
+	return saved, nil
+}
+
+func (r *budgetRepository) DeleteLimits(ctx context.Context, userID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM maximum_spends WHERE user_id = $1`, userID)
+	if err != nil {
+		return errors.WrapDatabaseError(err, "failed to delete spending limits")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.WrapDatabaseError(err, "failed to delete spending limits")
+	}
+	if affected == 0 {
+		return errors.ErrSpendingLimitNotFound
+	}
+
+	return nil
+}
+
+func (r *budgetRepository) GetExpenseTotals(ctx context.Context, userID uuid.UUID, date time.Time) (*ExpenseTotals, error) {
+	query := `
+        SELECT
+            COALESCE(SUM(amount) FILTER (WHERE date = $2::date), 0),
+            COALESCE(SUM(amount) FILTER (WHERE date_trunc('month', date) = date_trunc('month', $2::date)), 0),
+            COALESCE(SUM(amount), 0)
+        FROM transactions
+        WHERE user_id = $1
+          AND type = 'expense'
+          AND date >= date_trunc('year', $2::date)
+          AND date < date_trunc('year', $2::date) + INTERVAL '1 year'
+    `
+
+	var totals ExpenseTotals
+	err := r.db.QueryRowContext(ctx, query, userID, date.Format(dto.DateLayout)).
+		Scan(&totals.Daily, &totals.Monthly, &totals.Yearly)
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "failed to sum expenses")
+	}
+
+	return &totals, nil
+}
+
+func scanMaximumSpend(row *sql.Row) (*MaximumSpend, error) {
+	var limits MaximumSpend
+	err := row.Scan(
+		&limits.ID, &limits.UserID, &limits.DailyLimit, &limits.MonthlyLimit,
+		&limits.YearlyLimit, &limits.CreatedAt, &limits.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &limits, nil
+}
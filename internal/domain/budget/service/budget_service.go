@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/internal/domain/budget/dto"
+	"devsecops-be/internal/domain/budget/repository"
+	"devsecops-be/pkg/errors"
+	"devsecops-be/pkg/logger"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BudgetService interface {
+	GetLimits(ctx context.Context, userID uuid.UUID) (*dto.LimitsData, error)
+	SetLimits(ctx context.Context, userID uuid.UUID, req dto.SetLimitsRequest) (*dto.LimitsData, error)
+	UpdateLimits(ctx context.Context, userID uuid.UUID, req dto.UpdateLimitsRequest) (*dto.LimitsData, error)
+	ClearLimits(ctx context.Context, userID uuid.UUID) error
+	GetUsage(ctx context.Context, userID uuid.UUID, date time.Time) (*dto.UsageResponse, error)
+}
+
+type budgetService struct {
+	budgetRepo repository.BudgetRepository
+	logger     logger.Logger
+}
+
+func NewBudgetService(
+	budgetRepo repository.BudgetRepository,
+	logger logger.Logger,
+) BudgetService {
+	return &budgetService{
+		budgetRepo: budgetRepo,
+		logger:     logger,
+	}
+}
+
+func (s *budgetService) GetLimits(ctx context.Context, userID uuid.UUID) (*dto.LimitsData, error) {
+	limits, err := s.budgetRepo.GetLimits(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toLimitsData(limits), nil
+}
+
+func (s *budgetService) SetLimits(ctx context.Context, userID uuid.UUID, req dto.SetLimitsRequest) (*dto.LimitsData, error) {
+	limits, err := s.budgetRepo.UpsertLimits(ctx, &repository.MaximumSpend{
+		UserID:       userID,
+		DailyLimit:   toNullFloat64(req.DailyLimit),
+		MonthlyLimit: toNullFloat64(req.MonthlyLimit),
+		YearlyLimit:  toNullFloat64(req.YearlyLimit),
+	})
+	if err != nil {
+		s.logger.Error(ctx, "Failed to set spending limits", err, logger.Fields{
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Spending limits set", logger.Fields{
+		"user_id": userID,
+	})
+
+	return toLimitsData(limits), nil
+}
+
+func (s *budgetService) UpdateLimits(ctx context.Context, userID uuid.UUID, req dto.UpdateLimitsRequest) (*dto.LimitsData, error) {
+	current, err := s.budgetRepo.GetLimits(ctx, userID)
+	if err != nil {
+		if err != errors.ErrSpendingLimitNotFound {
+			return nil, err
+		}
+		current = &repository.MaximumSpend{UserID: userID}
+	}
+
+	if req.DailyLimit != nil || req.ClearDailyLimit {
+		current.DailyLimit = toNullFloat64(req.DailyLimit)
+	}
+	if req.MonthlyLimit != nil || req.ClearMonthlyLimit {
+		current.MonthlyLimit = toNullFloat64(req.MonthlyLimit)
+	}
+	if req.YearlyLimit != nil || req.ClearYearlyLimit {
+		current.YearlyLimit = toNullFloat64(req.YearlyLimit)
+	}
+
+	limits, err := s.budgetRepo.UpsertLimits(ctx, current)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to update spending limits", err, logger.Fields{
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Spending limits updated", logger.Fields{
+		"user_id": userID,
+	})
+
+	return toLimitsData(limits), nil
+}
+
+func (s *budgetService) ClearLimits(ctx context.Context, userID uuid.UUID) error {
+	if err := s.budgetRepo.DeleteLimits(ctx, userID); err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "Spending limits cleared", logger.Fields{
+		"user_id": userID,
+	})
+
+	return nil
+}
+
+func (s *budgetService) GetUsage(ctx context.Context, userID uuid.UUID, date time.Time) (*dto.UsageResponse, error) {
+	limits, err := s.budgetRepo.GetLimits(ctx, userID)
+	if err != nil {
+		if err != errors.ErrSpendingLimitNotFound {
+			return nil, err
+		}
+		limits = &repository.MaximumSpend{UserID: userID}
+	}
+
+	totals, err := s.budgetRepo.GetExpenseTotals(ctx, userID, date)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to compute spending usage", err, logger.Fields{
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	periods := make([]dto.PeriodUsage, 0, len(dto.Periods))
+	for _, period := range dto.Periods {
+		periods = append(periods, buildPeriodUsage(period, date, limits.LimitFor(period), totals.For(period)))
+	}
+
+	return &dto.UsageResponse{
+		Date:    date.Format(dto.DateLayout),
+		Periods: periods,
+	}, nil
+}
+
+func buildPeriodUsage(period string, date time.Time, limit sql.NullFloat64, spent float64) dto.PeriodUsage {
+	start, end := dto.PeriodRange(period, date)
+
+	usage := dto.PeriodUsage{
+		Period:    period,
+		StartDate: start.Format(dto.DateLayout),
+		EndDate:   end.Format(dto.DateLayout),
+		Spent:     spent,
+	}
+
+	if limit.Valid {
+		limitValue := limit.Float64
+		remaining := math.Max(limitValue-spent, 0)
+		percentage := math.Round(spent/limitValue*10000) / 100
+
+		usage.Limit = &limitValue
+		usage.Remaining = &remaining
+		usage.Percentage = &percentage
+		usage.Exceeded = spent > limitValue
+	}
+
+	return usage
+}
+
+func toLimitsData(limits *repository.MaximumSpend) *dto.LimitsData {
+	return &dto.LimitsData{
+		DailyLimit:   fromNullFloat64(limits.DailyLimit),
+		MonthlyLimit: fromNullFloat64(limits.MonthlyLimit),
+		YearlyLimit:  fromNullFloat64(limits.YearlyLimit),
+		CreatedAt:    limits.CreatedAt,
+		UpdatedAt:    limits.UpdatedAt,
+	}
+}
+
+func toNullFloat64(value *float64) sql.NullFloat64 {
+	if value == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *value, Valid: true}
+}
+
+func fromNullFloat64(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	v := value.Float64
+	return &v
+}
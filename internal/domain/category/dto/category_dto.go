@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateCategoryRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=50" example:"Groceries"`
+}
+
+type RenameCategoryRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=50" example:"Groceries"`
+}
+
+type CategoryFilter struct {
+	IncludeArchived bool `query:"include_archived"`
+}
+
+type DeleteCategoryRequest struct {
+	ReassignTo string `query:"reassign_to" validate:"omitempty,uuid"`
+}
+
+type CategoryData struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	IsDefault  bool       `json:"is_default"`
+	ArchivedAt *time.Time `json:"archived_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,228 @@
+package http
+
+import (
+	"devsecops-be/internal/domain/category/dto"
+	"devsecops-be/internal/domain/category/service"
+	"devsecops-be/internal/middleware"
+	"devsecops-be/pkg/errors"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/response"
+	"devsecops-be/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CategoryHandler struct {
+	categoryService service.CategoryService
+	validator       validator.Validator
+	logger          logger.Logger
+}
+
+func NewCategoryHandler(
+	categoryService service.CategoryService,
+	validator validator.Validator,
+	logger logger.Logger,
+) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+		validator:       validator,
+		logger:          logger,
+	}
+}
+
+func (h *CategoryHandler) List(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	var filter dto.CategoryFilter
+
+	if err := c.QueryParser(&filter); err != nil {
+		h.logger.Warn(ctx, "Invalid query parameters in list categories", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid query parameters", nil)
+	}
+
+	result, err := h.categoryService.List(ctx, userID, filter)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Categories retrieved successfully", result)
+}
+
+func (h *CategoryHandler) GetByID(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid category ID", nil)
+	}
+
+	result, err := h.categoryService.GetByID(ctx, userID, id)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Category retrieved successfully", result)
+}
+
+func (h *CategoryHandler) Create(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	var req dto.CreateCategoryRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in create category", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in create category", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.categoryService.Create(ctx, userID, req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Created(c, "Category created successfully", result)
+}
+
+func (h *CategoryHandler) Rename(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid category ID", nil)
+	}
+
+	var req dto.RenameCategoryRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in rename category", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in rename category", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.categoryService.Rename(ctx, userID, id, req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Category renamed successfully", result)
+}
+
+func (h *CategoryHandler) Archive(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid category ID", nil)
+	}
+
+	result, err := h.categoryService.Archive(ctx, userID, id)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Category archived successfully", result)
+}
+
+func (h *CategoryHandler) Restore(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid category ID", nil)
+	}
+
+	result, err := h.categoryService.Restore(ctx, userID, id)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Category restored successfully", result)
+}
+
+func (h *CategoryHandler) Delete(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid category ID", nil)
+	}
+
+	var req dto.DeleteCategoryRequest
+
+	if err := c.QueryParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid query parameters", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	var reassignTo *uuid.UUID
+	if req.ReassignTo != "" {
+		target := uuid.MustParse(req.ReassignTo)
+		if target == id {
+			return response.BadRequest(c, "Cannot reassign transactions to the category being deleted", nil)
+		}
+		reassignTo = &target
+	}
+
+	if err := h.categoryService.Delete(ctx, userID, id, reassignTo); err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Category deleted successfully", nil)
+}
@@ -0,0 +1,46 @@
+package category
+
+import (
+	"database/sql"
+	"devsecops-be/internal/domain/category/handler/http"
+	"devsecops-be/internal/domain/category/repository"
+	"devsecops-be/internal/domain/category/service"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CategoryModule struct {
+	Handler *http.CategoryHandler
+	Service service.CategoryService
+}
+
+func NewCategoryModule(db *sql.DB, logger logger.Logger) *CategoryModule {
+	// Initialize dependencies
+	categoryRepo := repository.NewCategoryRepository(db)
+	validator := validator.NewValidator()
+
+	// Initialize service
+	categoryService := service.NewCategoryService(categoryRepo, logger)
+
+	// Initialize handler
+	categoryHandler := http.NewCategoryHandler(categoryService, validator, logger)
+
+	return &CategoryModule{
+		Handler: categoryHandler,
+		Service: categoryService,
+	}
+}
+
+func (m *CategoryModule) RegisterRoutes(app *fiber.App, authMiddleware fiber.Handler) {
+	categories := app.Group("/api/v1/categories", authMiddleware)
+
+	categories.Get("/", m.Handler.List)
+	categories.Post("/", m.Handler.Create)
+	categories.Get("/:id", m.Handler.GetByID)
+	categories.Put("/:id", m.Handler.Rename)
+	categories.Post("/:id/archive", m.Handler.Archive)
+	categories.Post("/:id/restore", m.Handler.Restore)
+	categories.Delete("/:id", m.Handler.Delete)
+}
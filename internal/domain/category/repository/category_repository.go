@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type CategoryRepository interface {
+	List(ctx context.Context, userID uuid.UUID, includeArchived bool) ([]Category, error)
+	GetByID(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*Category, error)
+	Create(ctx context.Context, userID uuid.UUID, name string) (*Category, error)
+	Rename(ctx context.Context, userID uuid.UUID, id uuid.UUID, name string) (*Category, error)
+	SetArchived(ctx context.Context, userID uuid.UUID, id uuid.UUID, archived bool) (*Category, error)
+	Delete(ctx context.Context, userID uuid.UUID, id uuid.UUID, reassignTo *uuid.UUID) error
+}
+
+// Category is either a system default (UserID is NULL) or owned by a single user.
+type Category struct {
+	ID         uuid.UUID     `db:"id"`
+	UserID     uuid.NullUUID `db:"user_id"`
+	Name       string        `db:"name"`
+	ArchivedAt sql.NullTime  `db:"archived_at"`
+	CreatedAt  time.Time     `db:"created_at"`
+	UpdatedAt  time.Time     `db:"updated_at"`
+}
+
+func (c *Category) IsDefault() bool {
+	return !c.UserID.Valid
+}
+
+const categoryColumns = `id, user_id, name, archived_at, created_at, updated_at`
+
+type categoryRepository struct {
+	db *sql.DB
+}
+
+func NewCategoryRepository(db *sql.DB) CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+func (r *categoryRepository) List(ctx context.Context, userID uuid.UUID, includeArchived bool) ([]Category, error) {
+	query := `
+        SELECT ` + categoryColumns + `
+        FROM categories
+        WHERE (user_id IS NULL OR user_id = $1)
+          AND ($2 OR archived_at IS NULL)
+        ORDER BY user_id NULLS FIRST, name
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, userID, includeArchived)
+	if err != nil {
+		return nil, errors.WrapDatabaseError(err, "failed to list categories")
+	}
+	defer rows.Close()
+
+	categories := []Category{}
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, errors.WrapDatabaseError(err, "failed to scan category")
+		}
+		categories = append(categories, *category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapDatabaseError(err, "failed to iterate categories")
+	}
+
+	return categories, nil
+}
+
+func (r *categoryRepository) GetByID(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*Category, error) {
+	query := `
+        SELECT ` + categoryColumns + `
+        FROM categories
+        WHERE id = $1 AND (user_id IS NULL OR user_id = $2)
+    `
+
+	category, err := scanCategory(r.db.QueryRowContext(ctx, query, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrCategoryNotFound
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to get category by ID")
+	}
+
+	return category, nil
+}
+
+func (r *categoryRepository) Create(ctx context.Context, userID uuid.UUID, name string) (*Category, error) {
+	query := `
+        INSERT INTO categories (user_id, name)
+        VALUES ($1, $2)
+        RETURNING ` + categoryColumns
+
+	category, err := scanCategory(r.db.QueryRowContext(ctx, query, userID, name))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, errors.ErrCategoryAlreadyExists
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to create category")
+	}
+
+	return category, nil
+}
+
+func (r *categoryRepository) Rename(ctx context.Context, userID uuid.UUID, id uuid.UUID, name string) (*Category, error) {
+	query := `
+        UPDATE categories
+        SET name = $1, updated_at = NOW()
+        WHERE id = $2 AND user_id = $3
+        RETURNING ` + categoryColumns
+
+	category, err := scanCategory(r.db.QueryRowContext(ctx, query, name, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrCategoryNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, errors.ErrCategoryAlreadyExists
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to rename category")
+	}
+
+	return category, nil
+}
+
+func (r *categoryRepository) SetArchived(ctx context.Context, userID uuid.UUID, id uuid.UUID, archived bool) (*Category, error) {
+	query := `
+        UPDATE categories
+        SET archived_at = CASE WHEN $1 THEN COALESCE(archived_at, NOW()) ELSE NULL END,
+            updated_at = NOW()
+        WHERE id = $2 AND user_id = $3
+        RETURNING ` + categoryColumns
+
+	category, err := scanCategory(r.db.QueryRowContext(ctx, query, archived, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrCategoryNotFound
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to update category archive state")
+	}
+
+	return category, nil
+}
+
+// Delete removes a user-owned category. When reassignTo is set, the user's
+// transactions are moved to that category first; otherwise the delete is
+// rejected while any transaction still references the category.
+func (r *categoryRepository) Delete(ctx context.Context, userID uuid.UUID, id uuid.UUID, reassignTo *uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.WrapDatabaseError(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if reassignTo != nil {
+		_, err := tx.ExecContext(ctx, `
+            UPDATE transactions
+            SET category_id = $1, updated_at = NOW()
+            WHERE category_id = $2 AND user_id = $3
+        `, *reassignTo, id, userID)
+		if err != nil {
+			return errors.WrapDatabaseError(err, "failed to reassign transactions")
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM categories WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return errors.ErrCategoryInUse
+		}
+		return errors.WrapDatabaseError(err, "failed to delete category")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.WrapDatabaseError(err, "failed to delete category")
+	}
+	if affected == 0 {
+		return errors.ErrCategoryNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		if isForeignKeyViolation(err) {
+			return errors.ErrCategoryInUse
+		}
+		return errors.WrapDatabaseError(err, "failed to commit category deletion")
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCategory(row rowScanner) (*Category, error) {
+	var category Category
+	err := row.Scan(
+		&category.ID, &category.UserID, &category.Name,
+		&category.ArchivedAt, &category.CreatedAt, &category.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func isUniqueViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23505"
+}
+
+func isForeignKeyViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23503"
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"devsecops-be/internal/domain/category/dto"
+	"devsecops-be/internal/domain/category/repository"
+	"devsecops-be/pkg/errors"
+	"devsecops-be/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+type CategoryService interface {
+	List(ctx context.Context, userID uuid.UUID, filter dto.CategoryFilter) ([]dto.CategoryData, error)
+	GetByID(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*dto.CategoryData, error)
+	Create(ctx context.Context, userID uuid.UUID, req dto.CreateCategoryRequest) (*dto.CategoryData, error)
+	Rename(ctx context.Context, userID uuid.UUID, id uuid.UUID, req dto.RenameCategoryRequest) (*dto.CategoryData, error)
+	Archive(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*dto.CategoryData, error)
+	Restore(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*dto.CategoryData, error)
+	Delete(ctx context.Context, userID uuid.UUID, id uuid.UUID, reassignTo *uuid.UUID) error
+}
+
+type categoryService struct {
+	categoryRepo repository.CategoryRepository
+	logger       logger.Logger
+}
+
+func NewCategoryService(
+	categoryRepo repository.CategoryRepository,
+	logger logger.Logger,
+) CategoryService {
+	return &categoryService{
+		categoryRepo: categoryRepo,
+		logger:       logger,
+	}
+}
+
+func (s *categoryService) List(ctx context.Context, userID uuid.UUID, filter dto.CategoryFilter) ([]dto.CategoryData, error) {
+	categories, err := s.categoryRepo.List(ctx, userID, filter.IncludeArchived)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list categories", err, logger.Fields{
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	data := make([]dto.CategoryData, 0, len(categories))
+	for i := range categories {
+		data = append(data, *toCategoryData(&categories[i]))
+	}
+
+	return data, nil
+}
+
+func (s *categoryService) GetByID(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*dto.CategoryData, error) {
+	category, err := s.categoryRepo.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toCategoryData(category), nil
+}
+
+func (s *categoryService) Create(ctx context.Context, userID uuid.UUID, req dto.CreateCategoryRequest) (*dto.CategoryData, error) {
+	category, err := s.categoryRepo.Create(ctx, userID, req.Name)
+	if err != nil {
+		if err != errors.ErrCategoryAlreadyExists {
+			s.logger.Error(ctx, "Failed to create category", err, logger.Fields{
+				"user_id": userID,
+			})
+		}
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Category created", logger.Fields{
+		"user_id":     userID,
+		"category_id": category.ID,
+	})
+
+	return toCategoryData(category), nil
+}
+
+func (s *categoryService) Rename(ctx context.Context, userID uuid.UUID, id uuid.UUID, req dto.RenameCategoryRequest) (*dto.CategoryData, error) {
+	if err := s.ensureOwned(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	category, err := s.categoryRepo.Rename(ctx, userID, id, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Category renamed", logger.Fields{
+		"user_id":     userID,
+		"category_id": id,
+	})
+
+	return toCategoryData(category), nil
+}
+
+func (s *categoryService) Archive(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*dto.CategoryData, error) {
+	return s.setArchived(ctx, userID, id, true)
+}
+
+func (s *categoryService) Restore(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*dto.CategoryData, error) {
+	return s.setArchived(ctx, userID, id, false)
+}
+
+func (s *categoryService) setArchived(ctx context.Context, userID uuid.UUID, id uuid.UUID, archived bool) (*dto.CategoryData, error) {
+	if err := s.ensureOwned(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	category, err := s.categoryRepo.SetArchived(ctx, userID, id, archived)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Category archive state changed", logger.Fields{
+		"user_id":     userID,
+		"category_id": id,
+		"archived":    archived,
+	})
+
+	return toCategoryData(category), nil
+}
+
+func (s *categoryService) Delete(ctx context.Context, userID uuid.UUID, id uuid.UUID, reassignTo *uuid.UUID) error {
+	if err := s.ensureOwned(ctx, userID, id); err != nil {
+		return err
+	}
+
+	if reassignTo != nil {
+		target, err := s.categoryRepo.GetByID(ctx, userID, *reassignTo)
+		if err != nil {
+			return err
+		}
+		if target.ArchivedAt.Valid {
+			return errors.ErrCategoryArchived
+		}
+	}
+
+	if err := s.categoryRepo.Delete(ctx, userID, id, reassignTo); err != nil {
+		if err == errors.ErrCategoryInUse {
+			s.logger.Warn(ctx, "Attempt to delete category still in use", logger.Fields{
+				"user_id":     userID,
+				"category_id": id,
+			})
+		}
+		return err
+	}
+
+	s.logger.Info(ctx, "Category deleted", logger.Fields{
+		"user_id":     userID,
+		"category_id": id,
+		"reassign_to": reassignTo,
+	})
+
+	return nil
+}
+
+// ensureOwned rejects changes to categories the user can see but does not own.
+func (s *categoryService) ensureOwned(ctx context.Context, userID uuid.UUID, id uuid.UUID) error {
+	category, err := s.categoryRepo.GetByID(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if category.IsDefault() {
+		return errors.ErrCategoryReadOnly
+	}
+	return nil
+}
+
+func toCategoryData(category *repository.Category) *dto.CategoryData {
+	data := &dto.CategoryData{
+		ID:        category.ID,
+		Name:      category.Name,
+		IsDefault: category.IsDefault(),
+		CreatedAt: category.CreatedAt,
+		UpdatedAt: category.UpdatedAt,
+	}
+	if category.ArchivedAt.Valid {
+		archivedAt := category.ArchivedAt.Time
+		data.ArchivedAt = &archivedAt
+	}
+	return data
+}
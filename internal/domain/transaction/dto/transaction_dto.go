@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"devsecops-be/pkg/response"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const DateLayout = "2006-01-02"
+
+type CreateTransactionRequest struct {
+	Type       string     `json:"type" validate:"required,oneof=income expense" example:"expense"`
+	Amount     float64    `json:"amount" validate:"required,gt=0,lt=10000000000" example:"25000"`
+	CategoryID *uuid.UUID `json:"category_id" example:"3f1c2a8e-8a5b-4c1d-9f0e-2b7a6d5c4e3f"`
+	Note       *string    `json:"note" validate:"omitempty,max=1000" example:"Lunch"`
+	Date       string     `json:"date" validate:"required,datetime=2006-01-02" example:"2025-01-31"`
+	ProofFile  *string    `json:"proof_file" validate:"omitempty,max=255" example:"receipts/lunch.jpg"`
+}
+
+type UpdateTransactionRequest struct {
+	Type       string     `json:"type" validate:"required,oneof=income expense" example:"expense"`
+	Amount     float64    `json:"amount" validate:"required,gt=0,lt=10000000000" example:"25000"`
+	CategoryID *uuid.UUID `json:"category_id" example:"3f1c2a8e-8a5b-4c1d-9f0e-2b7a6d5c4e3f"`
+	Note       *string    `json:"note" validate:"omitempty,max=1000" example:"Lunch"`
+	Date       string     `json:"date" validate:"required,datetime=2006-01-02" example:"2025-01-31"`
+	ProofFile  *string    `json:"proof_file" validate:"omitempty,max=255" example:"receipts/lunch.jpg"`
+}
+
+type TransactionFilter struct {
+	Type       string `query:"type" validate:"omitempty,oneof=income expense"`
+	CategoryID string `query:"category_id" validate:"omitempty,uuid"`
+	StartDate  string `query:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate    string `query:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	Page       int    `query:"page" validate:"omitempty,min=1"`
+	Limit      int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type TransactionData struct {
+	ID         int              `json:"id"`
+	Type       string           `json:"type"`
+	Amount     float64          `json:"amount"`
+	CategoryID *uuid.UUID       `json:"category_id"`
+	Category   *CategorySummary `json:"category"`
+	Note       *string          `json:"note"`
+	Date       string           `json:"date"`
+	ProofFile  *string          `json:"proof_file"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+}
+
+type CategorySummary struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+type TransactionListResponse struct {
+	Transactions []TransactionData   `json:"transactions"`
+	Pagination   response.Pagination `json:"pagination"`
+}
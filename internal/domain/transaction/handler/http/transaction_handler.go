@@ -0,0 +1,173 @@
+package http
+
+import (
+	"devsecops-be/internal/domain/transaction/dto"
+	"devsecops-be/internal/domain/transaction/service"
+	"devsecops-be/internal/middleware"
+	"devsecops-be/pkg/errors"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/response"
+	"devsecops-be/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TransactionHandler struct {
+	transactionService service.TransactionService
+	validator          validator.Validator
+	logger             logger.Logger
+}
+
+func NewTransactionHandler(
+	transactionService service.TransactionService,
+	validator validator.Validator,
+	logger logger.Logger,
+) *TransactionHandler {
+	return &TransactionHandler{
+		transactionService: transactionService,
+		validator:          validator,
+		logger:             logger,
+	}
+}
+
+func (h *TransactionHandler) Create(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	var req dto.CreateTransactionRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in create transaction", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in create transaction", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.transactionService.Create(ctx, userID, req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Created(c, "Transaction created successfully", result)
+}
+
+func (h *TransactionHandler) List(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	var filter dto.TransactionFilter
+
+	if err := c.QueryParser(&filter); err != nil {
+		h.logger.Warn(ctx, "Invalid query parameters in list transactions", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid query parameters", nil)
+	}
+
+	if err := h.validator.Validate(filter); err != nil {
+		h.logger.Warn(ctx, "Validation failed in list transactions", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.transactionService.List(ctx, userID, filter)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Transactions retrieved successfully", result)
+}
+
+func (h *TransactionHandler) GetByID(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.BadRequest(c, "Invalid transaction ID", nil)
+	}
+
+	result, err := h.transactionService.GetByID(ctx, userID, id)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Transaction retrieved successfully", result)
+}
+
+func (h *TransactionHandler) Update(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.BadRequest(c, "Invalid transaction ID", nil)
+	}
+
+	var req dto.UpdateTransactionRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Warn(ctx, "Invalid request body in update transaction", logger.Fields{
+			"error": err.Error(),
+		})
+		return response.BadRequest(c, "Invalid request body", nil)
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		h.logger.Warn(ctx, "Validation failed in update transaction", logger.Fields{
+			"validation_errors": err,
+		})
+		return response.BadRequest(c, "Validation failed", err)
+	}
+
+	result, err := h.transactionService.Update(ctx, userID, id, req)
+	if err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Transaction updated successfully", result)
+}
+
+func (h *TransactionHandler) Delete(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.BadRequest(c, "Invalid transaction ID", nil)
+	}
+
+	if err := h.transactionService.Delete(ctx, userID, id); err != nil {
+		return errors.HandleHTTPError(c, err)
+	}
+
+	return response.Success(c, "Transaction deleted successfully", nil)
+}
@@ -0,0 +1,52 @@
+package transaction
+
+import (
+	"database/sql"
+	auditService "devsecops-be/internal/domain/audit/service"
+	"devsecops-be/internal/domain/transaction/handler/http"
+	"devsecops-be/internal/domain/transaction/repository"
+	"devsecops-be/internal/domain/transaction/service"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TransactionModule struct {
+	Handler *http.TransactionHandler
+	Service service.TransactionService
+}
+
+func NewTransactionModule(
+	db *sql.DB,
+	spendingMonitor service.SpendingMonitor,
+	audit auditService.AuditService,
+	logger logger.Logger,
+) *TransactionModule {
+	// Initialize dependencies
+	transactionRepo := repository.NewTransactionRepository(db)
+	validator := validator.NewValidator()
+
+	// Initialize service
+	transactionService := service.NewTransactionService(transactionRepo, spendingMonitor, audit, logger)
+
+	// Initialize handler
+	transactionHandler := http.NewTransactionHandler(transactionService, validator, logger)
+
+	return &TransactionModule{
+		Handler: transactionHandler,
+		Service: transactionService,
+	}
+}
+
+// RegisterRoutes mounts the transaction routes. Reads only need authMiddleware;
+// writes go through verifiedMiddleware, which also requires a verified email.
+func (m *TransactionModule) RegisterRoutes(app *fiber.App, authMiddleware, verifiedMiddleware fiber.Handler) {
+	transactions := app.Group("/api/v1/transactions")
+
+	transactions.Post("/", verifiedMiddleware, m.Handler.Create)
+	transactions.Get("/", authMiddleware, m.Handler.List)
+	transactions.Get("/:id", authMiddleware, m.Handler.GetByID)
+	transactions.Put("/:id", verifiedMiddleware, m.Handler.Update)
+	transactions.Delete("/:id", verifiedMiddleware, m.Handler.Delete)
+}
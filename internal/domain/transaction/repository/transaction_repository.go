@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/internal/domain/transaction/dto"
+	"devsecops-be/pkg/errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type TransactionRepository interface {
+	Create(ctx context.Context, tx *Transaction) (*Transaction, error)
+	GetByID(ctx context.Context, userID uuid.UUID, id int) (*Transaction, error)
+	List(ctx context.Context, userID uuid.UUID, filter dto.TransactionFilter) ([]Transaction, int, error)
+	Update(ctx context.Context, tx *Transaction) (*Transaction, error)
+	Delete(ctx context.Context, userID uuid.UUID, id int) error
+}
+
+type Transaction struct {
+	ID         int            `db:"id"`
+	UserID     uuid.UUID      `db:"user_id"`
+	CategoryID uuid.NullUUID  `db:"category_id"`
+	Category   sql.NullString `db:"category_name"`
+	Type       string         `db:"type"`
+	Amount     float64        `db:"amount"`
+	Note       sql.NullString `db:"note"`
+	Date       time.Time      `db:"date"`
+	ProofFile  sql.NullString `db:"proof_file"`
+	CreatedAt  time.Time      `db:"created_at"`
+	UpdatedAt  time.Time      `db:"updated_at"`
+}
+
+// transactionColumns selects from a transactions row aliased as t joined to categories as c.
+const transactionColumns = `t.id, t.user_id, t.category_id, c.name, t.type, t.amount, t.note, t.date, t.proof_file, t.created_at, t.updated_at`
+
+const categoryJoin = `LEFT JOIN categories c ON c.id = t.category_id`
+
+type transactionRepository struct {
+	db *sql.DB
+}
+
+func NewTransactionRepository(db *sql.DB) TransactionRepository {
+	return &transactionRepository{db: db}
+}
+
+func (r *transactionRepository) Create(ctx context.Context, tx *Transaction) (*Transaction, error) {
+	if err := r.ensureCategoryAssignable(ctx, tx.UserID, tx.CategoryID, 0); err != nil {
+		return nil, err
+	}
+
+	query := `
+        WITH t AS (
+            INSERT INTO transactions (user_id, category_id, type, amount, note, date, proof_file)
+            VALUES ($1, $2, $3, $4, $5, $6, $7)
+            RETURNING *
+        )
+        SELECT ` + transactionColumns + ` FROM t ` + categoryJoin
+
+	row := r.db.QueryRowContext(ctx, query,
+		tx.UserID, tx.CategoryID, tx.Type, tx.Amount, tx.Note, tx.Date, tx.ProofFile)
+
+	created, err := scanTransaction(row)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, errors.ErrCategoryNotFound
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to create transaction")
+	}
+
+	return created, nil
+}
+
+func (r *transactionRepository) GetByID(ctx context.Context, userID uuid.UUID, id int) (*Transaction, error) {
+	query := `
+        SELECT ` + transactionColumns + `
+        FROM transactions t ` + categoryJoin + `
+        WHERE t.id = $1 AND t.user_id = $2
+    `
+
+	tx, err := scanTransaction(r.db.QueryRowContext(ctx, query, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrTransactionNotFound
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to get transaction by ID")
+	}
+
+	return tx, nil
+}
+
+func (r *transactionRepository) List(ctx context.Context, userID uuid.UUID, filter dto.TransactionFilter) ([]Transaction, int, error) {
+	conditions := []string{"t.user_id = $1"}
+	args := []interface{}{userID}
+
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		conditions = append(conditions, fmt.Sprintf("t.type = $%d", len(args)))
+	}
+	if filter.CategoryID != "" {
+		args = append(args, filter.CategoryID)
+		conditions = append(conditions, fmt.Sprintf("t.category_id = $%d", len(args)))
+	}
+	if filter.StartDate != "" {
+		args = append(args, filter.StartDate)
+		conditions = append(conditions, fmt.Sprintf("t.date >= $%d::date", len(args)))
+	}
+	if filter.EndDate != "" {
+		args = append(args, filter.EndDate)
+		conditions = append(conditions, fmt.Sprintf("t.date <= $%d::date", len(args)))
+	}
+
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM transactions t WHERE ` + where
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, errors.WrapDatabaseError(err, "failed to count transactions")
+	}
+
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	query := fmt.Sprintf(`
+        SELECT %s
+        FROM transactions t %s
+        WHERE %s
+        ORDER BY t.date DESC, t.id DESC
+        LIMIT $%d OFFSET $%d
+    `, transactionColumns, categoryJoin, where, len(args)-1, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.WrapDatabaseError(err, "failed to list transactions")
+	}
+	defer rows.Close()
+
+	transactions := []Transaction{}
+	for rows.Next() {
+		tx, err := scanTransaction(rows)
+		if err != nil {
+			return nil, 0, errors.WrapDatabaseError(err, "failed to scan transaction")
+		}
+		transactions = append(transactions, *tx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.WrapDatabaseError(err, "failed to iterate transactions")
+	}
+
+	return transactions, total, nil
+}
+
+func (r *transactionRepository) Update(ctx context.Context, tx *Transaction) (*Transaction, error) {
+	if err := r.ensureCategoryAssignable(ctx, tx.UserID, tx.CategoryID, tx.ID); err != nil {
+		return nil, err
+	}
+
+	query := `
+        WITH t AS (
+            UPDATE transactions
+            SET category_id = $1, type = $2, amount = $3, note = $4, date = $5, proof_file = $6, updated_at = NOW()
+            WHERE id = $7 AND user_id = $8
+            RETURNING *
+        )
+        SELECT ` + transactionColumns + ` FROM t ` + categoryJoin
+
+	row := r.db.QueryRowContext(ctx, query,
+		tx.CategoryID, tx.Type, tx.Amount, tx.Note, tx.Date, tx.ProofFile, tx.ID, tx.UserID)
+
+	updated, err := scanTransaction(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrTransactionNotFound
+		}
+		if isForeignKeyViolation(err) {
+			return nil, errors.ErrCategoryNotFound
+		}
+		return nil, errors.WrapDatabaseError(err, "failed to update transaction")
+	}
+
+	return updated, nil
+}
+
+func (r *transactionRepository) Delete(ctx context.Context, userID uuid.UUID, id int) error {
+	query := `DELETE FROM transactions WHERE id = $1 AND user_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return errors.WrapDatabaseError(err, "failed to delete transaction")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.WrapDatabaseError(err, "failed to delete transaction")
+	}
+	if affected == 0 {
+		return errors.ErrTransactionNotFound
+	}
+
+	return nil
+}
+
+// ensureCategoryAssignable checks that the category is a default or one of the
+// user's own categories. Archived categories may only be kept by a transaction
+// that already uses them.
+func (r *transactionRepository) ensureCategoryAssignable(ctx context.Context, userID uuid.UUID, categoryID uuid.NullUUID, transactionID int) error {
+	if !categoryID.Valid {
+		return nil
+	}
+
+	query := `
+        SELECT EXISTS (
+            SELECT 1
+            FROM categories
+            WHERE id = $1
+              AND (user_id IS NULL OR user_id = $2)
+              AND (
+                  archived_at IS NULL
+                  OR id = (SELECT category_id FROM transactions WHERE id = $3 AND user_id = $2)
+              )
+        )
+    `
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, categoryID.UUID, userID, transactionID).Scan(&exists); err != nil {
+		return errors.WrapDatabaseError(err, "failed to check category")
+	}
+	if !exists {
+		return errors.ErrCategoryNotFound
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTransaction(row rowScanner) (*Transaction, error) {
+	var tx Transaction
+	err := row.Scan(
+		&tx.ID, &tx.UserID, &tx.CategoryID, &tx.Category, &tx.Type, &tx.Amount,
+		&tx.Note, &tx.Date, &tx.ProofFile, &tx.CreatedAt, &tx.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+func isForeignKeyViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23503"
+}
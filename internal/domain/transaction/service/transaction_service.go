@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	auditService "devsecops-be/internal/domain/audit/service"
+	"devsecops-be/internal/domain/transaction/dto"
+	"devsecops-be/internal/domain/transaction/repository"
+	"devsecops-be/pkg/errors"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/response"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPage  = 1
+	defaultLimit = 20
+)
+
+type TransactionService interface {
+	Create(ctx context.Context, userID uuid.UUID, req dto.CreateTransactionRequest) (*dto.TransactionData, error)
+	GetByID(ctx context.Context, userID uuid.UUID, id int) (*dto.TransactionData, error)
+	List(ctx context.Context, userID uuid.UUID, filter dto.TransactionFilter) (*dto.TransactionListResponse, error)
+	Update(ctx context.Context, userID uuid.UUID, id int, req dto.UpdateTransactionRequest) (*dto.TransactionData, error)
+	Delete(ctx context.Context, userID uuid.UUID, id int) error
+}
+
+// SpendingMonitor is notified after expense transactions change so it can
+// raise limit alerts for the affected periods.
+type SpendingMonitor interface {
+	CheckSpending(ctx context.Context, userID uuid.UUID, date time.Time) error
+}
+
+type transactionService struct {
+	transactionRepo repository.TransactionRepository
+	spendingMonitor SpendingMonitor
+	audit           auditService.AuditService
+	logger          logger.Logger
+}
+
+func NewTransactionService(
+	transactionRepo repository.TransactionRepository,
+	spendingMonitor SpendingMonitor,
+	audit auditService.AuditService,
+	logger logger.Logger,
+) TransactionService {
+	return &transactionService{
+		transactionRepo: transactionRepo,
+		spendingMonitor: spendingMonitor,
+		audit:           audit,
+		logger:          logger,
+	}
+}
+
+func (s *transactionService) Create(ctx context.Context, userID uuid.UUID, req dto.CreateTransactionRequest) (*dto.TransactionData, error) {
+	date, err := time.Parse(dto.DateLayout, req.Date)
+	if err != nil {
+		return nil, errors.WrapValidationError(err, "invalid date")
+	}
+
+	tx, err := s.transactionRepo.Create(ctx, &repository.Transaction{
+		UserID:     userID,
+		CategoryID: toNullUUID(req.CategoryID),
+		Type:       req.Type,
+		Amount:     req.Amount,
+		Note:       toNullString(req.Note),
+		Date:       date,
+		ProofFile:  toNullString(req.ProofFile),
+	})
+	if err != nil {
+		s.logger.Error(ctx, "Failed to create transaction", err, logger.Fields{
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Transaction created", logger.Fields{
+		"user_id":        userID,
+		"transaction_id": tx.ID,
+		"type":           tx.Type,
+	})
+
+	data := toTransactionData(tx)
+	s.recordAudit(ctx, auditService.ActionTransactionCreate, userID, tx.ID, nil, data)
+	s.checkSpending(ctx, tx)
+
+	return data, nil
+}
+
+func (s *transactionService) GetByID(ctx context.Context, userID uuid.UUID, id int) (*dto.TransactionData, error) {
+	tx, err := s.transactionRepo.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toTransactionData(tx), nil
+}
+
+func (s *transactionService) List(ctx context.Context, userID uuid.UUID, filter dto.TransactionFilter) (*dto.TransactionListResponse, error) {
+	if filter.Page <= 0 {
+		filter.Page = defaultPage
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
+	}
+
+	transactions, total, err := s.transactionRepo.List(ctx, userID, filter)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list transactions", err, logger.Fields{
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	data := make([]dto.TransactionData, 0, len(transactions))
+	for i := range transactions {
+		data = append(data, *toTransactionData(&transactions[i]))
+	}
+
+	return &dto.TransactionListResponse{
+		Transactions: data,
+		Pagination:   response.NewPagination(filter.Page, filter.Limit, total),
+	}, nil
+}
+
+func (s *transactionService) Update(ctx context.Context, userID uuid.UUID, id int, req dto.UpdateTransactionRequest) (*dto.TransactionData, error) {
+	date, err := time.Parse(dto.DateLayout, req.Date)
+	if err != nil {
+		return nil, errors.WrapValidationError(err, "invalid date")
+	}
+
+	before, err := s.transactionRepo.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.transactionRepo.Update(ctx, &repository.Transaction{
+		ID:         id,
+		UserID:     userID,
+		CategoryID: toNullUUID(req.CategoryID),
+		Type:       req.Type,
+		Amount:     req.Amount,
+		Note:       toNullString(req.Note),
+		Date:       date,
+		ProofFile:  toNullString(req.ProofFile),
+	})
+	if err != nil {
+		if err != errors.ErrTransactionNotFound {
+			s.logger.Error(ctx, "Failed to update transaction", err, logger.Fields{
+				"user_id":        userID,
+				"transaction_id": id,
+			})
+		}
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Transaction updated", logger.Fields{
+		"user_id":        userID,
+		"transaction_id": tx.ID,
+	})
+
+	data := toTransactionData(tx)
+	s.recordAudit(ctx, auditService.ActionTransactionUpdate, userID, tx.ID, toTransactionData(before), data)
+	s.checkSpending(ctx, tx)
+
+	return data, nil
+}
+
+func (s *transactionService) Delete(ctx context.Context, userID uuid.UUID, id int) error {
+	before, err := s.transactionRepo.GetByID(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.transactionRepo.Delete(ctx, userID, id); err != nil {
+		if err != errors.ErrTransactionNotFound {
+			s.logger.Error(ctx, "Failed to delete transaction", err, logger.Fields{
+				"user_id":        userID,
+				"transaction_id": id,
+			})
+		}
+		return err
+	}
+
+	s.logger.Info(ctx, "Transaction deleted", logger.Fields{
+		"user_id":        userID,
+		"transaction_id": id,
+	})
+
+	s.recordAudit(ctx, auditService.ActionTransactionDelete, userID, id, toTransactionData(before), nil)
+
+	return nil
+}
+
+func (s *transactionService) recordAudit(ctx context.Context, action string, userID uuid.UUID, id int, before, after *dto.TransactionData) {
+	var beforeValue, afterValue interface{}
+	if before != nil {
+		beforeValue = before
+	}
+	if after != nil {
+		afterValue = after
+	}
+
+	changes := auditService.Diff(beforeValue, afterValue)
+	delete(changes, "updated_at")
+
+	s.audit.Record(ctx, auditService.Event{
+		UserID:       &userID,
+		Action:       action,
+		ResourceType: "transaction",
+		ResourceID:   strconv.Itoa(id),
+		Changes:      changes,
+	})
+}
+
+// checkSpending runs limit checks for expense transactions. Failures are logged
+// rather than returned so that a saved transaction is never reported as failed.
+func (s *transactionService) checkSpending(ctx context.Context, tx *repository.Transaction) {
+	if s.spendingMonitor == nil || tx.Type != "expense" {
+		return
+	}
+
+	if err := s.spendingMonitor.CheckSpending(ctx, tx.UserID, tx.Date); err != nil {
+		s.logger.Error(ctx, "Failed to check spending limits", err, logger.Fields{
+			"user_id":        tx.UserID,
+			"transaction_id": tx.ID,
+		})
+	}
+}
+
+func toTransactionData(tx *repository.Transaction) *dto.TransactionData {
+	data := &dto.TransactionData{
+		ID:        tx.ID,
+		Type:      tx.Type,
+		Amount:    tx.Amount,
+		Date:      tx.Date.Format(dto.DateLayout),
+		CreatedAt: tx.CreatedAt,
+		UpdatedAt: tx.UpdatedAt,
+	}
+	if tx.CategoryID.Valid {
+		categoryID := tx.CategoryID.UUID
+		data.CategoryID = &categoryID
+		data.Category = &dto.CategorySummary{
+			ID:   categoryID,
+			Name: tx.Category.String,
+		}
+	}
+	if tx.Note.Valid {
+		note := tx.Note.String
+		data.Note = &note
+	}
+	if tx.ProofFile.Valid {
+		proofFile := tx.ProofFile.String
+		data.ProofFile = &proofFile
+	}
+	return data
+}
+
+func toNullUUID(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
+
+func toNullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
@@ -2,31 +2,87 @@ package routes
 
 import (
 	"devsecops-be/internal/middleware"
+	"devsecops-be/pkg/health"
 	"devsecops-be/pkg/jwt"
 	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/rbac"
+	"devsecops-be/pkg/response"
+	"devsecops-be/pkg/revocation"
+	"devsecops-be/pkg/version"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
-func RegisterRoutes(app *fiber.App, jwtUtil jwt.JWTUtil, appLogger logger.Logger) {
-	// Public health check
-	app.Get("/health", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"success": true,
-			"message": "Server is healthy",
-			"data": fiber.Map{
-				"timestamp": time.Now().Unix(),
-				"service":   "devsecops-be",
-				"version":   "1.0.0",
-			},
+func RegisterRoutes(app *fiber.App, jwtUtil jwt.JWTUtil, revocationStore revocation.Store, authorizer rbac.Authorizer, healthChecks *health.Registry, appLogger logger.Logger) {
+	build := version.Get()
+	startedAt := time.Now()
+
+	// Liveness only tells the orchestrator the process still serves requests,
+	// dependencies are left to readiness so an outage does not restart it
+	app.Get("/health/live", func(c *fiber.Ctx) error {
+		return response.Success(c, "Server is alive", fiber.Map{
+			"status":         health.StatusUp,
+			"service":        "devsecops-be",
+			"version":        build.Version,
+			"uptime_seconds": int64(time.Since(startedAt).Seconds()),
 		})
 	})
 
+	// Readiness runs the registered dependency checks, /health is kept for
+	// existing monitors. Only the statuses are public, errors and details
+	// are logged and served by /health/report
+	readiness := func(c *fiber.Ctx) error {
+		report := healthChecks.Run(c.UserContext())
+		summary := report.Summary()
+		data := fiber.Map{
+			"status":    summary.Status,
+			"service":   "devsecops-be",
+			"version":   build.Version,
+			"timestamp": time.Now().Unix(),
+			"checks":    summary.Checks,
+		}
+
+		if report.Status != health.StatusUp {
+			appLogger.Warn(c.UserContext(), "Readiness check failed", logger.Fields{
+				"checks": report.Checks,
+			})
+			return c.Status(fiber.StatusServiceUnavailable).JSON(response.Response{
+				Success: false,
+				Message: "Server is not ready",
+				Data:    data,
+			})
+		}
+		return response.Success(c, "Server is ready", data)
+	}
+	app.Get("/health/ready", readiness)
+	app.Get("/health", readiness)
+
+	// Public keys for verifying access tokens
+	app.Get("/.well-known/jwks.json", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.JSON(jwtUtil.JWKS())
+	})
+
+	authMiddleware := middleware.AuthMiddleware(jwtUtil, revocationStore, appLogger)
+
+	// Full report with errors and details, for operators
+	app.Get("/health/report", authMiddleware, middleware.RequirePermission(authorizer, rbac.PermUsersReadAny, appLogger), func(c *fiber.Ctx) error {
+		report := healthChecks.Run(c.UserContext())
+		if report.Status != health.StatusUp {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(response.Response{
+				Success: false,
+				Message: "Server is not ready",
+				Data:    report,
+			})
+		}
+		return response.Success(c, "Health report retrieved successfully", report)
+	})
+
 	// Protected health check
-	authMiddleware := middleware.AuthMiddleware(jwtUtil, appLogger)
 	app.Get("/health/protected", authMiddleware, func(c *fiber.Ctx) error {
-		userID := c.Locals("user_id").(int)
+		userID := c.Locals("user_id").(uuid.UUID)
 		return c.JSON(fiber.Map{
 			"success": true,
 			"message": "Protected endpoint accessible",
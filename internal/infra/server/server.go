@@ -2,11 +2,11 @@ package server
 
 import (
 	"context"
-	"devsecops-be/config/env"
 	"devsecops-be/pkg/logger"
 
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -15,15 +15,17 @@ import (
 
 type Server struct {
 	app    *fiber.App
+	port   int
+	env    string
 	logger logger.Logger
 }
 
-func NewServer(app *fiber.App, logger logger.Logger) *Server {
-	return &Server{app: app, logger: logger}
+func NewServer(app *fiber.App, port int, env string, logger logger.Logger) *Server {
+	return &Server{app: app, port: port, env: env, logger: logger}
 }
 
 func (s *Server) Start() {
-	port := env.GetEnv("PORT", "8000")
+	port := strconv.Itoa(s.port)
 
 	// Graceful shutdown
 	c := make(chan os.Signal, 1)
@@ -32,7 +34,7 @@ func (s *Server) Start() {
 	go func() {
 		s.logger.Info(context.Background(), "Server starting", logger.Fields{
 			"port": port,
-			"env":  env.GetEnv("ENV", "development"),
+			"env":  s.env,
 		})
 
 		if err := s.app.Listen(":" + port); err != nil {
@@ -1,15 +1,48 @@
 package middleware
 
 import (
+    "context"
     "devsecops-be/pkg/errors"
     "devsecops-be/pkg/jwt"
     "devsecops-be/pkg/logger"
+    "devsecops-be/pkg/rbac"
+    "devsecops-be/pkg/revocation"
     "strings"
+    "time"
 
     "github.com/gofiber/fiber/v2"
+    "github.com/google/uuid"
 )
 
-func AuthMiddleware(jwtUtil jwt.JWTUtil, log logger.Logger) fiber.Handler {
+// EmailVerificationChecker reports whether a user has verified their email.
+type EmailVerificationChecker interface {
+    IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+type authOptions struct {
+    verificationChecker EmailVerificationChecker
+    apiKeys             APIKeyAuthenticator
+    userRateLimit       *RateLimitConfig
+    apiKeyRateLimit     *RateLimitConfig
+}
+
+// AuthOption customizes AuthMiddleware.
+type AuthOption func(*authOptions)
+
+// RequireVerifiedEmail rejects users whose email is not verified yet. Use it
+// on routes that unverified accounts must not reach; login stays open to them.
+func RequireVerifiedEmail(checker EmailVerificationChecker) AuthOption {
+    return func(o *authOptions) {
+        o.verificationChecker = checker
+    }
+}
+
+func AuthMiddleware(jwtUtil jwt.JWTUtil, revocationStore revocation.Store, log logger.Logger, opts ...AuthOption) fiber.Handler {
+    options := &authOptions{}
+    for _, opt := range opts {
+        opt(options)
+    }
+
     return func(c *fiber.Ctx) error {
         authHeader := c.Get("Authorization")
         if authHeader == "" {
@@ -20,6 +53,11 @@ func AuthMiddleware(jwtUtil jwt.JWTUtil, log logger.Logger) fiber.Handler {
             return errors.HandleHTTPError(c, errors.ErrTokenRequired)
         }
 
+        // API keys are only accepted on routes that opted in with AllowAPIKeys
+        if options.apiKeys != nil && strings.HasPrefix(authHeader, apiKeyScheme) {
+            return authenticateAPIKey(c, options, strings.TrimPrefix(authHeader, apiKeyScheme), log)
+        }
+
         // Check Bearer format
         if !strings.HasPrefix(authHeader, "Bearer ") {
             log.Warn(c.Context(), "Invalid token format", logger.Fields{
@@ -42,8 +80,8 @@ func AuthMiddleware(jwtUtil jwt.JWTUtil, log logger.Logger) fiber.Handler {
             return errors.HandleHTTPError(c, err)
         }
 
-        // Check token type
-        if tokenType, ok := claims["type"].(string); !ok || tokenType != "access" {
+        // Check token type, single-purpose tokens such as mfa_pending are never accepted here
+        if tokenType, ok := claims["type"].(string); !ok || tokenType != jwt.TokenTypeAccess {
             log.Warn(c.Context(), "Invalid token type", logger.Fields{
                 "path":       c.Path(),
                 "ip":         c.IP(),
@@ -52,17 +90,137 @@ func AuthMiddleware(jwtUtil jwt.JWTUtil, log logger.Logger) fiber.Handler {
             return errors.HandleHTTPError(c, errors.ErrInvalidToken)
         }
 
+        // Resolve the token owner
+        rawUserID, _ := claims["user_id"].(string)
+        userID, err := uuid.Parse(rawUserID)
+        if err != nil {
+            log.Warn(c.Context(), "Invalid user_id claim", logger.Fields{
+                "path": c.Path(),
+                "ip":   c.IP(),
+            })
+            return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+        }
+
+        // Tokens issued before roles existed carry no role claim and get the least privileged one
+        role, _ := claims["role"].(string)
+        if role == "" {
+            role = rbac.RoleUser
+        }
+
+        jti, _ := claims["jti"].(string)
+        iat, _ := claims["iat"].(float64)
+        exp, _ := claims["exp"].(float64)
+        if jti == "" {
+            log.Warn(c.Context(), "Token without jti claim", logger.Fields{
+                "path":    c.Path(),
+                "ip":      c.IP(),
+                "user_id": userID,
+            })
+            return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+        }
+
+        // Tokens issued before sessions existed carry no sid claim
+        revocationIDs := []string{jti}
+        rawSessionID, _ := claims["sid"].(string)
+        sessionID, sessionErr := uuid.Parse(rawSessionID)
+        if sessionErr == nil {
+            revocationIDs = append(revocationIDs, sessionID.String())
+        }
+
+        revoked, err := revocationStore.IsRevoked(c.UserContext(), revocationIDs, userID, time.Unix(int64(iat), 0))
+        if err != nil {
+            log.Error(c.Context(), "Failed to check token revocation", err, logger.Fields{
+                "path":    c.Path(),
+                "user_id": userID,
+            })
+            return errors.HandleHTTPError(c, errors.ErrInternalServer)
+        }
+        if revoked {
+            log.Warn(c.Context(), "Revoked token used", logger.Fields{
+                "path":    c.Path(),
+                "ip":      c.IP(),
+                "user_id": userID,
+            })
+            return errors.HandleHTTPError(c, errors.ErrInvalidToken)
+        }
+
+        if err := checkVerifiedEmail(c, options, userID, log); err != nil {
+            return errors.HandleHTTPError(c, err)
+        }
+
         // Set user info in context
-        userID := int(claims["user_id"].(float64))
         c.Locals("user_id", userID)
+        c.Locals("role", role)
         c.Locals("token", token)
+        c.Locals("jti", jti)
+        if sessionErr == nil {
+            c.Locals("session_id", sessionID)
+        }
+        c.Locals("token_expires_at", time.Unix(int64(exp), 0))
 
         log.Debug(c.Context(), "Token validation successful", logger.Fields{
             "user_id": userID,
             "path":    c.Path(),
         })
 
+        if options.userRateLimit != nil {
+            if err := takeToken(c, *options.userRateLimit, log); err != nil {
+                return errors.HandleHTTPError(c, err)
+            }
+        }
+
         return c.Next()
     }
 }
 
+// checkVerifiedEmail enforces RequireVerifiedEmail when the option is set.
+func checkVerifiedEmail(c *fiber.Ctx, options *authOptions, userID uuid.UUID, log logger.Logger) error {
+    if options.verificationChecker == nil {
+        return nil
+    }
+
+    verified, err := options.verificationChecker.IsEmailVerified(c.UserContext(), userID)
+    if err != nil {
+        log.Error(c.Context(), "Failed to check email verification", err, logger.Fields{
+            "path":    c.Path(),
+            "user_id": userID,
+        })
+        return err
+    }
+    if !verified {
+        log.Warn(c.Context(), "Unverified user blocked", logger.Fields{
+            "path":    c.Path(),
+            "user_id": userID,
+        })
+        return errors.ErrEmailNotVerified
+    }
+
+    return nil
+}
+
+// GetUserID returns the authenticated user ID set by AuthMiddleware.
+func GetUserID(c *fiber.Ctx) (uuid.UUID, bool) {
+    userID, ok := c.Locals("user_id").(uuid.UUID)
+    return userID, ok
+}
+
+// GetRole returns the role of the authenticated user, taken from the access
+// token or from the owner of the API key.
+func GetRole(c *fiber.Ctx) (string, bool) {
+    role, ok := c.Locals("role").(string)
+    return role, ok
+}
+
+// GetSessionID returns the session of the access token validated by
+// AuthMiddleware. It reports false for API keys and tokens without a session.
+func GetSessionID(c *fiber.Ctx) (uuid.UUID, bool) {
+    sessionID, ok := c.Locals("session_id").(uuid.UUID)
+    return sessionID, ok
+}
+
+// GetTokenID returns the jti and expiry of the access token validated by AuthMiddleware.
+func GetTokenID(c *fiber.Ctx) (string, time.Time) {
+    jti, _ := c.Locals("jti").(string)
+    expiresAt, _ := c.Locals("token_expires_at").(time.Time)
+    return jti, expiresAt
+}
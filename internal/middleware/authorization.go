@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"devsecops-be/pkg/errors"
+	"devsecops-be/pkg/logger"
+	"devsecops-be/pkg/rbac"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequirePermission rejects requests whose role does not grant permission. It
+// must run after AuthMiddleware, which sets the role from the access token.
+func RequirePermission(authorizer rbac.Authorizer, permission string, log logger.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, _ := GetUserID(c)
+		role, ok := GetRole(c)
+		if !ok {
+			log.Warn(c.Context(), "Permission check without authenticated user", logger.Fields{
+				"path":       c.Path(),
+				"permission": permission,
+			})
+			return errors.HandleHTTPError(c, errors.ErrTokenRequired)
+		}
+
+		allowed, err := authorizer.HasPermission(c.UserContext(), role, permission)
+		if err != nil {
+			log.Error(c.Context(), "Failed to check permission", err, logger.Fields{
+				"path":       c.Path(),
+				"user_id":    userID,
+				"permission": permission,
+			})
+			return errors.HandleHTTPError(c, errors.ErrInternalServer)
+		}
+		if !allowed {
+			log.Warn(c.Context(), "Permission denied", logger.Fields{
+				"path":       c.Path(),
+				"ip":         c.IP(),
+				"user_id":    userID,
+				"role":       role,
+				"permission": permission,
+			})
+			return errors.HandleHTTPError(c, errors.ErrPermissionDenied)
+		}
+
+		return c.Next()
+	}
+}
@@ -31,6 +31,11 @@ func FiberLogger(log logger.Logger) fiber.Handler {
             fields["query"] = string(c.Request().URI().QueryString())
         }
 
+        // Add request_id if assigned by the requestid middleware
+        if requestID, ok := c.Locals("requestid").(string); ok && requestID != "" {
+            fields["request_id"] = requestID
+        }
+
         // Add user_id if present in context
         if userID := c.Locals("user_id"); userID != nil {
             fields["user_id"] = userID
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+// maxRequestIDLength keeps client request IDs within the audit_logs column.
+const maxRequestIDLength = 64
+
+// RequestID reuses the X-Request-ID sent by the client, so requests can be
+// traced across services, when it is a short token. Anything else is
+// replaced by a generated ID.
+func RequestID() fiber.Handler {
+	assign := requestid.New()
+
+	return func(c *fiber.Ctx) error {
+		if !validRequestID(c.Get(fiber.HeaderXRequestID)) {
+			c.Request().Header.Del(fiber.HeaderXRequestID)
+		}
+		return assign(c)
+	}
+}
+
+func validRequestID(id string) bool {
+	if len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
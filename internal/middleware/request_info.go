@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"devsecops-be/pkg/requestinfo"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestInfo stores the client IP, user agent and request ID in the request's
+// user context so services can read them through requestinfo.FromContext.
+// It must run after the requestid middleware.
+func RequestInfo() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID, _ := c.Locals("requestid").(string)
+
+		c.SetUserContext(requestinfo.NewContext(c.UserContext(), requestinfo.Info{
+			IP:        c.IP(),
+			UserAgent: c.Get(fiber.HeaderUserAgent),
+			RequestID: requestID,
+		}))
+
+		return c.Next()
+	}
+}
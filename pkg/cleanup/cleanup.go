@@ -0,0 +1,38 @@
+// Package cleanup runs the periodic jobs that delete expired rows.
+package cleanup
+
+import (
+	"context"
+	"devsecops-be/pkg/logger"
+	"time"
+)
+
+// Func removes expired entries and returns how many were removed.
+type Func func(ctx context.Context) (int64, error)
+
+// Every runs fn every interval until ctx is cancelled. name describes what is
+// removed, such as "revoked tokens", in the log messages.
+func Every(ctx context.Context, interval time.Duration, name string, fn Func, log logger.Logger) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				removed, err := fn(ctx)
+				if err != nil {
+					log.Error(ctx, "Failed to clean up "+name, err)
+					continue
+				}
+				if removed > 0 {
+					log.Debug(ctx, "Cleaned up "+name, logger.Fields{
+						"removed": removed,
+					})
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,248 @@
+package database
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ApplyDSN overrides the config with the settings found in a postgres:// URL
+// or a libpq keyword/value string such as "host=db user=app sslmode=require".
+// Settings the DSN leaves out keep their current value.
+func (c *Config) ApplyDSN(dsn string) error {
+	dsn = strings.TrimSpace(dsn)
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		return applyURL(c, dsn)
+	}
+
+	params, err := parseKeywordValue(dsn)
+	if err != nil {
+		return err
+	}
+	for _, param := range params {
+		if err := c.set(param[0], param[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyURL(config *Config, rawURL string) error {
+	rawURL, socketDir, err := extractSocketHost(rawURL)
+	if err != nil {
+		return err
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// The error embeds the URL, password included
+		return fmt.Errorf("malformed URL")
+	}
+
+	if u.User != nil {
+		if username := u.User.Username(); username != "" {
+			config.User = username
+		}
+		if password, ok := u.User.Password(); ok {
+			config.Password = password
+		}
+	}
+
+	if socketDir != "" {
+		config.Host = socketDir
+	} else if host := u.Hostname(); host != "" {
+		config.Host = host
+	}
+	if port := u.Port(); port != "" {
+		if err := config.set("port", port); err != nil {
+			return err
+		}
+	}
+	if dbname := strings.TrimPrefix(u.Path, "/"); dbname != "" {
+		config.DBName = dbname
+	}
+
+	for key, values := range u.Query() {
+		if len(values) == 0 {
+			continue
+		}
+		if err := config.set(key, values[len(values)-1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractSocketHost handles a unix socket directory given as a
+// percent-encoded host, postgres://%2Fvar%2Frun%2Fpostgresql/db, which
+// net/url rejects. The host is swapped for a placeholder before parsing.
+func extractSocketHost(rawURL string) (string, string, error) {
+	scheme, rest, _ := strings.Cut(rawURL, "://")
+	authority := rest
+	if end := strings.IndexAny(rest, "/?"); end >= 0 {
+		authority = rest[:end]
+	}
+
+	hostStart := strings.LastIndex(authority, "@") + 1
+	host := authority[hostStart:]
+	if !strings.HasPrefix(strings.ToLower(host), "%2f") {
+		return rawURL, "", nil
+	}
+
+	port := ""
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		host, port = host[:i], host[i:]
+	}
+	socketDir, err := url.PathUnescape(host)
+	if err != nil {
+		return "", "", fmt.Errorf("malformed socket directory in URL")
+	}
+
+	rewritten := scheme + "://" + authority[:hostStart] + "localhost" + port + rest[len(authority):]
+	return rewritten, socketDir, nil
+}
+
+// set applies one libpq setting.
+func (c *Config) set(key, value string) error {
+	switch key {
+	case "host":
+		c.Host = value
+	case "port":
+		port, err := strconv.Atoi(value)
+		if err != nil || port <= 0 || port > 65535 {
+			return fmt.Errorf("invalid database port %q", value)
+		}
+		c.Port = port
+	case "user":
+		c.User = value
+	case "password":
+		c.Password = value
+	case "dbname":
+		c.DBName = value
+	case "sslmode":
+		if err := validateSSLMode(value); err != nil {
+			return err
+		}
+		c.SSLMode = value
+	case "sslrootcert":
+		c.SSLRootCert = value
+	case "sslcert":
+		c.SSLCert = value
+	case "sslkey":
+		c.SSLKey = value
+	case "connect_timeout":
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 0 {
+			return fmt.Errorf("invalid connect_timeout %q", value)
+		}
+		c.ConnectTimeout = seconds
+	case "application_name":
+		c.ApplicationName = value
+	case "search_path":
+		c.SearchPath = value
+	default:
+		return fmt.Errorf("unsupported database connection setting %q", key)
+	}
+	return nil
+}
+
+func validateSSLMode(mode string) error {
+	switch mode {
+	case "disable", "require", "verify-ca", "verify-full":
+		return nil
+	default:
+		return fmt.Errorf("invalid sslmode %q, expected disable, require, verify-ca or verify-full", mode)
+	}
+}
+
+// parseKeywordValue splits a libpq keyword/value string. Values may be
+// single-quoted, with \' and \\ escapes, to hold spaces.
+func parseKeywordValue(dsn string) ([][2]string, error) {
+	var params [][2]string
+
+	s := []rune(dsn)
+	for i := 0; i < len(s); {
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i == len(s) {
+			break
+		}
+
+		start := i
+		for i < len(s) && s[i] != '=' && !isSpace(s[i]) {
+			i++
+		}
+		key := string(s[start:i])
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if key == "" || i == len(s) || s[i] != '=' {
+			return nil, fmt.Errorf("invalid database connection string near %q", key)
+		}
+		i++
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+
+		var value strings.Builder
+		if i < len(s) && s[i] == '\'' {
+			i++
+			closed := false
+			for i < len(s) {
+				if s[i] == '\\' && i+1 < len(s) {
+					value.WriteRune(s[i+1])
+					i += 2
+					continue
+				}
+				if s[i] == '\'' {
+					closed = true
+					i++
+					break
+				}
+				value.WriteRune(s[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated quoted value for %q", key)
+			}
+		} else {
+			for i < len(s) && !isSpace(s[i]) {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				value.WriteRune(s[i])
+				i++
+			}
+		}
+
+		params = append(params, [2]string{key, value.String()})
+	}
+
+	return params, nil
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// quoteValue quotes a value for a keyword/value connection string.
+func quoteValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\r'\\") {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+// hostPort is used for logging; unix socket directories are kept as is.
+func hostPort(host string, port int) string {
+	if strings.HasPrefix(host, "/") {
+		return host
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
@@ -2,7 +2,10 @@ package errors
 
 import (
     "fmt"
+    "math"
     "net/http"
+    "strconv"
+    "time"
 
     "devsecops-be/pkg/response"
     "github.com/gofiber/fiber/v2"
@@ -13,13 +16,38 @@ type AppError struct {
     Code    string `json:"code"`
     Message string `json:"message"`
     Type    string `json:"type"`
+    // Details carries structured information such as per-field violations.
+    Details interface{} `json:"details,omitempty"`
     HTTPStatus int `json:"-"`
+    // RetryAfter is sent as the Retry-After header when set.
+    RetryAfter time.Duration `json:"-"`
 }
 
 func (e *AppError) Error() string {
     return e.Message
 }
 
+// WithRetryAfter returns a copy of the error that tells the client when to retry.
+func (e *AppError) WithRetryAfter(d time.Duration) *AppError {
+    copied := *e
+    copied.RetryAfter = d
+    return &copied
+}
+
+// WithDetails returns a copy of the error carrying details for the client.
+func (e *AppError) WithDetails(details interface{}) *AppError {
+    copied := *e
+    copied.Details = details
+    return &copied
+}
+
+// FieldError describes why one request field was rejected.
+type FieldError struct {
+    Field   string `json:"field"`
+    Code    string `json:"code"`
+    Message string `json:"message"`
+}
+
 // Pre-defined errors
 var (
     ErrUserNotFound = &AppError{
@@ -50,6 +78,181 @@ var (
         HTTPStatus: http.StatusUnauthorized,
     }
 
+    ErrRefreshTokenReused = &AppError{
+        Code:       "REFRESH_TOKEN_REUSED",
+        Message:    "Refresh token has already been used, all sessions in this chain were revoked",
+        Type:       "UNAUTHORIZED",
+        HTTPStatus: http.StatusUnauthorized,
+    }
+
+    ErrInvalidResetToken = &AppError{
+        Code:       "INVALID_RESET_TOKEN",
+        Message:    "Password reset token is invalid, expired or already used",
+        Type:       "BAD_REQUEST",
+        HTTPStatus: http.StatusBadRequest,
+    }
+
+    ErrInvalidVerificationToken = &AppError{
+        Code:       "INVALID_VERIFICATION_TOKEN",
+        Message:    "Email verification link is invalid or expired",
+        Type:       "BAD_REQUEST",
+        HTTPStatus: http.StatusBadRequest,
+    }
+
+    ErrEmailNotVerified = &AppError{
+        Code:       "EMAIL_NOT_VERIFIED",
+        Message:    "Email address must be verified to perform this action",
+        Type:       "FORBIDDEN",
+        HTTPStatus: http.StatusForbidden,
+    }
+
+    ErrEmailAlreadyVerified = &AppError{
+        Code:       "EMAIL_ALREADY_VERIFIED",
+        Message:    "Email address is already verified",
+        Type:       "CONFLICT",
+        HTTPStatus: http.StatusConflict,
+    }
+
+    ErrVerificationCooldown = &AppError{
+        Code:       "VERIFICATION_EMAIL_COOLDOWN",
+        Message:    "A verification email was sent recently, please wait before requesting another",
+        Type:       "TOO_MANY_REQUESTS",
+        HTTPStatus: http.StatusTooManyRequests,
+    }
+
+    ErrMFAAlreadyEnabled = &AppError{
+        Code:       "MFA_ALREADY_ENABLED",
+        Message:    "Two-factor authentication is already enabled",
+        Type:       "CONFLICT",
+        HTTPStatus: http.StatusConflict,
+    }
+
+    ErrMFANotEnabled = &AppError{
+        Code:       "MFA_NOT_ENABLED",
+        Message:    "Two-factor authentication is not enabled",
+        Type:       "BAD_REQUEST",
+        HTTPStatus: http.StatusBadRequest,
+    }
+
+    ErrInvalidMFACode = &AppError{
+        Code:       "INVALID_MFA_CODE",
+        Message:    "Invalid two-factor authentication code",
+        Type:       "BAD_REQUEST",
+        HTTPStatus: http.StatusBadRequest,
+    }
+
+    ErrAccountLocked = &AppError{
+        Code:       "ACCOUNT_LOCKED",
+        Message:    "Too many failed login attempts, the account is temporarily locked",
+        Type:       "TOO_MANY_REQUESTS",
+        HTTPStatus: http.StatusTooManyRequests,
+    }
+
+    ErrTooManyLoginAttempts = &AppError{
+        Code:       "TOO_MANY_LOGIN_ATTEMPTS",
+        Message:    "Too many failed login attempts from this address, please try again later",
+        Type:       "TOO_MANY_REQUESTS",
+        HTTPStatus: http.StatusTooManyRequests,
+    }
+
+    ErrAccountDisabled = &AppError{
+        Code:       "ACCOUNT_DISABLED",
+        Message:    "This account has been disabled",
+        Type:       "FORBIDDEN",
+        HTTPStatus: http.StatusForbidden,
+    }
+
+    ErrPermissionDenied = &AppError{
+        Code:       "PERMISSION_DENIED",
+        Message:    "You do not have permission to perform this action",
+        Type:       "FORBIDDEN",
+        HTTPStatus: http.StatusForbidden,
+    }
+
+    ErrInsufficientScope = &AppError{
+        Code:       "INSUFFICIENT_SCOPE",
+        Message:    "This API key is read-only",
+        Type:       "FORBIDDEN",
+        HTTPStatus: http.StatusForbidden,
+    }
+
+    ErrCannotDisableSelf = &AppError{
+        Code:       "CANNOT_DISABLE_SELF",
+        Message:    "You cannot disable your own account",
+        Type:       "BAD_REQUEST",
+        HTTPStatus: http.StatusBadRequest,
+    }
+
+    ErrOIDCProviderNotFound = &AppError{
+        Code:       "OIDC_PROVIDER_NOT_FOUND",
+        Message:    "Unknown identity provider",
+        Type:       "NOT_FOUND",
+        HTTPStatus: http.StatusNotFound,
+    }
+
+    ErrInvalidOIDCState = &AppError{
+        Code:       "INVALID_OIDC_STATE",
+        Message:    "Sign-in request is invalid or expired, please start again",
+        Type:       "BAD_REQUEST",
+        HTTPStatus: http.StatusBadRequest,
+    }
+
+    ErrOIDCLoginFailed = &AppError{
+        Code:       "OIDC_LOGIN_FAILED",
+        Message:    "Sign-in with the identity provider failed",
+        Type:       "UNAUTHORIZED",
+        HTTPStatus: http.StatusUnauthorized,
+    }
+
+    ErrOIDCEmailNotVerified = &AppError{
+        Code:       "OIDC_EMAIL_NOT_VERIFIED",
+        Message:    "The identity provider did not return a verified email address",
+        Type:       "FORBIDDEN",
+        HTTPStatus: http.StatusForbidden,
+    }
+
+    ErrOIDCAccountConflict = &AppError{
+        Code:       "OIDC_ACCOUNT_CONFLICT",
+        Message:    "An account with this email already exists, sign in with your password and link the provider from your account",
+        Type:       "CONFLICT",
+        HTTPStatus: http.StatusConflict,
+    }
+
+    ErrIdentityAlreadyLinked = &AppError{
+        Code:       "IDENTITY_ALREADY_LINKED",
+        Message:    "This provider account or provider is already linked",
+        Type:       "CONFLICT",
+        HTTPStatus: http.StatusConflict,
+    }
+
+    ErrIdentityNotFound = &AppError{
+        Code:       "IDENTITY_NOT_FOUND",
+        Message:    "Linked identity not found",
+        Type:       "NOT_FOUND",
+        HTTPStatus: http.StatusNotFound,
+    }
+
+    ErrInvalidRole = &AppError{
+        Code:       "INVALID_ROLE",
+        Message:    "Role does not exist",
+        Type:       "BAD_REQUEST",
+        HTTPStatus: http.StatusBadRequest,
+    }
+
+    ErrPasswordPolicy = &AppError{
+        Code:       "PASSWORD_POLICY_VIOLATION",
+        Message:    "Password does not meet the password policy",
+        Type:       "BAD_REQUEST",
+        HTTPStatus: http.StatusBadRequest,
+    }
+
+    ErrRateLimitExceeded = &AppError{
+        Code:       "RATE_LIMIT_EXCEEDED",
+        Message:    "Too many requests, please slow down",
+        Type:       "TOO_MANY_REQUESTS",
+        HTTPStatus: http.StatusTooManyRequests,
+    }
+
     ErrTokenRequired = &AppError{
         Code:       "TOKEN_REQUIRED",
         Message:    "Authorization token is required",
@@ -57,6 +260,76 @@ var (
         HTTPStatus: http.StatusUnauthorized,
     }
 
+    ErrAPIKeyNotFound = &AppError{
+        Code:       "API_KEY_NOT_FOUND",
+        Message:    "API key not found",
+        Type:       "NOT_FOUND",
+        HTTPStatus: http.StatusNotFound,
+    }
+
+    ErrSessionNotFound = &AppError{
+        Code:       "SESSION_NOT_FOUND",
+        Message:    "Session not found",
+        Type:       "NOT_FOUND",
+        HTTPStatus: http.StatusNotFound,
+    }
+
+    ErrTransactionNotFound = &AppError{
+        Code:       "TRANSACTION_NOT_FOUND",
+        Message:    "Transaction not found",
+        Type:       "NOT_FOUND",
+        HTTPStatus: http.StatusNotFound,
+    }
+
+    ErrCategoryNotFound = &AppError{
+        Code:       "CATEGORY_NOT_FOUND",
+        Message:    "Category not found",
+        Type:       "NOT_FOUND",
+        HTTPStatus: http.StatusNotFound,
+    }
+
+    ErrCategoryAlreadyExists = &AppError{
+        Code:       "CATEGORY_ALREADY_EXISTS",
+        Message:    "Category with this name already exists",
+        Type:       "CONFLICT",
+        HTTPStatus: http.StatusConflict,
+    }
+
+    ErrCategoryInUse = &AppError{
+        Code:       "CATEGORY_IN_USE",
+        Message:    "Category is still used by transactions",
+        Type:       "CONFLICT",
+        HTTPStatus: http.StatusConflict,
+    }
+
+    ErrCategoryReadOnly = &AppError{
+        Code:       "CATEGORY_READ_ONLY",
+        Message:    "Default categories cannot be modified",
+        Type:       "FORBIDDEN",
+        HTTPStatus: http.StatusForbidden,
+    }
+
+    ErrCategoryArchived = &AppError{
+        Code:       "CATEGORY_ARCHIVED",
+        Message:    "Category is archived",
+        Type:       "BAD_REQUEST",
+        HTTPStatus: http.StatusBadRequest,
+    }
+
+    ErrSpendingLimitNotFound = &AppError{
+        Code:       "SPENDING_LIMIT_NOT_FOUND",
+        Message:    "Spending limits have not been set",
+        Type:       "NOT_FOUND",
+        HTTPStatus: http.StatusNotFound,
+    }
+
+    ErrAlertNotFound = &AppError{
+        Code:       "ALERT_NOT_FOUND",
+        Message:    "Alert not found",
+        Type:       "NOT_FOUND",
+        HTTPStatus: http.StatusNotFound,
+    }
+
     ErrInternalServer = &AppError{
         Code:       "INTERNAL_SERVER_ERROR",
         Message:    "Internal server error",
@@ -96,15 +369,23 @@ func WrapInternalError(err error, message string) *AppError {
 // HTTP Error Handler
 func HandleHTTPError(c *fiber.Ctx, err error) error {
     if appErr, ok := err.(*AppError); ok {
+        if appErr.RetryAfter > 0 {
+            c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(appErr.RetryAfter.Seconds()))))
+        }
+
         switch appErr.HTTPStatus {
         case http.StatusBadRequest:
             return response.BadRequest(c, appErr.Message, appErr)
         case http.StatusUnauthorized:
             return response.Unauthorized(c, appErr.Message, appErr)
+        case http.StatusForbidden:
+            return response.Forbidden(c, appErr.Message, appErr)
         case http.StatusNotFound:
             return response.NotFound(c, appErr.Message, appErr)
         case http.StatusConflict:
             return response.Conflict(c, appErr.Message, appErr)
+        case http.StatusTooManyRequests:
+            return response.TooManyRequests(c, appErr.Message, appErr)
         default:
             return response.InternalServerError(c, appErr.Message, appErr)
         }
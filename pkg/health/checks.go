@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/pkg/migrate"
+	"fmt"
+)
+
+// Database pings the database and reports the connection pool statistics.
+func Database(db *sql.DB) Check {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		stats := db.Stats()
+		details := map[string]interface{}{
+			"open_connections":     stats.OpenConnections,
+			"in_use":               stats.InUse,
+			"idle":                 stats.Idle,
+			"max_open_connections": stats.MaxOpenConnections,
+			"wait_count":           stats.WaitCount,
+			"wait_duration_ms":     stats.WaitDuration.Milliseconds(),
+		}
+
+		if err := db.PingContext(ctx); err != nil {
+			return details, fmt.Errorf("database ping failed: %w", err)
+		}
+		return details, nil
+	}
+}
+
+// Migrations fails while embedded migrations are pending or an applied one
+// was modified. Applied migrations this build does not know about are only
+// reported, they are expected while a newer release rolls out.
+func Migrations(migrator migrate.Migrator) Check {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var current, latest int64
+		var pending, modified, unknown int
+		for _, status := range statuses {
+			switch {
+			case status.Missing:
+				unknown++
+			case !status.Applied:
+				pending++
+			case status.Modified:
+				modified++
+			}
+			if status.Applied && status.Version > current {
+				current = status.Version
+			}
+			if !status.Missing && status.Version > latest {
+				latest = status.Version
+			}
+		}
+
+		details := map[string]interface{}{
+			"current_version": current,
+			"latest_version":  latest,
+			"pending":         pending,
+			"modified":        modified,
+			"unknown":         unknown,
+		}
+		if pending > 0 || modified > 0 {
+			return details, fmt.Errorf("database schema is not up to date")
+		}
+		return details, nil
+	}
+}
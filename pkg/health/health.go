@@ -0,0 +1,140 @@
+// Package health runs the dependency checks behind the readiness probe.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Check reports whether a dependency is usable. The details, such as
+// connection pool statistics, are included in the report either way.
+type Check func(ctx context.Context) (details map[string]interface{}, err error)
+
+// Result is the outcome of one check.
+type Result struct {
+	Name       string                 `json:"name"`
+	Status     string                 `json:"status"`
+	DurationMs int64                  `json:"duration_ms"`
+	Error      string                 `json:"error,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+}
+
+// Report is up when every check is.
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Summary is the report without errors and details, which can reveal host
+// names, schema versions and pool sizes, so it can be served to anyone.
+type Summary struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Summary returns the status of the report and of each check.
+func (r Report) Summary() Summary {
+	summary := Summary{Status: r.Status, Checks: make(map[string]string, len(r.Checks))}
+	for _, result := range r.Checks {
+		summary.Checks[result.Name] = result.Status
+	}
+	return summary
+}
+
+// Registry holds the checks registered by the modules that own a dependency.
+type Registry struct {
+	mu      sync.RWMutex
+	checks  map[string]Check
+	timeout time.Duration
+}
+
+// NewRegistry returns an empty registry; each check gets timeout to finish.
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{checks: map[string]Check{}, timeout: timeout}
+}
+
+// Register adds a check, replacing any check registered under the same name.
+func (r *Registry) Register(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = check
+}
+
+// Run runs every check concurrently and waits for all of them.
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.checks))
+	for name := range r.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	checks := make([]Check, len(names))
+	for i, name := range names {
+		checks[i] = r.checks[name]
+	}
+	r.mu.RUnlock()
+
+	results := make([]Result, len(names))
+	var wg sync.WaitGroup
+	for i := range names {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = r.run(ctx, names[i], checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+type outcome struct {
+	details map[string]interface{}
+	err     error
+}
+
+// run gives up on a check that ignores its context once the timeout passes.
+func (r *Registry) run(ctx context.Context, name string, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan outcome, 1)
+	go func() {
+		details, err := check(ctx)
+		done <- outcome{details: details, err: err}
+	}()
+
+	var result outcome
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result.err = fmt.Errorf("check did not finish within %s", r.timeout)
+	}
+
+	status, message := StatusUp, ""
+	if result.err != nil {
+		status, message = StatusDown, result.err.Error()
+	}
+	return Result{
+		Name:       name,
+		Status:     status,
+		DurationMs: time.Since(start).Milliseconds(),
+		Error:      message,
+		Details:    result.details,
+	}
+}
@@ -2,54 +2,108 @@ package jwt
 
 import (
 	"devsecops-be/pkg/errors"
-	"os"
-	"strconv"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
+// Token types carried in the "type" claim. Only access tokens are accepted by
+// the auth middleware; the others are single-purpose.
+const (
+    TokenTypeAccess            = "access"
+    TokenTypeEmailVerification = "email_verification"
+    TokenTypeMFAPending        = "mfa_pending"
+)
+
 type JWTUtil interface {
-    GenerateToken(userID uuid.UUID) (string, time.Time, error)
+    GenerateToken(userID uuid.UUID, role string, sessionID uuid.UUID) (string, time.Time, error)
     ValidateToken(tokenString string) (jwt.MapClaims, error)
+    GenerateTypedToken(userID uuid.UUID, tokenType string, ttl time.Duration, extra jwt.MapClaims) (string, time.Time, error)
+    ValidateTypedToken(tokenString string, tokenType string) (jwt.MapClaims, error)
+    AccessTokenExp() time.Duration
+    RefreshTokenExp() time.Duration
+    JWKS() JWKS
 }
 
 type jwtUtil struct {
-    secretKey      []byte
-    accessTokenExp time.Duration
+    signingKey       *signingKey
+    verificationKeys map[string]*verificationKey
+    accessTokenExp   time.Duration
+    refreshTokenExp  time.Duration
 }
 
-func NewJWTUtil() JWTUtil {
-    secretKey := os.Getenv("JWT_SECRET_KEY")
-    
+type Config struct {
+    // SigningKeyFile is the RS256/EdDSA private key new tokens are signed with.
+    SigningKeyFile string
+    // VerificationKeyFiles are public keys still accepted for verification, so
+    // tokens signed before a key rotation stay valid until they expire.
+    VerificationKeyFiles []string
+    AccessTokenExp       time.Duration
+    RefreshTokenExp      time.Duration
+}
 
-    accessExp := 6 * time.Hour // Default 6 hours
+// Validate checks the settings without reading the key files.
+func (c Config) Validate() error {
+    if c.SigningKeyFile == "" {
+        return fmt.Errorf("JWT_SIGNING_KEY_FILE is not set")
+    }
+    if c.AccessTokenExp <= 0 || c.RefreshTokenExp <= 0 {
+        return fmt.Errorf("JWT token lifetimes must be positive")
+    }
+    return nil
+}
+
+// NewJWTUtil loads the signing key and the verification keys of config. It
+// returns an error when a key file is missing or unusable.
+func NewJWTUtil(config Config) (JWTUtil, error) {
+    if err := config.Validate(); err != nil {
+        return nil, err
+    }
 
-    if exp := os.Getenv("JWT_ACCESS_EXP_HOURS"); exp != "" {
-        if hours, err := strconv.Atoi(exp); err == nil {
-            accessExp = time.Duration(hours) * time.Hour
+    signing, err := loadSigningKey(config.SigningKeyFile)
+    if err != nil {
+        return nil, fmt.Errorf("invalid JWT signing key: %w", err)
+    }
+
+    verificationKeys := map[string]*verificationKey{
+        signing.kid: &signing.verificationKey,
+    }
+    for _, path := range config.VerificationKeyFiles {
+        key, err := loadVerificationKey(path)
+        if err != nil {
+            return nil, fmt.Errorf("invalid JWT verification key: %w", err)
         }
+        verificationKeys[key.kid] = key
     }
 
     return &jwtUtil{
-        secretKey:      []byte(secretKey),
-        accessTokenExp: accessExp,
-    }
+        signingKey:       signing,
+        verificationKeys: verificationKeys,
+        accessTokenExp:   config.AccessTokenExp,
+        refreshTokenExp:  config.RefreshTokenExp,
+    }, nil
 }
 
-func (j *jwtUtil) GenerateToken(userID uuid.UUID) (string, time.Time, error) {
+// GenerateToken signs an access token. The role claim lets permission checks
+// run without a user lookup; role changes apply once the token is refreshed.
+// The sid claim ties the token to its session so revoking the session also
+// rejects the access tokens issued for it.
+func (j *jwtUtil) GenerateToken(userID uuid.UUID, role string, sessionID uuid.UUID) (string, time.Time, error) {
     expiresAt := time.Now().Add(j.accessTokenExp)
     
     claims := jwt.MapClaims{
         "user_id": userID,
+        "role":    role,
+        "sid":     sessionID,
         "exp":     expiresAt.Unix(),
         "iat":     time.Now().Unix(),
-        "type":    "access",
+        "type":    TokenTypeAccess,
+        "jti":     uuid.NewString(),
     }
 
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    tokenString, err := token.SignedString(j.secretKey)
+    tokenString, err := j.sign(claims)
     if err != nil {
         return "", time.Time{}, err
     }
@@ -57,13 +111,81 @@ func (j *jwtUtil) GenerateToken(userID uuid.UUID) (string, time.Time, error) {
     return tokenString, expiresAt, nil
 }
 
+// GenerateTypedToken signs a single-purpose token such as an email
+// verification link. Its "type" claim keeps it from being accepted as an
+// access token; extra claims are added alongside the standard ones.
+func (j *jwtUtil) GenerateTypedToken(userID uuid.UUID, tokenType string, ttl time.Duration, extra jwt.MapClaims) (string, time.Time, error) {
+    expiresAt := time.Now().Add(ttl)
+
+    claims := jwt.MapClaims{}
+    for key, value := range extra {
+        claims[key] = value
+    }
+    claims["user_id"] = userID
+    claims["exp"] = expiresAt.Unix()
+    claims["iat"] = time.Now().Unix()
+    claims["type"] = tokenType
+    claims["jti"] = uuid.NewString()
+
+    tokenString, err := j.sign(claims)
+    if err != nil {
+        return "", time.Time{}, err
+    }
+
+    return tokenString, expiresAt, nil
+}
+
+// ValidateTypedToken validates the token and checks that it was issued for tokenType.
+func (j *jwtUtil) ValidateTypedToken(tokenString string, tokenType string) (jwt.MapClaims, error) {
+    claims, err := j.ValidateToken(tokenString)
+    if err != nil {
+        return nil, err
+    }
+
+    if claimType, _ := claims["type"].(string); claimType != tokenType {
+        return nil, errors.ErrInvalidToken
+    }
+
+    return claims, nil
+}
+
+func (j *jwtUtil) sign(claims jwt.MapClaims) (string, error) {
+    token := jwt.NewWithClaims(j.signingKey.method, claims)
+    token.Header["kid"] = j.signingKey.kid
+    return token.SignedString(j.signingKey.private)
+}
+
+func (j *jwtUtil) AccessTokenExp() time.Duration {
+    return j.accessTokenExp
+}
+
+// RefreshTokenExp is the lifetime of the opaque refresh tokens issued alongside
+// access tokens.
+func (j *jwtUtil) RefreshTokenExp() time.Duration {
+    return j.refreshTokenExp
+}
+
+// JWKS returns every public key currently accepted for verification.
+func (j *jwtUtil) JWKS() JWKS {
+    keys := make([]JWK, 0, len(j.verificationKeys))
+    keys = append(keys, j.signingKey.jwk())
+    for kid, key := range j.verificationKeys {
+        if kid != j.signingKey.kid {
+            keys = append(keys, key.jwk())
+        }
+    }
+    return JWKS{Keys: keys}
+}
+
 func (j *jwtUtil) ValidateToken(tokenString string) (jwt.MapClaims, error) {
     token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+        kid, _ := token.Header["kid"].(string)
+        key, ok := j.verificationKeys[kid]
+        if !ok || token.Method.Alg() != key.method.Alg() {
             return nil, errors.ErrInvalidToken
         }
-        return j.secretKey, nil
-    })
+        return key.public, nil
+    }, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}))
 
     if err != nil {
         return nil, errors.ErrInvalidToken
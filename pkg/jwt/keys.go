@@ -0,0 +1,188 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSAKeyBits is the smallest RSA modulus accepted for signing or verification.
+const minRSAKeyBits = 2048
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// verificationKey is a public key tokens may be verified with. The kid is the
+// RFC 7638 thumbprint, so it is stable for a key no matter where it is loaded.
+type verificationKey struct {
+	kid    string
+	method jwt.SigningMethod
+	public crypto.PublicKey
+}
+
+type signingKey struct {
+	verificationKey
+	private crypto.PrivateKey
+}
+
+func loadSigningKey(path string) (*signingKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var private crypto.PrivateKey
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q, expected a private key", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var public crypto.PublicKey
+	switch key := private.(type) {
+	case *rsa.PrivateKey:
+		public = &key.PublicKey
+	case ed25519.PrivateKey:
+		public = key.Public()
+	default:
+		return nil, fmt.Errorf("%s: unsupported key type %T, expected RSA or Ed25519", path, private)
+	}
+
+	verify, err := newVerificationKey(path, public)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signingKey{verificationKey: *verify, private: private}, nil
+}
+
+func loadVerificationKey(path string) (*verificationKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var public crypto.PublicKey
+	switch block.Type {
+	case "PUBLIC KEY":
+		public, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		public, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			public = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q, expected a public key", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return newVerificationKey(path, public)
+}
+
+func newVerificationKey(path string, public crypto.PublicKey) (*verificationKey, error) {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("%s: RSA key is %d bits, at least %d are required", path, key.N.BitLen(), minRSAKeyBits)
+		}
+		return &verificationKey{kid: thumbprint(key), method: jwt.SigningMethodRS256, public: key}, nil
+	case ed25519.PublicKey:
+		return &verificationKey{kid: thumbprint(key), method: jwt.SigningMethodEdDSA, public: key}, nil
+	default:
+		return nil, fmt.Errorf("%s: unsupported key type %T, expected RSA or Ed25519", path, public)
+	}
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+
+	return block, nil
+}
+
+func (k *verificationKey) jwk() JWK {
+	key := JWK{Use: "sig", Alg: k.method.Alg(), Kid: k.kid}
+
+	switch public := k.public.(type) {
+	case *rsa.PublicKey:
+		key.Kty = "RSA"
+		key.N = encodeSegment(public.N.Bytes())
+		key.E = encodeSegment(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		key.Kty = "OKP"
+		key.Crv = "Ed25519"
+		key.X = encodeSegment(public)
+	}
+
+	return key
+}
+
+// thumbprint computes the RFC 7638 JWK thumbprint of a public key.
+func thumbprint(public crypto.PublicKey) string {
+	var members interface{}
+
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		// Members must be in lexicographic order, which struct field order gives us.
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{encodeSegment(big.NewInt(int64(key.E)).Bytes()), "RSA", encodeSegment(key.N.Bytes())}
+	case ed25519.PublicKey:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{"Ed25519", "OKP", encodeSegment(key)}
+	}
+
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return encodeSegment(sum[:])
+}
+
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,103 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Algorithms accepted by RotateSigningKey.
+const (
+	KeyAlgorithmEd25519 = "ed25519"
+	KeyAlgorithmRSA     = "rsa"
+)
+
+const rotatedRSAKeyBits = 3072
+
+// Rotation describes a signing key rotation.
+type Rotation struct {
+	NewKeyID string
+	// OldKeyID and OldPublicKeyFile are empty when there was no key before.
+	OldKeyID         string
+	OldPublicKeyFile string
+}
+
+// RotateSigningKey replaces the private key at path with a new one. The
+// public half of the old key is first written next to it as
+// jwt-<kid>.pub.pem; add that file to JWT_VERIFICATION_KEY_FILES so tokens
+// signed before the rotation stay valid until they expire.
+func RotateSigningKey(path, algorithm string) (*Rotation, error) {
+	rotation := &Rotation{}
+
+	if _, err := os.Stat(path); err == nil {
+		old, err := loadSigningKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid current signing key: %w", err)
+		}
+
+		der, err := x509.MarshalPKIXPublicKey(old.public)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode current public key: %w", err)
+		}
+
+		publicPath := filepath.Join(filepath.Dir(path), "jwt-"+old.kid+".pub.pem")
+		if err := writePEM(publicPath, "PUBLIC KEY", der, 0o644); err != nil {
+			return nil, err
+		}
+		rotation.OldKeyID = old.kid
+		rotation.OldPublicKeyFile = publicPath
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var private crypto.Signer
+	var err error
+	switch algorithm {
+	case KeyAlgorithmEd25519:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	case KeyAlgorithmRSA:
+		private, err = rsa.GenerateKey(rand.Reader, rotatedRSAKeyBits)
+	default:
+		return nil, fmt.Errorf("unsupported key algorithm %q, expected %s or %s", algorithm, KeyAlgorithmEd25519, KeyAlgorithmRSA)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signing key: %w", err)
+	}
+
+	// Write then rename so a running server never reads a partial key
+	tmpPath := path + ".tmp"
+	if err := writePEM(tmpPath, "PRIVATE KEY", der, 0o600); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to replace signing key: %w", err)
+	}
+
+	verify, err := newVerificationKey(path, private.Public())
+	if err != nil {
+		return nil, err
+	}
+	rotation.NewKeyID = verify.kid
+
+	return rotation, nil
+}
+
+func writePEM(path, blockType string, der []byte, perm os.FileMode) error {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package lockout
+
+import (
+	"context"
+	"devsecops-be/pkg/cleanup"
+	"devsecops-be/pkg/logger"
+	"time"
+)
+
+// Attempt is the failure history kept for one key, such as an email or IP.
+type Attempt struct {
+	Failures    int
+	LockedUntil time.Time
+}
+
+// Store keeps failed attempt counters. Entries expire once their failures are
+// older than the window and any lockout has ended, after which Cleanup may
+// drop them.
+type Store interface {
+	// Get returns the attempt for key, or a zero Attempt when there is none.
+	Get(ctx context.Context, key string) (Attempt, error)
+	// RecordFailure counts a failure for key and returns the number of failures
+	// within window, including this one.
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	// Lock rejects attempts for key until the given time.
+	Lock(ctx context.Context, key string, until time.Time) error
+	// Reset forgets every failure recorded for key.
+	Reset(ctx context.Context, key string) error
+	// Cleanup removes expired entries and returns how many were removed.
+	Cleanup(ctx context.Context) (int64, error)
+}
+
+// Policy controls when a key is locked and for how long.
+type Policy struct {
+	// MaxFailures is the number of failures that triggers the first lockout.
+	MaxFailures int
+	// Window is how long a failure keeps counting.
+	Window time.Duration
+	// BaseLockout is the first lockout; it doubles with every further failure.
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+}
+
+// lockoutFor returns how long to lock after the given number of failures.
+func (p Policy) lockoutFor(failures int) time.Duration {
+	if failures < p.MaxFailures {
+		return 0
+	}
+
+	lockout := p.BaseLockout
+	for i := p.MaxFailures; i < failures && lockout < p.MaxLockout; i++ {
+		lockout *= 2
+	}
+	return min(lockout, p.MaxLockout)
+}
+
+// Guard applies a Policy to keys sharing a prefix in a Store.
+type Guard struct {
+	store  Store
+	prefix string
+	policy Policy
+}
+
+func NewGuard(store Store, prefix string, policy Policy) *Guard {
+	return &Guard{store: store, prefix: prefix, policy: policy}
+}
+
+// Check returns how long key remains locked, or zero when attempts are allowed.
+func (g *Guard) Check(ctx context.Context, key string) (time.Duration, error) {
+	attempt, err := g.store.Get(ctx, g.prefix+key)
+	if err != nil {
+		return 0, err
+	}
+
+	return max(time.Until(attempt.LockedUntil), 0), nil
+}
+
+// Fail records a failed attempt for key and returns the lockout it triggered,
+// or zero when the key is still below the policy's limit.
+func (g *Guard) Fail(ctx context.Context, key string) (time.Duration, error) {
+	failures, err := g.store.RecordFailure(ctx, g.prefix+key, g.policy.Window)
+	if err != nil {
+		return 0, err
+	}
+
+	lockout := g.policy.lockoutFor(failures)
+	if lockout == 0 {
+		return 0, nil
+	}
+
+	if err := g.store.Lock(ctx, g.prefix+key, time.Now().Add(lockout)); err != nil {
+		return 0, err
+	}
+	return lockout, nil
+}
+
+// Reset clears the failures of key, typically after a successful attempt.
+func (g *Guard) Reset(ctx context.Context, key string) error {
+	return g.store.Reset(ctx, g.prefix+key)
+}
+
+// StartCleanup runs store.Cleanup every interval until ctx is cancelled.
+func StartCleanup(ctx context.Context, store Store, interval time.Duration, log logger.Logger) {
+	cleanup.Every(ctx, interval, "login attempts", store.Cleanup, log)
+}
@@ -0,0 +1,93 @@
+package lockout
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore returns a Store backed by the login_attempts table, shared
+// by every instance of the service.
+func NewPostgresStore(db *sql.DB) Store {
+	return &postgresStore{db: db}
+}
+
+func (s *postgresStore) Get(ctx context.Context, key string) (Attempt, error) {
+	query := `
+        SELECT failures, locked_until
+        FROM login_attempts
+        WHERE key = $1 AND expires_at > $2
+    `
+
+	var attempt Attempt
+	var lockedUntil sql.NullTime
+	err := s.db.QueryRowContext(ctx, query, key, time.Now().UTC()).Scan(&attempt.Failures, &lockedUntil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Attempt{}, nil
+		}
+		return Attempt{}, fmt.Errorf("failed to get login attempts: %w", err)
+	}
+
+	if lockedUntil.Valid {
+		attempt.LockedUntil = lockedUntil.Time
+	}
+	return attempt, nil
+}
+
+func (s *postgresStore) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	query := `
+        INSERT INTO login_attempts (key, failures, expires_at)
+        VALUES ($1, 1, $3)
+        ON CONFLICT (key) DO UPDATE
+        SET failures = CASE WHEN login_attempts.expires_at > $2 THEN login_attempts.failures + 1 ELSE 1 END,
+            locked_until = CASE WHEN login_attempts.expires_at > $2 THEN login_attempts.locked_until END,
+            expires_at = GREATEST(
+                CASE WHEN login_attempts.expires_at > $2 THEN login_attempts.expires_at END,
+                EXCLUDED.expires_at
+            )
+        RETURNING failures
+    `
+
+	now := time.Now().UTC()
+	var failures int
+	if err := s.db.QueryRowContext(ctx, query, key, now, now.Add(window)).Scan(&failures); err != nil {
+		return 0, fmt.Errorf("failed to record login failure: %w", err)
+	}
+	return failures, nil
+}
+
+func (s *postgresStore) Lock(ctx context.Context, key string, until time.Time) error {
+	query := `
+        UPDATE login_attempts
+        SET locked_until = $2, expires_at = GREATEST(expires_at, $2)
+        WHERE key = $1
+    `
+
+	if _, err := s.db.ExecContext(ctx, query, key, until.UTC()); err != nil {
+		return fmt.Errorf("failed to lock login attempts: %w", err)
+	}
+	return nil
+}
+
+func (s *postgresStore) Reset(ctx context.Context, key string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM login_attempts WHERE key = $1`, key); err != nil {
+		return fmt.Errorf("failed to reset login attempts: %w", err)
+	}
+	return nil
+}
+
+func (s *postgresStore) Cleanup(ctx context.Context) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM login_attempts WHERE expires_at < $1`, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean up login attempts: %w", err)
+	}
+
+	removed, _ := result.RowsAffected()
+	return removed, nil
+}
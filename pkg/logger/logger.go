@@ -2,7 +2,6 @@ package logger
 
 import (
     "context"
-    "os"
 
     "go.uber.org/zap"
     "go.uber.org/zap/zapcore"
@@ -22,11 +21,13 @@ type logger struct {
     zap *zap.Logger
 }
 
-func NewLogger() Logger {
+// NewLogger logs JSON at info level, or colored text at debug level in
+// development.
+func NewLogger(development bool) Logger {
     config := zap.NewProductionConfig()
     
     // Set log level based on environment
-    if os.Getenv("ENV") == "development" {
+    if development {
         config = zap.NewDevelopmentConfig()
         config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
     }
@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"context"
+	"devsecops-be/pkg/logger"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer writes every message to an .eml file in dir instead of
+// delivering it, for local development and tests.
+func NewFileMailer(dir, from string) (Mailer, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+
+	return &fileMailer{dir: dir, from: from}, nil
+}
+
+func (m *fileMailer) Send(ctx context.Context, msg Message) error {
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), uuid.NewString())
+	if err := os.WriteFile(filepath.Join(m.dir, name), formatMessage(m.from, msg), 0o600); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+
+	return nil
+}
+
+type logMailer struct {
+	logger logger.Logger
+}
+
+// NewLogMailer records that a message was sent without delivering it. The
+// body is left out because it may hold secrets such as reset links, use the
+// file mailer to read messages during development.
+func NewLogMailer(log logger.Logger) Mailer {
+	return &logMailer{logger: log}
+}
+
+func (m *logMailer) Send(ctx context.Context, msg Message) error {
+	m.logger.Info(ctx, "Mail not delivered (log mailer)", logger.Fields{
+		"to":      msg.To,
+		"subject": msg.Subject,
+	})
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package mailer
+
+import (
+	"context"
+	"devsecops-be/pkg/logger"
+	"fmt"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional email.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+type Config struct {
+	Driver   string
+	From     string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Dir      string
+}
+
+// Validate checks the settings of the selected driver.
+func (c Config) Validate() error {
+	switch c.Driver {
+	case "smtp":
+		if c.Host == "" {
+			return fmt.Errorf("SMTP_HOST is required for the smtp mailer")
+		}
+		if c.Port <= 0 || c.Port > 65535 {
+			return fmt.Errorf("invalid SMTP port %d", c.Port)
+		}
+	case "file":
+		if c.Dir == "" {
+			return fmt.Errorf("MAILER_DIR is required for the file mailer")
+		}
+	case "log":
+	default:
+		return fmt.Errorf("unknown MAILER_DRIVER %q", c.Driver)
+	}
+	return nil
+}
+
+// NewMailer builds the mailer selected by the driver: "smtp", "file" or
+// "log" (the default, for local development).
+func NewMailer(config Config, log logger.Logger) (Mailer, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch config.Driver {
+	case "smtp":
+		return NewSMTPMailer(config), nil
+	case "file":
+		return NewFileMailer(config.Dir, config.From)
+	default:
+		return NewLogMailer(log), nil
+	}
+}
+
+func formatMessage(from string, msg Message) []byte {
+	return []byte("From: " + from + "\r\n" +
+		"To: " + msg.To + "\r\n" +
+		"Subject: " + msg.Subject + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" +
+		msg.Body + "\r\n")
+}
@@ -0,0 +1,380 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"devsecops-be/pkg/logger"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrChecksumMismatch means an applied migration was edited afterwards.
+	ErrChecksumMismatch = errors.New("applied migration does not match its file")
+	// ErrUnknownVersion means a version is neither 0 nor one of the migrations.
+	ErrUnknownVersion = errors.New("unknown migration version")
+	// ErrUnversionedSchema means the database already has tables but no
+	// recorded migrations, such as one created by the Postgres entrypoint
+	// scripts. Record the version it is at with Baseline first.
+	ErrUnversionedSchema = errors.New("database has tables but no recorded migrations, baseline it first")
+)
+
+// lockKey identifies the advisory lock held while migrating, so replicas
+// starting together apply each migration once.
+const lockKey int64 = 0x64657673656370 // "devsecp"
+
+// Status describes one migration.
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	// Modified is set when the file changed after the migration was applied.
+	Modified bool
+	// Missing is set when an applied migration no longer has a file.
+	Missing bool
+}
+
+// Migrator applies the migrations and records them in schema_migrations.
+// Every method that changes the schema holds a Postgres advisory lock and
+// runs each migration in its own transaction.
+type Migrator interface {
+	// Up applies every pending migration and returns how many ran.
+	Up(ctx context.Context) (int, error)
+	// Down reverts the last steps applied migrations.
+	Down(ctx context.Context, steps int) (int, error)
+	// To applies or reverts migrations until version is the latest applied
+	// one. Version 0 reverts everything.
+	To(ctx context.Context, version int64) (int, error)
+	Status(ctx context.Context) ([]Status, error)
+	// Baseline records every migration up to version as applied without
+	// running it, for databases whose schema was created another way.
+	Baseline(ctx context.Context, version int64) error
+}
+
+type migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	log        logger.Logger
+}
+
+type appliedMigration struct {
+	checksum  string
+	name      string
+	appliedAt time.Time
+}
+
+func NewMigrator(db *sql.DB, fsys fs.FS, log logger.Logger) (Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &migrator{
+		db:         db,
+		migrations: migrations,
+		log:        log,
+	}, nil
+}
+
+func (m *migrator) Up(ctx context.Context) (int, error) {
+	if len(m.migrations) == 0 {
+		return 0, nil
+	}
+	return m.To(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+func (m *migrator) Down(ctx context.Context, steps int) (int, error) {
+	count := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+			if _, ok := applied[m.migrations[i].Version]; !ok {
+				continue
+			}
+			if err := m.run(ctx, conn, m.migrations[i], false); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+func (m *migrator) To(ctx context.Context, version int64) (int, error) {
+	if version != 0 && m.find(version) == nil {
+		return 0, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	count := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			if err := m.checkVersioned(ctx, conn); err != nil {
+				return err
+			}
+		}
+
+		// Revert newer migrations first, newest first
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok || migration.Version <= version {
+				continue
+			}
+			if err := m.run(ctx, conn, migration, false); err != nil {
+				return err
+			}
+			count++
+		}
+
+		// Then apply pending ones in order, including any older migration
+		// that was merged after newer ones had already been applied
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok || migration.Version > version {
+				continue
+			}
+			if err := m.run(ctx, conn, migration, true); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+func (m *migrator) Status(ctx context.Context) ([]Status, error) {
+	var exists bool
+	if err := m.db.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check schema_migrations: %w", err)
+	}
+
+	applied := map[int64]appliedMigration{}
+	if exists {
+		conn, err := m.db.Conn(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get connection: %w", err)
+		}
+		defer conn.Close()
+
+		if applied, err = m.applied(ctx, conn); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = row.appliedAt
+			status.Modified = row.checksum != migration.Checksum
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for version, row := range applied {
+		statuses = append(statuses, Status{
+			Version:   version,
+			Name:      row.name,
+			Applied:   true,
+			AppliedAt: row.appliedAt,
+			Missing:   true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+func (m *migrator) Baseline(ctx context.Context, version int64) error {
+	if m.find(version) == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		query := `
+            INSERT INTO schema_migrations (version, name, checksum)
+            VALUES ($1, $2, $3)
+            ON CONFLICT (version) DO NOTHING
+        `
+
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			if _, err := conn.ExecContext(ctx, query, migration.Version, migration.Name, migration.Checksum); err != nil {
+				return fmt.Errorf("failed to baseline migration %d: %w", migration.Version, err)
+			}
+		}
+
+		m.log.Info(ctx, "Database baselined", logger.Fields{
+			"version": version,
+		})
+		return nil
+	})
+}
+
+// withLock runs fn on a single connection holding the migration lock.
+// Advisory locks belong to a session, so every statement has to go through
+// the same connection.
+func (m *migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey); err != nil {
+			m.log.Error(ctx, "Failed to release migration lock", err)
+		}
+	}()
+
+	query := `
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version BIGINT PRIMARY KEY,
+            name TEXT NOT NULL,
+            checksum TEXT NOT NULL,
+            applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )
+    `
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+func (m *migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := map[int64]appliedMigration{}
+	for rows.Next() {
+		var version int64
+		var row appliedMigration
+		if err := rows.Scan(&version, &row.name, &row.checksum, &row.appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[version] = row
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+
+	return applied, nil
+}
+
+// verify refuses to migrate a database whose history does not match the
+// embedded files.
+func (m *migrator) verify(applied map[int64]appliedMigration) error {
+	for version, row := range applied {
+		migration := m.find(version)
+		if migration == nil {
+			return fmt.Errorf("%w: %d_%s is applied but has no file", ErrUnknownVersion, version, row.name)
+		}
+		if migration.Checksum != row.checksum {
+			return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, version, migration.Name)
+		}
+	}
+	return nil
+}
+
+func (m *migrator) checkVersioned(ctx context.Context, conn *sql.Conn) error {
+	query := `
+        SELECT COUNT(*)
+        FROM information_schema.tables
+        WHERE table_schema = current_schema() AND table_name <> 'schema_migrations'
+    `
+
+	var tables int
+	if err := conn.QueryRowContext(ctx, query).Scan(&tables); err != nil {
+		return fmt.Errorf("failed to inspect schema: %w", err)
+	}
+	if tables > 0 {
+		return ErrUnversionedSchema
+	}
+	return nil
+}
+
+func (m *migrator) run(ctx context.Context, conn *sql.Conn, migration Migration, up bool) error {
+	script, direction := migration.Up, "up"
+	if !up {
+		script, direction = migration.Down, "down"
+		if script == "" {
+			return fmt.Errorf("migration %d_%s has no down script", migration.Version, migration.Name)
+		}
+	}
+
+	start := time.Now()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d: %w", migration.Version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("migration %d_%s %s failed: %w", migration.Version, migration.Name, direction, err)
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+			migration.Version, migration.Name, migration.Checksum)
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", migration.Version, err)
+	}
+
+	message := "Applied migration"
+	if !up {
+		message = "Reverted migration"
+	}
+	m.log.Info(ctx, message, logger.Fields{
+		"version":     migration.Version,
+		"name":        migration.Name,
+		"duration_ms": time.Since(start).Milliseconds(),
+	})
+	return nil
+}
+
+func (m *migrator) find(version int64) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	gitHubAuthorizeURL = "https://github.com/login/oauth/authorize"
+	gitHubTokenURL     = "https://github.com/login/oauth/access_token"
+	gitHubAPIURL       = "https://api.github.com"
+)
+
+type gitHubProvider struct {
+	config Config
+	client *http.Client
+}
+
+// NewGitHubProvider returns a Provider for GitHub OAuth apps. GitHub issues no
+// ID token, so the identity comes from its REST API and only the primary,
+// verified email address is used.
+func NewGitHubProvider(config Config, client *http.Client) Provider {
+	return &gitHubProvider{config: config, client: client}
+}
+
+func (p *gitHubProvider) Name() string {
+	return p.config.Name
+}
+
+func (p *gitHubProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	return buildAuthURL(gitHubAuthorizeURL, p.config, url.Values{
+		"state":                 {state},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	})
+}
+
+func (p *gitHubProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error) {
+	tokens, err := exchangeCode(ctx, p.client, gitHubTokenURL, p.config, code, codeVerifier, false)
+	if err != nil {
+		return nil, err
+	}
+	if tokens.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access_token")
+	}
+
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := getJSON(ctx, p.client, gitHubAPIURL+"/user", tokens.AccessToken, &user); err != nil {
+		return nil, fmt.Errorf("failed to fetch GitHub user: %w", err)
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("GitHub user has no id")
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, p.client, gitHubAPIURL+"/user/emails", tokens.AccessToken, &emails); err != nil {
+		return nil, fmt.Errorf("failed to fetch GitHub emails: %w", err)
+	}
+
+	identity := &Identity{
+		Provider: p.config.Name,
+		// The numeric id is stable; logins can be renamed
+		Subject: strconv.FormatInt(user.ID, 10),
+		Name:    user.Name,
+	}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+		}
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,352 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "test-client"
+	testClientSecret = "test-secret"
+	testRedirectURL  = "http://localhost:3000/auth/callback"
+	testKeyID        = "test-key"
+)
+
+// mockIssuer is a minimal OpenID Connect provider: discovery, JWKS, an
+// authorization endpoint that approves every request and a token endpoint
+// that enforces PKCE.
+type mockIssuer struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	// claims adjusts the ID token before it is signed
+	claims func(claims jwt.MapClaims)
+
+	mu    sync.Mutex
+	codes map[string]authRequest
+}
+
+type authRequest struct {
+	challenge string
+	nonce     string
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	m := &mockIssuer{t: t, key: key, codes: map[string]authRequest{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", m.discovery)
+	mux.HandleFunc("/authorize", m.authorize)
+	mux.HandleFunc("/token", m.token)
+	mux.HandleFunc("/jwks", m.jwks)
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	return m
+}
+
+func (m *mockIssuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                m.server.URL,
+		"authorization_endpoint":                m.server.URL + "/authorize",
+		"token_endpoint":                        m.server.URL + "/token",
+		"jwks_uri":                              m.server.URL + "/jwks",
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic"},
+	})
+}
+
+func (m *mockIssuer) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": testKeyID,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}},
+	})
+}
+
+// authorize approves the login and redirects back with a code, as the
+// provider would once the user has signed in.
+func (m *mockIssuer) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("response_type") != "code" || query.Get("client_id") != testClientID ||
+		query.Get("redirect_uri") != testRedirectURL || query.Get("code_challenge_method") != "S256" ||
+		query.Get("code_challenge") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	code := "code-" + query.Get("state")
+	m.mu.Lock()
+	m.codes[code] = authRequest{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	m.mu.Unlock()
+
+	callback, _ := url.Parse(testRedirectURL)
+	callback.RawQuery = url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+	http.Redirect(w, r, callback.String(), http.StatusFound)
+}
+
+func (m *mockIssuer) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != testClientID || clientSecret != testClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if r.FormValue("grant_type") != "authorization_code" || r.FormValue("redirect_uri") != testRedirectURL {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	// Codes are single use
+	m.mu.Lock()
+	request, ok := m.codes[r.FormValue("code")]
+	delete(m.codes, r.FormValue("code"))
+	m.mu.Unlock()
+	if !ok || ChallengeS256(r.FormValue("code_verifier")) != request.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            m.server.URL,
+		"aud":            testClientID,
+		"sub":            "user-123",
+		"email":          "user@example.com",
+		"email_verified": true,
+		"name":           "Test User",
+		"nonce":          request.nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+	}
+	if m.claims != nil {
+		m.claims(claims)
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = testKeyID
+	signed, err := idToken.SignedString(m.key)
+	if err != nil {
+		m.t.Errorf("sign ID token: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": "access-token",
+		"id_token":     signed,
+		"token_type":   "Bearer",
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// login sends the user to the authorization URL and returns the code and
+// state the provider redirects back with.
+func (m *mockIssuer) login(t *testing.T, authURL string) (string, string) {
+	t.Helper()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize returned status %d", resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("invalid redirect: %v", err)
+	}
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func TestOIDCProviderLogin(t *testing.T) {
+	tests := []struct {
+		name string
+		// claims adjusts the ID token issued by the provider
+		claims func(claims jwt.MapClaims)
+		// verifier and nonce override the values sent with Exchange
+		verifier string
+		nonce    string
+		wantErr  string
+	}{
+		{
+			name: "valid login",
+		},
+		{
+			name:     "code verifier does not match the challenge",
+			verifier: "attacker-verifier-attacker-verifier-attacker",
+			wantErr:  "invalid_grant",
+		},
+		{
+			name:    "nonce does not match",
+			nonce:   "other-nonce",
+			wantErr: "nonce mismatch",
+		},
+		{
+			name:    "token without nonce",
+			claims:  func(claims jwt.MapClaims) { delete(claims, "nonce") },
+			wantErr: "nonce mismatch",
+		},
+		{
+			name:    "token for another client",
+			claims:  func(claims jwt.MapClaims) { claims["aud"] = "other-client" },
+			wantErr: "invalid ID token",
+		},
+		{
+			name:    "token from another issuer",
+			claims:  func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" },
+			wantErr: "invalid ID token",
+		},
+		{
+			name:    "expired token",
+			claims:  func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+			wantErr: "invalid ID token",
+		},
+		{
+			name:    "authorized party is another client",
+			claims:  func(claims jwt.MapClaims) { claims["azp"] = "other-client" },
+			wantErr: "azp mismatch",
+		},
+		{
+			name:    "token without subject",
+			claims:  func(claims jwt.MapClaims) { delete(claims, "sub") },
+			wantErr: "missing sub claim",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := newMockIssuer(t)
+			issuer.claims = tt.claims
+
+			providers, err := NewProviders([]Config{{
+				Name:         "mock",
+				Type:         TypeOIDC,
+				Issuer:       issuer.server.URL + "/",
+				ClientID:     testClientID,
+				ClientSecret: testClientSecret,
+				RedirectURL:  testRedirectURL,
+				Scopes:       []string{"openid", "email", "profile"},
+			}}, issuer.server.Client())
+			if err != nil {
+				t.Fatalf("NewProviders: %v", err)
+			}
+			provider := providers["mock"]
+
+			verifier, err := GenerateVerifier()
+			if err != nil {
+				t.Fatalf("GenerateVerifier: %v", err)
+			}
+			ctx := context.Background()
+			authURL, err := provider.AuthCodeURL(ctx, "state-123", "nonce-123", ChallengeS256(verifier))
+			if err != nil {
+				t.Fatalf("AuthCodeURL: %v", err)
+			}
+
+			code, state := issuer.login(t, authURL)
+			if state != "state-123" {
+				t.Fatalf("state = %q, want it returned unchanged", state)
+			}
+
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+			nonce := "nonce-123"
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+
+			identity, err := provider.Exchange(ctx, code, verifier, nonce)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Exchange error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+
+			want := Identity{
+				Provider:      "mock",
+				Subject:       "user-123",
+				Email:         "user@example.com",
+				EmailVerified: true,
+				Name:          "Test User",
+			}
+			if *identity != want {
+				t.Fatalf("identity = %+v, want %+v", *identity, want)
+			}
+		})
+	}
+}
+
+func TestOIDCProviderCodeIsSingleUse(t *testing.T) {
+	issuer := newMockIssuer(t)
+	provider := NewOIDCProvider(Config{
+		Name:         "mock",
+		Issuer:       issuer.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"openid"},
+	}, issuer.server.Client())
+
+	verifier, err := GenerateVerifier()
+	if err != nil {
+		t.Fatalf("GenerateVerifier: %v", err)
+	}
+	ctx := context.Background()
+	authURL, err := provider.AuthCodeURL(ctx, "state-123", "nonce-123", ChallengeS256(verifier))
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, _ := issuer.login(t, authURL)
+
+	if _, err := provider.Exchange(ctx, code, verifier, "nonce-123"); err != nil {
+		t.Fatalf("first Exchange: %v", err)
+	}
+	if _, err := provider.Exchange(ctx, code, verifier, "nonce-123"); err == nil {
+		t.Fatal("second Exchange with the same code succeeded")
+	}
+}
+
+func TestChallengeS256(t *testing.T) {
+	// Example from RFC 7636, appendix B
+	got := ChallengeS256("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Fatalf("ChallengeS256 = %q, want %q", got, want)
+	}
+}
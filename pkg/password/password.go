@@ -1,33 +1,171 @@
 package password
 
 import (
+    "crypto/rand"
+    "crypto/subtle"
+    "encoding/base64"
+    "fmt"
+    "strings"
+
+    "golang.org/x/crypto/argon2"
     "golang.org/x/crypto/bcrypt"
 )
 
+const (
+    AlgorithmArgon2id = "argon2id"
+    AlgorithmBcrypt   = "bcrypt"
+
+    // BcryptMaxBytes is the longest password bcrypt accepts.
+    BcryptMaxBytes = 72
+
+    argon2SaltLength = 16
+    argon2KeyLength  = 32
+)
+
 type PasswordUtil interface {
     HashPassword(password string) (string, error)
     CheckPassword(password, hash string) bool
+    // NeedsRehash reports whether hash was made with another algorithm or
+    // weaker parameters than the configured ones.
+    NeedsRehash(hash string) bool
+}
+
+// Argon2Params are the Argon2id cost parameters. Memory is in KiB.
+type Argon2Params struct {
+    Memory      uint32
+    Iterations  uint32
+    Parallelism uint8
+}
+
+type Config struct {
+    Algorithm  string
+    BcryptCost int
+    Argon2     Argon2Params
+}
+
+// Validate checks the parameters of the selected algorithm.
+func (c Config) Validate() error {
+    switch c.Algorithm {
+    case AlgorithmArgon2id:
+        if c.Argon2.Iterations < 1 || c.Argon2.Parallelism < 1 {
+            return fmt.Errorf("argon2id iterations and parallelism must be at least 1")
+        }
+        if c.Argon2.Memory < 8*uint32(c.Argon2.Parallelism) {
+            return fmt.Errorf("argon2id memory must be at least 8 KiB per thread")
+        }
+    case AlgorithmBcrypt:
+        if c.BcryptCost < bcrypt.MinCost || c.BcryptCost > bcrypt.MaxCost {
+            return fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+        }
+    default:
+        return fmt.Errorf("unknown password hash algorithm %q", c.Algorithm)
+    }
+    return nil
 }
 
 type passwordUtil struct {
-    cost int
+    config Config
 }
 
-func NewPasswordUtil() PasswordUtil {
-    return &passwordUtil{
-        cost: bcrypt.DefaultCost,
+// NewPasswordUtil hashes new passwords with the configured algorithm and
+// verifies both bcrypt and Argon2id hashes, so existing users keep working
+// while their hashes are upgraded on login.
+func NewPasswordUtil(config Config) (PasswordUtil, error) {
+    if err := config.Validate(); err != nil {
+        return nil, err
     }
+
+    return &passwordUtil{
+        config: config,
+    }, nil
 }
 
 func (p *passwordUtil) HashPassword(password string) (string, error) {
-    hash, err := bcrypt.GenerateFromPassword([]byte(password), p.cost)
-    if err != nil {
+    if p.config.Algorithm == AlgorithmBcrypt {
+        hash, err := bcrypt.GenerateFromPassword([]byte(password), p.config.BcryptCost)
+        if err != nil {
+            return "", err
+        }
+        return string(hash), nil
+    }
+
+    salt := make([]byte, argon2SaltLength)
+    if _, err := rand.Read(salt); err != nil {
         return "", err
     }
-    return string(hash), nil
+
+    params := p.config.Argon2
+    key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, argon2KeyLength)
+
+    // PHC string format, the same one used by the reference implementation
+    return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+        argon2.Version, params.Memory, params.Iterations, params.Parallelism,
+        base64.RawStdEncoding.EncodeToString(salt),
+        base64.RawStdEncoding.EncodeToString(key),
+    ), nil
 }
 
 func (p *passwordUtil) CheckPassword(password, hash string) bool {
+    if strings.HasPrefix(hash, "$argon2id$") {
+        params, salt, key, err := decodeArgon2(hash)
+        if err != nil {
+            return false
+        }
+        candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+        return subtle.ConstantTimeCompare(candidate, key) == 1
+    }
+
     err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
     return err == nil
-}
\ No newline at end of file
+}
+
+func (p *passwordUtil) NeedsRehash(hash string) bool {
+    if strings.HasPrefix(hash, "$argon2id$") {
+        if p.config.Algorithm != AlgorithmArgon2id {
+            return true
+        }
+        params, _, key, err := decodeArgon2(hash)
+        if err != nil {
+            return true
+        }
+        return params != p.config.Argon2 || len(key) != argon2KeyLength
+    }
+
+    if p.config.Algorithm != AlgorithmBcrypt {
+        return true
+    }
+    cost, err := bcrypt.Cost([]byte(hash))
+    return err != nil || cost != p.config.BcryptCost
+}
+
+func decodeArgon2(hash string) (Argon2Params, []byte, []byte, error) {
+    var params Argon2Params
+
+    // "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+    parts := strings.Split(hash, "$")
+    if len(parts) != 6 {
+        return params, nil, nil, fmt.Errorf("invalid argon2id hash")
+    }
+
+    var version int
+    if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+        return params, nil, nil, fmt.Errorf("unsupported argon2 version")
+    }
+    if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+        return params, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+    }
+    if params.Iterations < 1 || params.Parallelism < 1 {
+        return params, nil, nil, fmt.Errorf("invalid argon2id parameters")
+    }
+
+    salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+    if err != nil {
+        return params, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+    }
+    key, err := base64.RawStdEncoding.DecodeString(parts[5])
+    if err != nil || len(key) == 0 {
+        return params, nil, nil, fmt.Errorf("invalid argon2id key")
+    }
+
+    return params, salt, key, nil
+}
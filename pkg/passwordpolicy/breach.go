@@ -0,0 +1,156 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// BreachChecker reports how often a password appears in known breaches.
+type BreachChecker interface {
+	Count(ctx context.Context, password string) (int, error)
+}
+
+const (
+	hashLength   = 40
+	prefixLength = 5
+)
+
+type fileBreachChecker struct {
+	path string
+	dir  bool
+}
+
+// NewFileBreachChecker looks passwords up in a local copy of the Have I Been
+// Pwned SHA-1 corpus, so no password or hash prefix ever leaves the server.
+// path is either a directory of range files named after the first five hex
+// characters of the hash (ABCDE.txt) holding SUFFIX:COUNT lines, as served
+// by the range API, or a single file of HASH:COUNT lines sorted by hash.
+func NewFileBreachChecker(path string) (BreachChecker, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password corpus: %w", err)
+	}
+
+	return &fileBreachChecker{
+		path: path,
+		dir:  info.IsDir(),
+	}, nil
+}
+
+func (c *fileBreachChecker) Count(ctx context.Context, password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	if c.dir {
+		return c.countInRange(hash)
+	}
+	return c.countInSortedFile(hash)
+}
+
+func (c *fileBreachChecker) countInRange(hash string) (int, error) {
+	file, err := os.Open(filepath.Join(c.path, hash[:prefixLength]+".txt"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer file.Close()
+
+	suffix := hash[prefixLength:]
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineSuffix, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if ok && strings.EqualFold(lineSuffix, suffix) {
+			return parseCount(count)
+		}
+	}
+
+	return 0, scanner.Err()
+}
+
+// countInSortedFile binary searches the byte offsets of the file, since the
+// full corpus is far too large to load.
+func (c *fileBreachChecker) countInSortedFile(hash string) (int, error) {
+	file, err := os.Open(c.path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	// The matching line, if any, starts in [low, high)
+	low, high := int64(0), info.Size()
+	for low < high {
+		mid := low + (high-low)/2
+		start, line, err := lineFrom(file, mid, info.Size())
+		if err == io.EOF {
+			high = mid
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		lineHash, count, _ := strings.Cut(strings.TrimSpace(line), ":")
+		switch compared := strings.Compare(strings.ToUpper(lineHash), hash); {
+		case compared == 0:
+			return parseCount(count)
+		case compared < 0:
+			low = start + int64(len(line))
+		default:
+			high = mid
+		}
+	}
+
+	return 0, nil
+}
+
+// lineFrom returns the first line starting at or after offset, including its
+// newline, and where it starts.
+func lineFrom(file *os.File, offset, size int64) (int64, string, error) {
+	start := offset
+	if offset > 0 {
+		// Step back one byte so a line starting exactly at offset is kept
+		reader := bufio.NewReader(io.NewSectionReader(file, offset-1, size-offset+1))
+		skipped, err := reader.ReadString('\n')
+		if err != nil {
+			return 0, "", io.EOF
+		}
+		start = offset - 1 + int64(len(skipped))
+	}
+	if start >= size {
+		return 0, "", io.EOF
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(file, start, min(size-start, hashLength+32)))
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return 0, "", err
+	}
+	if line == "" {
+		return 0, "", io.EOF
+	}
+
+	return start, line, nil
+}
+
+func parseCount(value string) (int, error) {
+	count, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid breach count %q", value)
+	}
+	return count, nil
+}
@@ -0,0 +1,190 @@
+package passwordpolicy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Violation codes returned by Check.
+const (
+	CodeTooShort             = "TOO_SHORT"
+	CodeTooLong              = "TOO_LONG"
+	CodeMissingUppercase     = "MISSING_UPPERCASE"
+	CodeMissingLowercase     = "MISSING_LOWERCASE"
+	CodeMissingDigit         = "MISSING_DIGIT"
+	CodeMissingSymbol        = "MISSING_SYMBOL"
+	CodeContainsPersonalInfo = "CONTAINS_PERSONAL_INFO"
+	CodeTooWeak              = "TOO_WEAK"
+	CodeBreached             = "BREACHED"
+)
+
+// Violation is one rule the password does not satisfy.
+type Violation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Policy holds the rules a new password must satisfy.
+type Policy struct {
+	MinLength int
+	MaxLength int
+	// MaxBytes caps the UTF-8 encoded length, for hash algorithms such as
+	// bcrypt that only accept so many bytes. Zero means no limit.
+	MaxBytes         int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	// ForbidPersonalInfo rejects passwords containing the email or a part of
+	// the name of the user.
+	ForbidPersonalInfo bool
+	// MinScore is the lowest accepted strength score, from 0 to 4.
+	MinScore int
+}
+
+// Validate checks that the rules can be satisfied.
+func (p Policy) Validate() error {
+	switch {
+	case p.MinLength < 1:
+		return fmt.Errorf("minimum password length must be at least 1")
+	case p.MaxLength > 0 && p.MaxLength < p.MinLength:
+		return fmt.Errorf("maximum password length %d is below the minimum %d", p.MaxLength, p.MinLength)
+	case p.MaxBytes > 0 && p.MaxBytes < p.MinLength:
+		return fmt.Errorf("maximum password size of %d bytes is below the minimum length %d", p.MaxBytes, p.MinLength)
+	case p.MinScore < 0 || p.MinScore > 4:
+		return fmt.Errorf("minimum password score must be between 0 and 4")
+	}
+	return nil
+}
+
+// Checker validates new passwords against the policy.
+type Checker interface {
+	// Check returns every rule the password breaks. personalInfo holds values
+	// such as the email and name of the user, which the password must not
+	// contain. The error is only set when the breach corpus could not be read;
+	// the other rules are still applied.
+	Check(ctx context.Context, password string, personalInfo ...string) ([]Violation, error)
+}
+
+type checker struct {
+	policy Policy
+	breach BreachChecker
+}
+
+// NewChecker returns a Checker for policy. breach may be nil to skip the
+// breached password check.
+func NewChecker(policy Policy, breach BreachChecker) Checker {
+	return &checker{
+		policy: policy,
+		breach: breach,
+	}
+}
+
+func (c *checker) Check(ctx context.Context, password string, personalInfo ...string) ([]Violation, error) {
+	var violations []Violation
+	add := func(code, message string) {
+		violations = append(violations, Violation{Code: code, Message: message})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < c.policy.MinLength {
+		add(CodeTooShort, fmt.Sprintf("Password must be at least %d characters long", c.policy.MinLength))
+	}
+	if c.policy.MaxLength > 0 && length > c.policy.MaxLength {
+		add(CodeTooLong, fmt.Sprintf("Password must be at most %d characters long", c.policy.MaxLength))
+	} else if c.policy.MaxBytes > 0 && len(password) > c.policy.MaxBytes {
+		add(CodeTooLong, fmt.Sprintf("Password must be at most %d bytes long, accented letters and other non-ASCII characters count as more than one", c.policy.MaxBytes))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+	if c.policy.RequireUppercase && !hasUpper {
+		add(CodeMissingUppercase, "Password must contain an uppercase letter")
+	}
+	if c.policy.RequireLowercase && !hasLower {
+		add(CodeMissingLowercase, "Password must contain a lowercase letter")
+	}
+	if c.policy.RequireDigit && !hasDigit {
+		add(CodeMissingDigit, "Password must contain a digit")
+	}
+	if c.policy.RequireSymbol && !hasSymbol {
+		add(CodeMissingSymbol, "Password must contain a symbol")
+	}
+
+	userWords := personalWords(personalInfo)
+	if c.policy.ForbidPersonalInfo && containsAny(strings.ToLower(password), userWords) {
+		add(CodeContainsPersonalInfo, "Password must not contain your email or name")
+	}
+
+	if Score(password, userWords...) < c.policy.MinScore {
+		add(CodeTooWeak, "Password is too easy to guess, use a longer phrase or fewer common words and patterns")
+	}
+
+	if c.breach == nil {
+		return violations, nil
+	}
+
+	count, err := c.breach.Count(ctx, password)
+	if err != nil {
+		return violations, err
+	}
+	if count > 0 {
+		add(CodeBreached, "Password has appeared in a data breach, choose a different one")
+	}
+
+	return violations, nil
+}
+
+// personalWords splits emails and names into the lowercase fragments a
+// password must not contain. Fragments shorter than three characters are
+// dropped; they match too many unrelated passwords.
+func personalWords(values []string) []string {
+	var words []string
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+		if local, _, ok := strings.Cut(value, "@"); ok {
+			words = append(words, value)
+			value = local
+		}
+		words = append(words, value)
+		for _, part := range strings.FieldsFunc(value, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			words = append(words, part)
+		}
+	}
+
+	kept := words[:0]
+	for _, word := range words {
+		if utf8.RuneCountInString(word) >= 3 {
+			kept = append(kept, word)
+		}
+	}
+	return kept
+}
+
+func containsAny(value string, words []string) bool {
+	for _, word := range words {
+		if strings.Contains(value, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,322 @@
+package passwordpolicy
+
+import (
+	_ "embed"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// The estimator follows zxcvbn: the password is split into the cheapest
+// sequence of guessable patterns (dictionary words, sequences, repeats,
+// keyboard walks and years) with the rest brute forced, and the resulting
+// number of guesses is mapped to a score from 0 to 4.
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// commonWords maps each entry to its rank, most common first.
+var commonWords = func() map[string]int {
+	words := map[string]int{}
+	for _, line := range strings.Split(commonPasswordList, "\n") {
+		word := strings.ToLower(strings.TrimSpace(line))
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		if _, ok := words[word]; !ok {
+			words[word] = len(words) + 1
+		}
+	}
+	return words
+}()
+
+var leetSubstitutions = map[rune]rune{
+	'4': 'a', '@': 'a', '8': 'b', '(': 'c', '3': 'e', '6': 'g', '1': 'i',
+	'!': 'i', '|': 'l', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't', '2': 'z',
+}
+
+var keyboardRows = []string{"`1234567890-=", "qwertyuiop[]\\", "asdfghjkl;'", "zxcvbnm,./"}
+
+const (
+	bruteforceCardinality = 10
+	minSubmatchGuesses    = 50
+	keyboardStartingKeys  = 47
+	keyboardAverageDegree = 4
+)
+
+// Score estimates how hard the password is to guess, from 0 (too guessable)
+// to 4 (very unguessable). userWords, such as the name and email of the user,
+// are treated as the most likely dictionary words.
+func Score(password string, userWords ...string) int {
+	guesses := estimateGuesses([]rune(password), userWords)
+	switch {
+	case guesses < 1e3+5:
+		return 0
+	case guesses < 1e6+5:
+		return 1
+	case guesses < 1e8+5:
+		return 2
+	case guesses < 1e10+5:
+		return 3
+	default:
+		return 4
+	}
+}
+
+type match struct {
+	start, end int // end is exclusive
+	guesses    float64
+}
+
+func estimateGuesses(password []rune, userWords []string) float64 {
+	n := len(password)
+	if n == 0 {
+		return 1
+	}
+
+	matches := findMatches(password, userWords)
+
+	// best[i] is the fewest guesses needed for the first i characters
+	best := make([]float64, n+1)
+	best[0] = 1
+	for i := 1; i <= n; i++ {
+		best[i] = best[i-1] * bruteforceCardinality
+	}
+	for i := 1; i <= n; i++ {
+		for _, m := range matches {
+			if m.end != i {
+				continue
+			}
+			guesses := m.guesses
+			if m.end-m.start < n {
+				guesses = math.Max(guesses, minSubmatchGuesses)
+			}
+			if candidate := best[m.start] * guesses; candidate < best[i] {
+				best[i] = candidate
+			}
+		}
+		if i < n {
+			best[i+1] = math.Min(best[i+1], best[i]*bruteforceCardinality)
+		}
+	}
+
+	return best[n]
+}
+
+func findMatches(password []rune, userWords []string) []match {
+	var matches []match
+	matches = append(matches, dictionaryMatches(password, userWords)...)
+	matches = append(matches, repeatMatches(password)...)
+	matches = append(matches, sequenceMatches(password)...)
+	matches = append(matches, keyboardMatches(password)...)
+	matches = append(matches, yearMatches(password)...)
+	return matches
+}
+
+func dictionaryMatches(password []rune, userWords []string) []match {
+	lower := []rune(strings.ToLower(string(password)))
+	if len(lower) != len(password) {
+		// Case folding changed the length; fall back to the original runes
+		lower = password
+	}
+	unleet := make([]rune, len(lower))
+	for i, r := range lower {
+		if sub, ok := leetSubstitutions[r]; ok {
+			unleet[i] = sub
+		} else {
+			unleet[i] = r
+		}
+	}
+
+	ranks := map[string]int{}
+	for _, word := range userWords {
+		ranks[strings.ToLower(word)] = 1
+	}
+
+	var matches []match
+	for i := 0; i < len(lower); i++ {
+		for j := i + 3; j <= len(lower); j++ {
+			for _, candidate := range []struct {
+				word     string
+				leet     bool
+				reversed bool
+			}{
+				{string(lower[i:j]), false, false},
+				{string(unleet[i:j]), string(unleet[i:j]) != string(lower[i:j]), false},
+				{reverse(string(lower[i:j])), false, true},
+			} {
+				rank, ok := ranks[candidate.word]
+				if !ok {
+					rank, ok = commonWords[candidate.word]
+				}
+				if !ok {
+					continue
+				}
+				guesses := float64(rank) * uppercaseVariations(password[i:j])
+				if candidate.leet {
+					guesses *= 2
+				}
+				if candidate.reversed {
+					guesses *= 2
+				}
+				matches = append(matches, match{start: i, end: j, guesses: guesses})
+			}
+		}
+	}
+
+	return matches
+}
+
+func uppercaseVariations(word []rune) float64 {
+	var upper, lower int
+	for _, r := range word {
+		if unicode.IsUpper(r) {
+			upper++
+		} else if unicode.IsLower(r) {
+			lower++
+		}
+	}
+	switch {
+	case upper == 0:
+		return 1
+	case lower == 0 || (upper == 1 && unicode.IsUpper(word[0])):
+		// All caps or only the first letter capitalized
+		return 2
+	default:
+		return math.Pow(2, float64(upper))
+	}
+}
+
+func repeatMatches(password []rune) []match {
+	var matches []match
+	for i := 0; i < len(password); {
+		j := i + 1
+		for j < len(password) && password[j] == password[i] {
+			j++
+		}
+		if j-i >= 3 {
+			matches = append(matches, match{start: i, end: j, guesses: cardinality(password[i]) * float64(j-i)})
+		}
+		i = j
+	}
+	return matches
+}
+
+func sequenceMatches(password []rune) []match {
+	var matches []match
+	for i := 0; i+1 < len(password); {
+		delta := password[i+1] - password[i]
+		if delta != 1 && delta != -1 {
+			i++
+			continue
+		}
+		j := i + 2
+		for j < len(password) && password[j]-password[j-1] == delta {
+			j++
+		}
+		if j-i >= 3 {
+			var base float64
+			switch first := unicode.ToLower(password[i]); {
+			case strings.ContainsRune("az019", first):
+				base = 4
+			case unicode.IsDigit(first):
+				base = 10
+			default:
+				base = 26
+			}
+			if delta < 0 {
+				base *= 2
+			}
+			matches = append(matches, match{start: i, end: j, guesses: base * float64(j-i)})
+		}
+		i = j - 1
+	}
+	return matches
+}
+
+func keyboardMatches(password []rune) []match {
+	var matches []match
+	for i := 0; i+1 < len(password); {
+		j := i + 1
+		for j < len(password) && keysAdjacent(password[j-1], password[j]) {
+			j++
+		}
+		if j-i >= 3 {
+			guesses := keyboardStartingKeys * math.Pow(keyboardAverageDegree, float64(j-i-1)) / float64(j-i)
+			matches = append(matches, match{start: i, end: j, guesses: guesses})
+		}
+		i = j
+	}
+	return matches
+}
+
+func keysAdjacent(a, b rune) bool {
+	rowA, colA := keyPosition(a)
+	rowB, colB := keyPosition(b)
+	if rowA < 0 || rowB < 0 {
+		return false
+	}
+	if rowA == rowB {
+		return colA-colB == 1 || colB-colA == 1
+	}
+	if rowA > rowB {
+		rowA, colA, rowB, colB = rowB, colB, rowA, colA
+	}
+	if rowB-rowA != 1 {
+		return false
+	}
+	// Each row is shifted half a key to the right of the one above it
+	offset := 0
+	if rowA == 0 {
+		offset = 1
+	}
+	return colA == colB+offset || colA == colB+offset+1
+}
+
+func keyPosition(r rune) (int, int) {
+	r = unicode.ToLower(r)
+	for row, keys := range keyboardRows {
+		if col := strings.IndexRune(keys, r); col >= 0 {
+			return row, col
+		}
+	}
+	return -1, -1
+}
+
+func yearMatches(password []rune) []match {
+	var matches []match
+	for i := 0; i+4 <= len(password); i++ {
+		year := 0
+		digits := true
+		for _, r := range password[i : i+4] {
+			if r < '0' || r > '9' {
+				digits = false
+				break
+			}
+			year = year*10 + int(r-'0')
+		}
+		if digits && year >= 1900 && year <= 2099 {
+			matches = append(matches, match{start: i, end: i + 4, guesses: 200})
+		}
+	}
+	return matches
+}
+
+func cardinality(r rune) float64 {
+	switch {
+	case unicode.IsDigit(r):
+		return 10
+	case unicode.IsLower(r) || unicode.IsUpper(r):
+		return 26
+	default:
+		return 33
+	}
+}
+
+func reverse(value string) string {
+	runes := []rune(value)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
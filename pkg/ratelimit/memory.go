@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	bucket
+	fullAt time.Time
+}
+
+type memoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryEntry
+}
+
+// NewMemoryStore returns a Store kept in process memory. Each instance of the
+// service enforces its own limits.
+func NewMemoryStore() Store {
+	return &memoryStore{buckets: make(map[string]*memoryEntry)}
+}
+
+func (s *memoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := s.buckets[key]
+	if !ok {
+		entry = &memoryEntry{bucket: bucket{tokens: float64(limit.Burst), updatedAt: now}}
+		s.buckets[key] = entry
+	}
+
+	updated, result := take(entry.bucket, limit, now)
+	entry.bucket = updated
+	entry.fullAt = now.Add(result.ResetAfter)
+
+	return result, nil
+}
+
+func (s *memoryStore) Cleanup(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var removed int64
+	for key, entry := range s.buckets {
+		if now.After(entry.fullAt) {
+			delete(s.buckets, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore returns a Store backed by the rate_limit_buckets table, so
+// limits hold across every instance of the service.
+func NewPostgresStore(db *sql.DB) Store {
+	return &postgresStore{db: db}
+}
+
+// Take refills and takes from the bucket in one statement. The row lock of
+// the upsert serializes concurrent requests for the same key. When no token
+// is left the update is skipped and the refilled count is read instead.
+// full_at is set one period ahead: a bucket is full again at most one period
+// after its last token was taken, which is all Cleanup needs to know.
+func (s *postgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	query := `
+        WITH taken AS (
+            INSERT INTO rate_limit_buckets AS b (key, tokens, updated_at, full_at)
+            VALUES ($1, $2::DOUBLE PRECISION - 1, $3::TIMESTAMP, $3::TIMESTAMP + make_interval(secs => $5::DOUBLE PRECISION))
+            ON CONFLICT (key) DO UPDATE
+            SET tokens = LEAST($2, b.tokens + GREATEST(EXTRACT(EPOCH FROM $3 - b.updated_at), 0) * $4::DOUBLE PRECISION) - 1,
+                updated_at = $3,
+                full_at = $3 + make_interval(secs => $5)
+            WHERE LEAST($2, b.tokens + GREATEST(EXTRACT(EPOCH FROM $3 - b.updated_at), 0) * $4) >= 1
+            RETURNING tokens
+        )
+        SELECT TRUE, tokens FROM taken
+        UNION ALL
+        SELECT FALSE, LEAST($2, tokens + GREATEST(EXTRACT(EPOCH FROM $3 - updated_at), 0) * $4)
+        FROM rate_limit_buckets
+        WHERE key = $1 AND NOT EXISTS (SELECT 1 FROM taken)
+    `
+
+	var (
+		allowed bool
+		tokens  float64
+	)
+	err := s.db.QueryRowContext(ctx, query, key, float64(limit.Burst), time.Now().UTC(), limit.perSecond(), limit.Period.Seconds()).
+		Scan(&allowed, &tokens)
+	if err == sql.ErrNoRows {
+		// The bucket was created by a concurrent request after this
+		// statement's snapshot and had no token left
+		return newResult(limit, 0, false), nil
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to take from rate limit bucket: %w", err)
+	}
+
+	return newResult(limit, tokens, allowed), nil
+}
+
+func (s *postgresStore) Cleanup(ctx context.Context) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM rate_limit_buckets WHERE full_at < $1`, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean up rate limit buckets: %w", err)
+	}
+
+	removed, _ := result.RowsAffected()
+	return removed, nil
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"devsecops-be/pkg/cleanup"
+	"devsecops-be/pkg/logger"
+	"math"
+	"time"
+)
+
+// Limit is a token bucket holding up to Burst requests that refills
+// completely over Period.
+type Limit struct {
+	Burst  int
+	Period time.Duration
+}
+
+// perSecond returns the refill rate in tokens per second.
+func (l Limit) perSecond() float64 {
+	return float64(l.Burst) / l.Period.Seconds()
+}
+
+// Result describes the bucket after a request was counted.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter is how long until the bucket is full again.
+	ResetAfter time.Duration
+	// RetryAfter is how long until the next request would be allowed; zero when Allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps token buckets by key.
+type Store interface {
+	// Take removes one token from the bucket for key if one is available.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+	// Cleanup removes buckets that have refilled completely and returns how many were removed.
+	Cleanup(ctx context.Context) (int64, error)
+}
+
+// bucket is the stored state of one key: the tokens left at updatedAt.
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// take refills b up to now and tries to remove one token.
+func take(b bucket, limit Limit, now time.Time) (bucket, Result) {
+	elapsed := max(now.Sub(b.updatedAt).Seconds(), 0)
+	tokens := math.Min(float64(limit.Burst), b.tokens+elapsed*limit.perSecond())
+
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+
+	return bucket{tokens: tokens, updatedAt: now}, newResult(limit, tokens, allowed)
+}
+
+// newResult describes a bucket left with tokens after a request was counted.
+func newResult(limit Limit, tokens float64, allowed bool) Result {
+	rate := limit.perSecond()
+
+	result := Result{Allowed: allowed, Limit: limit.Burst}
+	if !allowed {
+		result.RetryAfter = secondsToDuration((1 - tokens) / rate)
+	}
+	result.Remaining = int(math.Floor(tokens))
+	result.ResetAfter = secondsToDuration((float64(limit.Burst) - tokens) / rate)
+
+	return result
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// StartCleanup runs store.Cleanup every interval until ctx is cancelled.
+func StartCleanup(ctx context.Context, store Store, interval time.Duration, log logger.Logger) {
+	cleanup.Every(ctx, interval, "rate limit buckets", store.Cleanup, log)
+}
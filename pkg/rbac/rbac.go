@@ -0,0 +1,110 @@
+// Package rbac resolves which permissions a role grants. Roles are carried in
+// the access token's "role" claim; the role to permission mapping lives in the
+// roles, permissions and role_permissions tables.
+package rbac
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Roles seeded by the migrations. New accounts get RoleUser.
+const (
+	RoleUser    = "user"
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+)
+
+// roleRanks orders the seeded roles from least to most privileged.
+var roleRanks = map[string]int{RoleUser: 0, RoleSupport: 1, RoleAdmin: 2}
+
+// IsDowngrade reports whether changing a role from one to the other can take
+// permissions away. Changes involving a role that is not seeded count as one.
+func IsDowngrade(from, to string) bool {
+	fromRank, fromOK := roleRanks[from]
+	toRank, toOK := roleRanks[to]
+	if !fromOK || !toOK {
+		return from != to
+	}
+	return toRank < fromRank
+}
+
+// Permissions grant access to other users' data; access to a user's own
+// resources never needs one.
+const (
+	PermUsersReadAny        = "users:read:any"
+	PermUsersDisableAny     = "users:disable:any"
+	PermAuditLogsReadAny    = "audit_logs:read:any"
+	PermTransactionsReadAny = "transactions:read:any"
+)
+
+// Authorizer reports whether a role grants a permission.
+type Authorizer interface {
+	HasPermission(ctx context.Context, role string, permission string) (bool, error)
+}
+
+type postgresAuthorizer struct {
+	db  *sql.DB
+	ttl time.Duration
+
+	mu       sync.RWMutex
+	grants   map[string]map[string]struct{}
+	loadedAt time.Time
+}
+
+// NewPostgresAuthorizer returns an Authorizer that reads role_permissions and
+// caches the whole mapping for ttl, so permission checks do not hit the
+// database on every request. Changes to the mapping apply after at most ttl.
+func NewPostgresAuthorizer(db *sql.DB, ttl time.Duration) Authorizer {
+	return &postgresAuthorizer{db: db, ttl: ttl}
+}
+
+func (a *postgresAuthorizer) HasPermission(ctx context.Context, role string, permission string) (bool, error) {
+	grants, err := a.load(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := grants[role][permission]
+	return ok, nil
+}
+
+func (a *postgresAuthorizer) load(ctx context.Context) (map[string]map[string]struct{}, error) {
+	a.mu.RLock()
+	grants, loadedAt := a.grants, a.loadedAt
+	a.mu.RUnlock()
+
+	if grants != nil && time.Since(loadedAt) < a.ttl {
+		return grants, nil
+	}
+
+	rows, err := a.db.QueryContext(ctx, `SELECT role, permission FROM role_permissions`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load role permissions: %w", err)
+	}
+	defer rows.Close()
+
+	grants = map[string]map[string]struct{}{}
+	for rows.Next() {
+		var role, permission string
+		if err := rows.Scan(&role, &permission); err != nil {
+			return nil, fmt.Errorf("failed to scan role permission: %w", err)
+		}
+		if grants[role] == nil {
+			grants[role] = map[string]struct{}{}
+		}
+		grants[role][permission] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load role permissions: %w", err)
+	}
+
+	a.mu.Lock()
+	a.grants, a.loadedAt = grants, time.Now()
+	a.mu.Unlock()
+
+	return grants, nil
+}
@@ -0,0 +1,26 @@
+package requestinfo
+
+import (
+	"context"
+)
+
+type contextKey struct{}
+
+// Info describes the HTTP request that triggered an operation.
+type Info struct {
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+func NewContext(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+func FromContext(ctx context.Context) Info {
+	if ctx == nil {
+		return Info{}
+	}
+	info, _ := ctx.Value(contextKey{}).(Info)
+	return info
+}
@@ -11,6 +11,26 @@ type Response struct {
     Error   interface{} `json:"error,omitempty"`
 }
 
+type Pagination struct {
+    Page       int `json:"page"`
+    Limit      int `json:"limit"`
+    Total      int `json:"total"`
+    TotalPages int `json:"total_pages"`
+}
+
+func NewPagination(page, limit, total int) Pagination {
+    totalPages := 0
+    if limit > 0 {
+        totalPages = (total + limit - 1) / limit
+    }
+    return Pagination{
+        Page:       page,
+        Limit:      limit,
+        Total:      total,
+        TotalPages: totalPages,
+    }
+}
+
 func Success(c *fiber.Ctx, message string, data interface{}) error {
     return c.Status(fiber.StatusOK).JSON(Response{
         Success: true,
@@ -43,6 +63,14 @@ func Unauthorized(c *fiber.Ctx, message string, error interface{}) error {
     })
 }
 
+func Forbidden(c *fiber.Ctx, message string, error interface{}) error {
+    return c.Status(fiber.StatusForbidden).JSON(Response{
+        Success: false,
+        Message: message,
+        Error:   error,
+    })
+}
+
 func NotFound(c *fiber.Ctx, message string, error interface{}) error {
     return c.Status(fiber.StatusNotFound).JSON(Response{
         Success: false,
@@ -59,6 +87,14 @@ func Conflict(c *fiber.Ctx, message string, error interface{}) error {
     })
 }
 
+func TooManyRequests(c *fiber.Ctx, message string, error interface{}) error {
+    return c.Status(fiber.StatusTooManyRequests).JSON(Response{
+        Success: false,
+        Message: message,
+        Error:   error,
+    })
+}
+
 func InternalServerError(c *fiber.Ctx, message string, error interface{}) error {
     return c.Status(fiber.StatusInternalServerError).JSON(Response{
         Success: false,
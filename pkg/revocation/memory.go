@@ -0,0 +1,91 @@
+package revocation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type userCutoff struct {
+	cutoff    time.Time
+	expiresAt time.Time
+}
+
+type memoryStore struct {
+	mu      sync.RWMutex
+	tokens  map[string]time.Time
+	cutoffs map[uuid.UUID]userCutoff
+}
+
+// NewMemoryStore returns a Store kept in process memory. It is only suitable
+// for a single instance since revocations are not shared or persisted.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		tokens:  make(map[string]time.Time),
+		cutoffs: make(map[uuid.UUID]userCutoff),
+	}
+}
+
+func (s *memoryStore) Revoke(ctx context.Context, id string, userID uuid.UUID, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[id] = expiresAt
+	return nil
+}
+
+func (s *memoryStore) RevokeAllForUser(ctx context.Context, userID uuid.UUID, cutoff time.Time, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Never move either bound backwards: a later call with an earlier
+	// cutoff must not un-revoke tokens, matching the Postgres store
+	if entry, ok := s.cutoffs[userID]; ok {
+		if entry.cutoff.After(cutoff) {
+			cutoff = entry.cutoff
+		}
+		if entry.expiresAt.After(expiresAt) {
+			expiresAt = entry.expiresAt
+		}
+	}
+	s.cutoffs[userID] = userCutoff{cutoff: cutoff, expiresAt: expiresAt}
+	return nil
+}
+
+func (s *memoryStore) IsRevoked(ctx context.Context, ids []string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, id := range ids {
+		if _, ok := s.tokens[id]; ok {
+			return true, nil
+		}
+	}
+	if entry, ok := s.cutoffs[userID]; ok && issuedAt.Before(entry.cutoff) {
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *memoryStore) Cleanup(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var removed int64
+	for jti, expiresAt := range s.tokens {
+		if now.After(expiresAt) {
+			delete(s.tokens, jti)
+			removed++
+		}
+	}
+	for userID, entry := range s.cutoffs {
+		if now.After(entry.expiresAt) {
+			delete(s.cutoffs, userID)
+			removed++
+		}
+	}
+	return removed, nil
+}
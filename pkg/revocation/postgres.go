@@ -0,0 +1,80 @@
+package revocation
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore returns a Store backed by the revoked_tokens and
+// user_token_revocations tables, shared by every instance of the service.
+func NewPostgresStore(db *sql.DB) Store {
+	return &postgresStore{db: db}
+}
+
+func (s *postgresStore) Revoke(ctx context.Context, id string, userID uuid.UUID, expiresAt time.Time) error {
+	query := `
+        INSERT INTO revoked_tokens (jti, user_id, expires_at)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (jti) DO NOTHING
+    `
+
+	if _, err := s.db.ExecContext(ctx, query, id, userID, expiresAt.UTC()); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+func (s *postgresStore) RevokeAllForUser(ctx context.Context, userID uuid.UUID, cutoff time.Time, expiresAt time.Time) error {
+	query := `
+        INSERT INTO user_token_revocations (user_id, revoked_before, expires_at)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (user_id) DO UPDATE
+        SET revoked_before = GREATEST(user_token_revocations.revoked_before, EXCLUDED.revoked_before),
+            expires_at = GREATEST(user_token_revocations.expires_at, EXCLUDED.expires_at)
+    `
+
+	if _, err := s.db.ExecContext(ctx, query, userID, cutoff.UTC(), expiresAt.UTC()); err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+	return nil
+}
+
+func (s *postgresStore) IsRevoked(ctx context.Context, ids []string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	query := `
+        SELECT
+            EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = ANY($1))
+            OR EXISTS (SELECT 1 FROM user_token_revocations WHERE user_id = $2 AND revoked_before > $3)
+    `
+
+	var revoked bool
+	if err := s.db.QueryRowContext(ctx, query, pq.Array(ids), userID, issuedAt.UTC()).Scan(&revoked); err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	return revoked, nil
+}
+
+func (s *postgresStore) Cleanup(ctx context.Context) (int64, error) {
+	now := time.Now().UTC()
+
+	tokens, err := s.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean up revoked tokens: %w", err)
+	}
+	users, err := s.db.ExecContext(ctx, `DELETE FROM user_token_revocations WHERE expires_at < $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean up user token revocations: %w", err)
+	}
+
+	removedTokens, _ := tokens.RowsAffected()
+	removedUsers, _ := users.RowsAffected()
+	return removedTokens + removedUsers, nil
+}
@@ -0,0 +1,30 @@
+package revocation
+
+import (
+	"context"
+	"devsecops-be/pkg/cleanup"
+	"devsecops-be/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store records access tokens that must be rejected before they expire.
+// Entries are only needed until the affected tokens would have expired, so
+// every write carries an expiry after which Cleanup may drop it.
+type Store interface {
+	// Revoke rejects every token carrying id as its jti or session ID.
+	Revoke(ctx context.Context, id string, userID uuid.UUID, expiresAt time.Time) error
+	// RevokeAllForUser rejects every token of the user issued before cutoff.
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID, cutoff time.Time, expiresAt time.Time) error
+	// IsRevoked reports whether a token with the given IDs (jti and, when
+	// present, session ID), owner and issue time is revoked.
+	IsRevoked(ctx context.Context, ids []string, userID uuid.UUID, issuedAt time.Time) (bool, error)
+	// Cleanup removes entries whose expiry has passed and returns how many were removed.
+	Cleanup(ctx context.Context) (int64, error)
+}
+
+// StartCleanup runs store.Cleanup every interval until ctx is cancelled.
+func StartCleanup(ctx context.Context, store Store, interval time.Duration, log logger.Logger) {
+	cleanup.Every(ctx, interval, "revoked tokens", store.Cleanup, log)
+}
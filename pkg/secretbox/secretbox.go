@@ -0,0 +1,77 @@
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+// Box encrypts small secrets for storage with AES-256-GCM.
+type Box interface {
+	Seal(plaintext []byte) (string, error)
+	Open(ciphertext string) ([]byte, error)
+}
+
+type box struct {
+	aead cipher.AEAD
+}
+
+// New returns a Box for a 32-byte key.
+func New(key []byte) (Box, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &box{aead: aead}, nil
+}
+
+// NewFromBase64 returns a Box for a base64-encoded 32-byte key.
+func NewFromBase64(encoded string) (Box, error) {
+	if encoded == "" {
+		return nil, fmt.Errorf("encryption key is not set")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key is not valid base64: %w", err)
+	}
+
+	return New(key)
+}
+
+// Seal encrypts plaintext and returns base64(nonce || ciphertext).
+func (b *box) Seal(plaintext []byte) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := b.aead.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (b *box) Open(ciphertext string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := b.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	return b.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+}
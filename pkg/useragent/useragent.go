@@ -0,0 +1,78 @@
+// Package useragent turns User-Agent headers into short device descriptions
+// such as "Firefox on Windows" for session listings. It only recognizes the
+// common browsers and platforms; anything else is reported generically.
+package useragent
+
+import "strings"
+
+// MaxLength is the longest description Describe returns, in runes.
+const MaxLength = 64
+
+// Describe returns a human readable description of the client, at most
+// MaxLength runes long.
+func Describe(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	client := browser(userAgent)
+	if platform := operatingSystem(userAgent); platform != "" {
+		return truncate(client, MaxLength-len(" on ")-len(platform)) + " on " + platform
+	}
+	return truncate(client, MaxLength)
+}
+
+// truncate cuts s to at most n runes, the product token of an unknown
+// client can be arbitrarily long.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+func browser(ua string) string {
+	switch {
+	case strings.Contains(ua, "Edg/"):
+		return "Edge"
+	case strings.Contains(ua, "OPR/"):
+		return "Opera"
+	case strings.Contains(ua, "Firefox/"):
+		return "Firefox"
+	case strings.Contains(ua, "Chrome/"), strings.Contains(ua, "CriOS/"):
+		return "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		return "Safari"
+	case strings.HasPrefix(ua, "curl/"):
+		return "curl"
+	case strings.HasPrefix(ua, "PostmanRuntime/"):
+		return "Postman"
+	}
+
+	// Fall back to the product token, e.g. "python-requests/2.31" -> "python-requests"
+	product, _, _ := strings.Cut(ua, "/")
+	product, _, _ = strings.Cut(product, " ")
+	if product == "" || product == "Mozilla" {
+		return "Unknown browser"
+	}
+	return product
+}
+
+func operatingSystem(ua string) string {
+	switch {
+	case strings.Contains(ua, "Windows"):
+		return "Windows"
+	case strings.Contains(ua, "Android"):
+		return "Android"
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"):
+		return "iOS"
+	case strings.Contains(ua, "Mac OS X"):
+		return "macOS"
+	case strings.Contains(ua, "CrOS"):
+		return "ChromeOS"
+	case strings.Contains(ua, "Linux"):
+		return "Linux"
+	}
+	return ""
+}
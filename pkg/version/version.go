@@ -0,0 +1,39 @@
+// Package version holds the build information logged at startup. Only the
+// version is reported by the health endpoints. Both are set at build time:
+//
+//	go build -ldflags "-X devsecops-be/pkg/version.Version=1.4.0 -X devsecops-be/pkg/version.Commit=$(git rev-parse --short HEAD)" ./cmd/app
+package version
+
+import "runtime/debug"
+
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information. Without -ldflags the commit falls back
+// to the revision the Go toolchain stamps into binaries built from a git
+// checkout.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, GoVersion: "unknown"}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info.GoVersion = build.GoVersion
+		for _, setting := range build.Settings {
+			if setting.Key == "vcs.revision" && info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+
+	return info
+}
@@ -0,0 +1,100 @@
+// Package worker runs background jobs, such as sending email after the
+// response was written, on a fixed number of goroutines.
+package worker
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type Config struct {
+	Workers   int
+	QueueSize int
+	// ShutdownTimeout bounds how long Shutdown waits for queued jobs.
+	ShutdownTimeout time.Duration
+}
+
+// Validate checks that the pool can run jobs.
+func (c Config) Validate() error {
+	if c.Workers <= 0 {
+		return fmt.Errorf("BACKGROUND_WORKERS must be positive")
+	}
+	if c.QueueSize < 0 {
+		return fmt.Errorf("BACKGROUND_QUEUE_SIZE must not be negative")
+	}
+	if c.ShutdownTimeout <= 0 {
+		return fmt.Errorf("BACKGROUND_SHUTDOWN_TIMEOUT_SECONDS must be positive")
+	}
+	return nil
+}
+
+// Pool runs submitted jobs on Config.Workers goroutines. Jobs wait in a queue
+// of Config.QueueSize; when it is full new jobs are rejected rather than
+// piling up goroutines.
+type Pool struct {
+	config Config
+	jobs   chan func()
+	wg     sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewPool starts the workers.
+func NewPool(config Config) *Pool {
+	p := &Pool{config: config, jobs: make(chan func(), config.QueueSize)}
+
+	p.wg.Add(config.Workers)
+	for i := 0; i < config.Workers; i++ {
+		go func() {
+			defer p.wg.Done()
+			for job := range p.jobs {
+				job()
+			}
+		}()
+	}
+
+	return p
+}
+
+// Submit queues job. It returns false when the queue is full or the pool is
+// shutting down, the job is then not run.
+func (p *Pool) Submit(job func()) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return false
+	}
+	select {
+	case p.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// Shutdown stops accepting jobs and waits for the queued ones to finish, for
+// at most Config.ShutdownTimeout.
+func (p *Pool) Shutdown() error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(p.config.ShutdownTimeout):
+		return fmt.Errorf("background jobs still running after %s", p.config.ShutdownTimeout)
+	}
+}